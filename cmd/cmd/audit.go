@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/server"
+)
+
+// runAudit prints the entries of the audit log, or verifies its hash chain with -verify.
+// A broken chain makes the command fail.
+func runAudit(args []string) error {
+	fs := newFlagSet("audit", "[-verify] [flags]")
+	logPath := fs.String("log", "", "Specify file path of the audit log, the configured one when empty")
+	configPath := fs.String("config", "", "Specify file path for the config file (JSON), "+config.EnvConfigFile+" when empty")
+	verify := fs.Bool("verify", false, "Verify the hash chain of the whole log instead of listing entries")
+	tenant := fs.String("tenant", "", "Only list the entries of this tenant, every tenant when empty")
+	action := fs.String("action", "", "Only list the entries of this action, e.g. run, sign_off or write_off")
+	runID := fs.String("run", "", "Only list the entries of this run")
+	actor := fs.String("actor", "", "Only list the entries of this actor")
+	jsonOut := fs.Bool("json", false, "Write the entries as JSON lines")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	// The key of the chain comes from the config even when the log is given
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	if *logPath == "" {
+		*logPath = cfg.Server.AuditLog
+	}
+	if _, err := os.Stat(*logPath); err != nil {
+		return err
+	}
+
+	log, err := audit.Open(*logPath, []byte(cfg.Server.AuditKey))
+	if err != nil {
+		return err
+	}
+
+	if *verify {
+		verification, err := server.VerifyAudit(log)
+		if err != nil {
+			return err
+		}
+		if !verification.Valid {
+			return errors.New(verification.Error)
+		}
+		fmt.Printf("Audit log valid: %d entries, head %s\n", verification.Entries, verification.Head)
+		return nil
+	}
+
+	entries, err := log.Query(audit.Filter{
+		Tenant:     *tenant,
+		AllTenants: *tenant == "",
+		Action:     *action,
+		RunID:      *runID,
+		Actor:      *actor,
+	})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, entry := range entries {
+		if *jsonOut {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Printf("%d %s tenant=%s actor=%s %s run=%s %s\n", entry.Seq, entry.Time.Format("2006-01-02T15:04:05Z"),
+			valueOr(entry.Tenant, "-"), valueOr(entry.Actor, "-"), entry.Action, valueOr(entry.RunID, "-"), entry.Details)
+	}
+
+	return nil
+}
+
+// valueOr returns value, or fallback when value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
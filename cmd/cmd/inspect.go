@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+)
+
+// fileStats summarizes the records parsed out of an input file
+type fileStats struct {
+	records, invalid        int
+	debits, credits         int
+	debitTotal, creditTotal float64
+	first, last             time.Time
+}
+
+func (s *fileStats) add(trxType string, amount float64, date time.Time) {
+	s.records++
+	if trxType == "DEBIT" {
+		s.debits++
+		s.debitTotal += amount
+	} else {
+		s.credits++
+		s.creditTotal += amount
+	}
+	if s.first.IsZero() || date.Before(s.first) {
+		s.first = date
+	}
+	if date.After(s.last) {
+		s.last = date
+	}
+}
+
+// runInspect prints the records parsed out of a system or bank file, followed by statistics
+func runInspect(args []string) error {
+	fs := newFlagSet("inspect", "-system <file> | -bank <file> [flags]")
+	system := fs.String("system", "", "Specify file path for system transactions")
+	bank := fs.String("bank", "", "Specify file path for bank transactions")
+	limit := fs.Int("limit", 0, "Maximum number of records to print, all when zero")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if (*system == "") == (*bank == "") {
+		return usagef("exactly one of -system or -bank is required")
+	}
+	if *limit < 0 {
+		return usagef("-limit must be a non-negative number")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var stats fileStats
+	var parseErrors []model.ParseError
+	printed := 0
+	printRow := func(format string, args ...any) {
+		if *limit == 0 || printed < *limit {
+			fmt.Fprintf(w, format, args...)
+			printed++
+		}
+	}
+
+	path := *system
+	if *system != "" {
+		transactions, errs, err := reconciliation.ParseSystemFile(*system)
+		if err != nil {
+			return err
+		}
+		parseErrors = errs
+
+		fmt.Fprintln(w, "TRX ID\tTYPE\tAMOUNT\tTIME\tDESCRIPTION")
+		for _, tx := range transactions {
+			printRow("%s\t%s\t%.2f\t%s\t%s\n", tx.TrxID, tx.Type, tx.Amount, tx.TransactionTime.Format("2006-01-02 15:04:05"), tx.Description)
+			stats.add(tx.Type, tx.Amount, tx.TransactionTime)
+		}
+	} else {
+		path = *bank
+		statements, errs, err := reconciliation.ParseBankFile(*bank)
+		if err != nil {
+			return err
+		}
+		parseErrors = errs
+
+		fmt.Fprintln(w, "IDENTIFIER\tTYPE\tAMOUNT\tDATE\tDESCRIPTION")
+		for _, stmt := range statements {
+			printRow("%s\t%s\t%.2f\t%s\t%s\n", stmt.UniqueIdentifier, stmt.Type, stmt.Amount, stmt.Date.Format("2006-01-02"), stmt.Description)
+			stats.add(stmt.Type, stmt.Amount, stmt.Date)
+		}
+	}
+	stats.invalid = len(parseErrors)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if printed < stats.records {
+		fmt.Printf("... %d more records\n", stats.records-printed)
+	}
+
+	fmt.Printf("\nFile: %s\n", path)
+	fmt.Printf("Records: %d\n", stats.records)
+	fmt.Printf("Invalid rows: %d\n", stats.invalid)
+	fmt.Printf("Debits: %d, total %.2f\n", stats.debits, stats.debitTotal)
+	fmt.Printf("Credits: %d, total %.2f\n", stats.credits, stats.creditTotal)
+	if stats.records > 0 {
+		fmt.Printf("Date range: %s to %s\n", stats.first.Format("2006-01-02"), stats.last.Format("2006-01-02"))
+	}
+	for _, parseErr := range parseErrors {
+		fmt.Printf("WARNING: line %d skipped: %s\n", parseErr.Line, parseErr.Message)
+	}
+
+	return nil
+}
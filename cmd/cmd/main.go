@@ -1,12 +1,24 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"os"
+	"path/filepath"
 	"strings"
+)
 
-	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+// Exit codes of the commands
+// exitFailure: the command failed, e.g. an input file cannot be read or the server stopped on an error
+// exitInput: the command was invoked with invalid flags, or reconcile could not read its input
+// exitExceptions: reconcile found exceptions above the thresholds, kept apart from failures so
+// scheduled jobs can tell a run that needs review from a run that did not complete
+const (
+	exitOK         = 0
+	exitFailure    = 1
+	exitInput      = 2
+	exitExceptions = 3
 )
 
 // Define a custom type for the array of strings
@@ -23,40 +35,144 @@ func (s *stringArray) Set(value string) error {
 	return nil
 }
 
+// usageError reports a command invoked with missing or invalid arguments
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exceptionsError reports a reconciliation whose exceptions exceed the configured thresholds
+type exceptionsError struct {
+	msg string
+}
+
+func (e exceptionsError) Error() string {
+	return e.msg
+}
+
+// command is a subcommand of the CLI, run receives the arguments following its name
+// failure: Exit code used when run returns any other error than a usage or exceptions error
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+	failure int
+}
+
+var commands = []command{
+	{"reconcile", "Reconcile system transactions against bank statements", runReconcile, exitInput},
+	{"validate", "Parse input files and report the rows that cannot be read", runValidate, exitFailure},
+	{"inspect", "Print the parsed records and statistics of an input file", runInspect, exitFailure},
+	{"serve", "Start the HTTP server", runServe, exitFailure},
+	{"audit", "Query the audit log or verify its hash chain", runAudit, exitFailure},
+	{"rekey", "Encrypt uploads and stored runs with the first encryption key", runRekey, exitFailure},
+}
+
 func main() {
-	// Define a string array flag
-	var system, bank, startDate, endDate stringArray
-	flag.Var(&system, "system", "Specify file path for system transactions")
-	flag.Var(&bank, "bank", "Specify file paths (can be used multiple times) for bank transactions")
-	flag.Var(&startDate, "start", "Specify start date")
-	flag.Var(&endDate, "end", "Specify end date")
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches args to a subcommand and returns the exit code.
+// Arguments starting with a flag are a reconcile invocation, as before subcommands existed.
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage()
+		return exitInput
+	}
 
-	// Parse the command-line flags
-	flag.Parse()
+	name := args[0]
+	switch {
+	case name == "-h" || name == "-help" || name == "--help" || name == "help":
+		printUsage()
+		return exitOK
+	case strings.HasPrefix(name, "-"):
+		name = "reconcile"
+	default:
+		args = args[1:]
+	}
 
-	svc := reconciliation.New(bank, system[0], startDate[0], endDate[0])
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
 
-	result, err := svc.Reconcile()
-	if err != nil {
-		log.Fatal(err)
+		err := cmd.run(args)
+		var usageErr usageError
+		var exceptionsErr exceptionsError
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.As(err, &usageErr):
+			// Flag parsing errors are already printed by the flag set
+			if usageErr.msg != "" {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", name, usageErr.msg)
+				fmt.Fprintf(os.Stderr, "Run '%s %s -h' for usage.\n", programName(), name)
+			}
+			return exitInput
+		case errors.As(err, &exceptionsErr):
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			return exitExceptions
+		default:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			return cmd.failure
+		}
 	}
 
-	// Print reconciliation summary
-	fmt.Println("Reconciliation Summary")
-	fmt.Println("-----------------------")
-	fmt.Printf("Total transactions processed: %d\n", result.TotalProcessed)
-	fmt.Printf("Total matched transactions: %d\n", result.Matched)
-	fmt.Printf("Total unmatched transactions: %d\n", result.Unmatched)
-	fmt.Printf("Total discrepancies: %.2f\n", result.Discrepancies)
-	fmt.Println("\nUnmatched System Transactions:")
-	for _, tx := range result.UnmatchedSystem {
-		fmt.Println(tx)
+	fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+	printUsage()
+	return exitInput
+}
+
+// newFlagSet returns a flag set for a subcommand whose usage lists its flags under synopsis
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n\nFlags:\n", programName(), name, synopsis)
+		fs.PrintDefaults()
 	}
-	fmt.Println("\nUnmatched By Bank Transactions:")
-	for key, transactions := range result.UnmatchedByBank {
-		fmt.Println(key)
-		for _, tx := range transactions {
-			fmt.Println(tx)
+	return fs
+}
+
+// parseFlags parses args, turning flag errors into usage errors
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
 		}
+		return usageError{}
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected argument %q", fs.Arg(0))
 	}
+	return nil
+}
+
+// setFlags returns the names of the flags set on the command line
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", programName())
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", programName())
+	fmt.Fprintf(os.Stderr, "\nExit codes:\n  %d  success\n  %d  the command failed\n  %d  missing or invalid flags, or unreadable reconcile input\n  %d  reconcile found exceptions above the thresholds\n",
+		exitOK, exitFailure, exitInput, exitExceptions)
+}
+
+func programName() string {
+	return filepath.Base(os.Args[0])
 }
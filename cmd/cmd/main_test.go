@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/dirlock"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReconcileExitCodes(t *testing.T) {
+	t.Setenv("RECONCILE_CONFIG", "")
+	dir := t.TempDir()
+
+	system := writeFile(t, dir, "system.csv", "TrxID,Amount,Type,TransactionTime\nT1,100.00,CREDIT,2024-01-02 10:00:00\n")
+	invalid := writeFile(t, dir, "invalid.csv", "TrxID,Amount,Type,TransactionTime\nT1,abc,CREDIT,2024-01-02 10:00:00\nT2,100.00,REFUND,2024-01-02 10:00:00\n")
+	bank := writeFile(t, dir, "bank-a.csv", "UniqueIdentifier,Amount,Date\nT1,100.00,2024-01-02\n")
+	unbalanced := writeFile(t, dir, "bank-b.csv", "UniqueIdentifier,Amount,Date\nopening_balance,1000.00,2024-01-02\nT1,100.00,2024-01-02\nclosing_balance,1200.00,2024-01-02\n")
+	out := filepath.Join(dir, "report.txt")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"reconciled", []string{"-system", system, "-bank", bank}, exitOK},
+		{"rejected rows", []string{"-system", invalid, "-bank", bank, "-max-unmatched", "10"}, exitExceptions},
+		{"unbalanced statement", []string{"-system", system, "-bank", unbalanced}, exitExceptions},
+		{"above thresholds", []string{"-system", system, "-bank", bank, "-bank", unbalanced}, exitExceptions},
+		{"missing file", []string{"-system", filepath.Join(dir, "missing.csv"), "-bank", bank}, exitInput},
+		{"missing flag", []string{"-bank", bank}, exitInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"reconcile", "-start", "2024-01-01", "-end", "2024-01-31", "-out", out}, tt.args...)
+			if got := run(args); got != tt.want {
+				t.Errorf("run(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "report.txt", "previous report\n")
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A report failing halfway leaves the previous one in place
+	err := writeReport(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errors.New("render failed")
+	})
+	if err == nil {
+		t.Fatal("writeReport() with a failing render succeeded")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "previous report\n" {
+		t.Errorf("report after a failed write = %q, want the previous report", data)
+	}
+
+	if err := writeReport(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new report\n")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new report\n" {
+		t.Errorf("report = %q, want the new report", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("report mode = %v, want the mode of the replaced file", info.Mode().Perm())
+	}
+
+	// No temporary file is left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d files, want only the report", len(entries))
+	}
+}
+
+func TestRekeyRefusesLockedDirectory(t *testing.T) {
+	t.Setenv("RECONCILE_CONFIG", "")
+	dir := t.TempDir()
+	uploads, runs := filepath.Join(dir, "uploads"), filepath.Join(dir, "runs")
+	for _, d := range []string{uploads, runs} {
+		if err := os.Mkdir(d, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configPath := writeFile(t, dir, "config.json", `{"encryption": {"keys": [{"id": "k1", "passphrase": "passphrase", "salt": "0123456789abcdef"}]}}`)
+	args := []string{"-config", configPath, "-uploads", uploads, "-runs", runs}
+
+	lock, err := dirlock.Shared(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runRekey(args); err == nil || !strings.Contains(err.Error(), "in use by a running server") {
+		t.Errorf("runRekey() while the server runs = %v, want a refusal", err)
+	}
+
+	lock.Release()
+	if err := runRekey(args); err != nil {
+		t.Errorf("runRekey() = %v", err)
+	}
+}
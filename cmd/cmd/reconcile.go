@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/report"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+	"github.com/arham-abiyan/reconciliation/pkg"
+)
+
+func runReconcile(args []string) error {
+	defaults := config.Default()
+	fs := newFlagSet("reconcile", "-system <file> -bank <file> [-bank <file>...] -start <date> -end <date> [flags]")
+	var bank stringArray
+	system := fs.String("system", "", "Specify file path for system transactions")
+	fs.Var(&bank, "bank", "Specify file paths (can be used multiple times) for bank transactions")
+	startDate := fs.String("start", "", "Specify start date (YYYY-MM-DD)")
+	endDate := fs.String("end", "", "Specify end date (YYYY-MM-DD)")
+	transferWindow := fs.Int("transfer-window", defaults.Matching.TransferWindowDays, "Maximum days between the legs of an internal transfer")
+	feesPath := fs.String("fees", "", "Specify file path for the per-bank fee rules (JSON)")
+	balancesPath := fs.String("balances", "", "Specify file path for the statement balances (CSV or MT940)")
+	matches := fs.String("matches", reconciliation.MatchesAll, "Matched pairs to list: all, imperfect or none")
+	format := fs.String("format", report.FormatText, "Report format: text, csv, xlsx, html or pdf")
+	outPath := fs.String("out", "", "Specify file path for the report, standard output when empty")
+	jsonOut := fs.Bool("json", false, "Write the reconciliation result as JSON instead of a report")
+	maxUnmatched := fs.Int("max-unmatched", defaults.Matching.MaxUnmatched, "Number of unmatched transactions tolerated before exiting with status 3")
+	maxDiscrepancy := fs.Float64("max-discrepancy", defaults.Matching.MaxDiscrepancy, "Total discrepancy amount tolerated before exiting with status 3")
+	configPath := fs.String("config", "", "Specify file path for the config file (JSON), "+config.EnvConfigFile+" when empty")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	set := setFlags(fs)
+
+	switch {
+	case *system == "":
+		return usagef("-system is required")
+	case len(bank) == 0:
+		return usagef("at least one -bank is required")
+	case *startDate == "" || *endDate == "":
+		return usagef("-start and -end are required")
+	case *transferWindow < 0:
+		return usagef("-transfer-window must be a non-negative number")
+	case *matches != reconciliation.MatchesAll && *matches != reconciliation.MatchesImperfect && *matches != reconciliation.MatchesNone:
+		return usagef("-matches must be one of all, imperfect or none")
+	case report.ContentType(*format) == "":
+		return usagef("unknown report format %q", *format)
+	case *jsonOut && set["format"]:
+		return usagef("-json and -format cannot be used together")
+	case *maxUnmatched < 0 || *maxDiscrepancy < 0:
+		return usagef("-max-unmatched and -max-discrepancy must be non-negative")
+	}
+	if err := pkg.ValidateDates(*startDate, *endDate); err != nil {
+		return usagef("%v", err)
+	}
+
+	// Flags set on the command line override the config file and the environment
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	if set["transfer-window"] {
+		cfg.Matching.TransferWindowDays = *transferWindow
+	}
+	if set["max-unmatched"] {
+		cfg.Matching.MaxUnmatched = *maxUnmatched
+	}
+	if set["max-discrepancy"] {
+		cfg.Matching.MaxDiscrepancy = *maxDiscrepancy
+	}
+	if set["matches"] || cfg.Output.Matches == "" {
+		cfg.Output.Matches = *matches
+	}
+	if set["format"] {
+		cfg.Output.Format, cfg.Output.JSON = *format, false
+	}
+	if set["json"] {
+		cfg.Output.JSON = *jsonOut
+	}
+	if cfg.Output.Format == "" {
+		cfg.Output.Format = report.FormatText
+	}
+
+	// Rules of the fees file override the bank profiles of the config
+	feeRules := cfg.FeeRules()
+	if *feesPath != "" {
+		file, err := os.Open(*feesPath)
+		if err != nil {
+			return err
+		}
+		rules, err := reconciliation.ParseFeeRules(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		feeRules = append(feeRules, rules...)
+	}
+
+	var balances []model.StatementBalance
+	if *balancesPath != "" {
+		file, err := os.Open(*balancesPath)
+		if err != nil {
+			return err
+		}
+		balances, err = reconciliation.ParseBalances(file, *balancesPath)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	svc := reconciliation.New(bank, *system, *startDate, *endDate,
+		reconciliation.WithTransferWindow(cfg.Matching.TransferWindowDays),
+		reconciliation.WithFeeRules(feeRules...),
+		reconciliation.WithStatementBalances(balances...),
+		reconciliation.WithMatches(cfg.Output.Matches),
+	)
+
+	result, err := svc.Reconcile()
+	if err != nil {
+		return err
+	}
+
+	run := model.Run{
+		Params: model.RunParams{
+			StartDate:  *startDate,
+			EndDate:    *endDate,
+			SystemFile: *system,
+			BankFiles:  bank,
+		},
+		Result: result,
+	}
+	for _, input := range []struct {
+		role  string
+		paths []string
+	}{{"system", []string{*system}}, {"bank", bank}, {"balances", nonEmpty(*balancesPath)}} {
+		for _, path := range input.paths {
+			checksum, err := pkg.FileChecksum(path)
+			if err != nil {
+				return err
+			}
+			run.Params.InputFiles = append(run.Params.InputFiles, model.InputFile{Role: input.role, Name: path, SHA256: checksum})
+		}
+	}
+
+	// Reports leave the machine, they are masked like the responses of the server
+	masker, err := cfg.Masking.Masker()
+	if err != nil {
+		return err
+	}
+	masked := masker.Run(run)
+
+	write := func(out io.Writer) error {
+		if cfg.Output.JSON {
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(&masked.Result)
+		}
+		return report.Write(out, cfg.Output.Format, masked)
+	}
+	if *outPath == "" {
+		err = write(os.Stdout)
+	} else {
+		err = writeReport(*outPath, write)
+	}
+	if err != nil {
+		return err
+	}
+
+	return checkThresholds(result, cfg.Matching.MaxUnmatched, cfg.Matching.MaxDiscrepancy)
+}
+
+// writeReport writes the report to path through a temporary file renamed once the report is
+// complete, so a failed run never leaves a truncated report or replaces the previous one.
+// A report replacing an existing file keeps its permissions.
+func writeReport(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".report-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// checkThresholds fails with an exceptions error when result is not fully reconciled: rows of the
+// input files were rejected, a statement does not balance, or the unmatched transactions or the
+// total discrepancy go beyond what is tolerated
+func checkThresholds(result model.ReconcileResponse, maxUnmatched int, maxDiscrepancy float64) error {
+	var exceptions []string
+	if len(result.ParseErrors) > 0 {
+		exceptions = append(exceptions, fmt.Sprintf("%d rows rejected", len(result.ParseErrors)))
+	}
+	for _, check := range result.BalanceChecks {
+		if !check.Balanced {
+			exceptions = append(exceptions, fmt.Sprintf("statement of %s off by %.2f", check.Bank, check.Difference))
+		}
+	}
+	if result.Unmatched > maxUnmatched || result.Discrepancies > maxDiscrepancy {
+		exceptions = append(exceptions, fmt.Sprintf("exceptions above thresholds: %d unmatched (max %d), %.2f discrepancies (max %.2f)",
+			result.Unmatched, maxUnmatched, result.Discrepancies, maxDiscrepancy))
+	}
+	if len(exceptions) == 0 {
+		return nil
+	}
+
+	return exceptionsError{msg: strings.Join(exceptions, "; ")}
+}
+
+// nonEmpty returns a slice holding value, or an empty slice when value is empty
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
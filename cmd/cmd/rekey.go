@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/dirlock"
+)
+
+// runRekey encrypts the uploads and stored runs with the first configured encryption key, after a
+// key rotation or when encryption is enabled on existing files. Only the data keys are rewrapped,
+// the content of files already encrypted is not decrypted again.
+func runRekey(args []string) error {
+	defaults := config.Default()
+	flags := newFlagSet("rekey", "[flags]")
+	uploadsDir := flags.String("uploads", defaults.Server.UploadsDir, "Directory for uploaded files")
+	runsDir := flags.String("runs", defaults.Server.RunsDir, "Directory for stored reconciliation runs")
+	configPath := flags.String("config", "", "Specify file path for the config file (JSON), "+config.EnvConfigFile+" when empty")
+
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	set := setFlags(flags)
+	if set["uploads"] {
+		cfg.Server.UploadsDir = *uploadsDir
+	}
+	if set["runs"] {
+		cfg.Server.RunsDir = *runsDir
+	}
+
+	keys, err := cfg.Encryption.Keyring()
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		return errors.New("no encryption key is configured")
+	}
+
+	// A running server would write files sealed with the previous key behind the walk
+	dirs := []string{cfg.Server.UploadsDir, cfg.Server.RunsDir}
+	for _, dir := range dirs {
+		lock, err := dirlock.Exclusive(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if errors.Is(err, dirlock.ErrLocked) {
+			return fmt.Errorf("%s is in use by a running server, stop it before rekey", dir)
+		}
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+
+	var rewrapped, current int
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && path == dir {
+				return filepath.SkipDir
+			}
+			// Files being written are renamed into place once complete
+			if err != nil || entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || strings.HasSuffix(entry.Name(), ".tmp") {
+				return err
+			}
+
+			changed, err := keys.RewrapFile(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if changed {
+				rewrapped++
+			} else {
+				current++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Encrypted %d files with key %s, %d already were\n", rewrapped, keys.Primary(), current)
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/server"
+)
+
+func runServe(args []string) error {
+	defaults := config.Default()
+	fs := newFlagSet("serve", "[flags]")
+	addr := fs.String("addr", defaults.Server.Addr, "Address the server listens on")
+	grpcAddr := fs.String("grpc-addr", defaults.Server.GRPCAddr, "Address the gRPC server listens on, not started when empty")
+	uploadsDir := fs.String("uploads", defaults.Server.UploadsDir, "Directory for uploaded files")
+	runsDir := fs.String("runs", defaults.Server.RunsDir, "Directory for stored reconciliation runs")
+	maxUploadSize := fs.Int64("max-upload-size", defaults.Server.MaxUploadSize, "Maximum size of a reconciliation request in bytes")
+	maxConcurrent := fs.Int("max-concurrent", defaults.Server.MaxConcurrent, "Maximum number of reconciliations running at once")
+	configPath := fs.String("config", "", "Specify file path for the config file (JSON), "+config.EnvConfigFile+" when empty")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *maxUploadSize <= 0 || *maxConcurrent <= 0 {
+		return usagef("-max-upload-size and -max-concurrent must be positive")
+	}
+
+	// Flags set on the command line override the config file and the environment
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	set := setFlags(fs)
+	if set["addr"] {
+		cfg.Server.Addr = *addr
+	}
+	if set["grpc-addr"] {
+		cfg.Server.GRPCAddr = *grpcAddr
+	}
+	if set["uploads"] {
+		cfg.Server.UploadsDir = *uploadsDir
+	}
+	if set["runs"] {
+		cfg.Server.RunsDir = *runsDir
+	}
+	if set["max-upload-size"] {
+		cfg.Server.MaxUploadSize = *maxUploadSize
+	}
+	if set["max-concurrent"] {
+		cfg.Server.MaxConcurrent = *maxConcurrent
+	}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	// SIGTERM and interrupts stop the server once the running reconciliations are done
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return srv.Run(ctx)
+}
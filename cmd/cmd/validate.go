@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+)
+
+// runValidate parses the input files without reconciling them, printing every row that cannot be read.
+// It fails when a file cannot be read or has invalid rows.
+func runValidate(args []string) error {
+	fs := newFlagSet("validate", "[-system <file>] [-bank <file>...]")
+	var bank stringArray
+	system := fs.String("system", "", "Specify file path for system transactions")
+	fs.Var(&bank, "bank", "Specify file paths (can be used multiple times) for bank transactions")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *system == "" && len(bank) == 0 {
+		return usagef("at least one -system or -bank file is required")
+	}
+
+	invalidFiles := 0
+	check := func(path string, records int, parseErrors []model.ParseError, err error) {
+		if err != nil {
+			invalidFiles++
+			fmt.Printf("%s: %v\n", path, err)
+			return
+		}
+		if len(parseErrors) > 0 {
+			invalidFiles++
+		}
+
+		fmt.Printf("%s: %d records, %d invalid rows\n", path, records, len(parseErrors))
+		for _, parseErr := range parseErrors {
+			fmt.Printf("  line %d: %s\n", parseErr.Line, parseErr.Message)
+		}
+	}
+
+	if *system != "" {
+		transactions, parseErrors, err := reconciliation.ParseSystemFile(*system)
+		check(*system, len(transactions), parseErrors, err)
+	}
+	for _, path := range bank {
+		statements, parseErrors, err := reconciliation.ParseBankFile(path)
+		check(path, len(statements), parseErrors, err)
+	}
+
+	if invalidFiles > 0 {
+		return fmt.Errorf("%d of %d files are invalid", invalidFiles, len(bank)+len(nonEmpty(*system)))
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Actions recorded in the audit log
+// ActionRunFailed: A reconciliation attempt that did not store a run, rejected or failed
+// ActionSignOffFailed: A sign-off attempt that did not attach its report
+// ActionManualMatch/ActionWriteOff: Exceptions of a run resolved by hand, see model.Override
+// ActionOverrideFailed: A manual match or write-off attempt that was not stored
+const (
+	ActionUpload         = "upload"
+	ActionRun            = "run"
+	ActionSignOff        = "sign_off"
+	ActionRunFailed      = "run_failed"
+	ActionSignOffFailed  = "sign_off_failed"
+	ActionManualMatch    = "manual_match"
+	ActionWriteOff       = "write_off"
+	ActionOverrideFailed = "override_failed"
+)
+
+// Entry is a record of the audit log. Every entry holds the hash of the previous one, so changing,
+// removing or reordering entries breaks the chain from that point on.
+// Seq: 1-based position of the entry in the log
+// Actor: Caller who performed the action, empty when authentication is disabled
+// Details: Action specific data, e.g. the parameters of a run
+// Keyed: The hash is an HMAC-SHA256 keyed with the secret of the log, which cannot be recomputed
+// without it, instead of a plain SHA-256
+// Hash: Hash of the entry with an empty Hash, chained through PrevHash
+type Entry struct {
+	Seq      int64           `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Tenant   string          `json:"tenant,omitempty"`
+	Actor    string          `json:"actor,omitempty"`
+	Action   string          `json:"action"`
+	RunID    string          `json:"run_id,omitempty"`
+	Details  json.RawMessage `json:"details,omitempty"`
+	Keyed    bool            `json:"keyed,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// computeHash returns the hash of the entry, computed over its JSON encoding with an empty Hash.
+// Keyed entries are hashed with an HMAC keyed with key.
+func (e Entry) computeHash(key []byte) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	if e.Keyed {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(data)
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Filter selects entries of the log, empty fields match any entry
+type Filter struct {
+	Tenant string
+	Actor  string
+	Action string
+	RunID  string
+	Since  time.Time
+	Until  time.Time
+	// AllTenants lifts the tenant restriction, Tenant is ignored when set
+	AllTenants bool
+}
+
+func (f Filter) match(e Entry) bool {
+	return (f.AllTenants || e.Tenant == f.Tenant) &&
+		(f.Actor == "" || e.Actor == f.Actor) &&
+		(f.Action == "" || e.Action == f.Action) &&
+		(f.RunID == "" || e.RunID == f.RunID) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until))
+}
+
+// ChainError reports the first entry of the log breaking the hash chain
+type ChainError struct {
+	Seq    int64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("audit chain broken at entry %d: %s", e.Seq, e.Reason)
+}
+
+// Log is an append-only audit log stored as one JSON entry per line
+type Log struct {
+	path string
+	// key keys the hashes of new entries, they are plain SHA-256 hashes when empty
+	key      []byte
+	mu       sync.Mutex
+	lastSeq  int64
+	lastHash string
+	// torn is the offset of an entry whose write was interrupted at the end of the log, dropped by
+	// the next Append, -1 when the log ends with a complete entry
+	torn int64
+}
+
+// Open opens the log at path, creating its directory when needed. New entries are keyed with key
+// when it is not empty, so the chain cannot be rewritten by someone able to edit the file only.
+// The chain is not verified, new entries are chained to the last one whatever the state of the
+// log is, see Verify. An entry left incomplete by a crash at the end of the log is reported and
+// dropped by the next Append, new entries follow the last complete one.
+func Open(path string, key []byte) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	l := &Log{path: path, key: key}
+	var last Entry
+	torn, err := l.scan(func(e Entry) error {
+		last = e
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.lastSeq, l.lastHash, l.torn = last.Seq, last.Hash, torn
+	if torn >= 0 {
+		log.Printf("Audit log %s ends with an interrupted write after entry %d, dropped by the next entry", path, last.Seq)
+	}
+
+	return l, nil
+}
+
+// Append chains entry to the log and writes it durably, filling its sequence, time and hashes
+func (l *Log) Append(entry Entry) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Seq = l.lastSeq + 1
+	entry.Time = time.Now().UTC()
+	entry.PrevHash = l.lastHash
+	entry.Keyed = len(l.key) > 0
+	hash, err := entry.computeHash(l.key)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry.Hash = hash
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if l.torn >= 0 {
+		if err := os.Truncate(l.path, l.torn); err != nil {
+			return Entry{}, err
+		}
+		l.torn = -1
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return Entry{}, err
+	}
+	if err := file.Sync(); err != nil {
+		return Entry{}, err
+	}
+
+	l.lastSeq, l.lastHash = entry.Seq, entry.Hash
+	return entry, nil
+}
+
+// Head returns the hash of the last entry, empty for an empty log. Kept elsewhere, it reveals a
+// log truncated since.
+func (l *Log) Head() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastHash
+}
+
+// Query returns the entries matching filter, oldest first
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	_, err := l.scan(func(e Entry) error {
+		if filter.match(e) {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// Verify walks the whole log checking every entry follows the previous one and holds its own hash.
+// Entries written before the log was keyed may be unkeyed, none may follow a keyed entry: they
+// could have been forged without the key.
+// It returns the number of entries, and a *ChainError for the first entry breaking the chain, an
+// interrupted entry at the end of the log included.
+func (l *Log) Verify() (int64, error) {
+	var seq int64
+	prevHash := ""
+	keyed := false
+	torn, err := l.scan(func(e Entry) error {
+		seq++
+		if e.Seq != seq {
+			return &ChainError{Seq: seq, Reason: fmt.Sprintf("found sequence %d", e.Seq)}
+		}
+		if e.PrevHash != prevHash {
+			return &ChainError{Seq: seq, Reason: "previous hash does not match"}
+		}
+		switch {
+		case e.Keyed && len(l.key) == 0:
+			return &ChainError{Seq: seq, Reason: "entry is keyed but no key is configured"}
+		case !e.Keyed && keyed:
+			return &ChainError{Seq: seq, Reason: "entry is not keyed but follows keyed entries"}
+		}
+		keyed = e.Keyed
+		hash, err := e.computeHash(l.key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(hash), []byte(e.Hash)) {
+			return &ChainError{Seq: seq, Reason: "entry hash does not match its content"}
+		}
+		prevHash = e.Hash
+		return nil
+	})
+	if err == nil && torn >= 0 {
+		err = &ChainError{Seq: seq + 1, Reason: "entry is incomplete, its write was interrupted"}
+	}
+	return seq, err
+}
+
+// scan calls fn for every entry of the log in order, a missing log has no entries. A last line
+// without its newline is an entry whose write was interrupted, not passed to fn: scan returns its
+// offset, -1 when the log ends with a complete entry.
+func (l *Log) scan(fn func(Entry) error) (int64, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	line, offset := int64(0), int64(0)
+	for {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				return offset, nil
+			}
+			return -1, nil
+		}
+		if err != nil {
+			return -1, err
+		}
+		line++
+		offset += int64(len(data))
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return -1, &ChainError{Seq: line, Reason: "entry is not valid JSON"}
+		}
+		if err := fn(entry); err != nil {
+			return -1, err
+		}
+	}
+}
+
+// Details encodes v as the details of an entry
+func Details(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,237 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestLog(t *testing.T) (*Log, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	log, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log, path
+}
+
+func appendEntries(t *testing.T, log *Log, entries ...Entry) {
+	t.Helper()
+	for _, entry := range entries {
+		if _, err := log.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAppendAndQuery(t *testing.T) {
+	log, path := openTestLog(t)
+	appendEntries(t, log,
+		Entry{Tenant: "retail", Actor: "jane", Action: ActionUpload, RunID: "r1", Details: Details(map[string]string{"name": "bank-a.csv"})},
+		Entry{Tenant: "retail", Actor: "jane", Action: ActionRun, RunID: "r1"},
+		Entry{Tenant: "corporate", Actor: "john", Action: ActionRun, RunID: "r2"},
+	)
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantIDs []string
+	}{
+		{name: "tenant", filter: Filter{Tenant: "retail"}, wantIDs: []string{"r1", "r1"}},
+		{name: "tenant and action", filter: Filter{Tenant: "retail", Action: ActionRun}, wantIDs: []string{"r1"}},
+		{name: "default tenant", filter: Filter{}, wantIDs: nil},
+		{name: "all tenants", filter: Filter{AllTenants: true, Action: ActionRun}, wantIDs: []string{"r1", "r2"}},
+		{name: "run", filter: Filter{AllTenants: true, RunID: "r2"}, wantIDs: []string{"r2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.wantIDs) {
+				t.Fatalf("Query() returned %d entries, want %d", len(entries), len(tt.wantIDs))
+			}
+			for i, entry := range entries {
+				if entry.RunID != tt.wantIDs[i] {
+					t.Errorf("entry %d run = %s, want %s", i, entry.RunID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+
+	// A reopened log keeps chaining after the last entry
+	reopened, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := reopened.Append(Entry{Action: ActionSignOff, RunID: "r1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Seq != 4 {
+		t.Errorf("Seq = %d, want 4", entry.Seq)
+	}
+	if n, err := reopened.Verify(); err != nil || n != 4 {
+		t.Errorf("Verify() = %d, %v, want 4 entries and no error", n, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(lines [][]byte) [][]byte
+		wantSeq int64
+	}{
+		{
+			name: "changed content",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte(`"actor":"jane"`), []byte(`"actor":"eve"`), 1)
+				return lines
+			},
+			wantSeq: 2,
+		},
+		{
+			name: "removed entry",
+			tamper: func(lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+			wantSeq: 2,
+		},
+		{
+			name: "reordered entries",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+			wantSeq: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, path := openTestLog(t)
+			appendEntries(t, log,
+				Entry{Actor: "jane", Action: ActionUpload},
+				Entry{Actor: "jane", Action: ActionRun},
+				Entry{Actor: "jane", Action: ActionSignOff},
+			)
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := tt.tamper(bytes.Split(bytes.TrimSpace(data), []byte("\n")))
+			if err := os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = log.Verify()
+			var chainErr *ChainError
+			if !errors.As(err, &chainErr) {
+				t.Fatalf("Verify() error = %v, want a chain error", err)
+			}
+			if chainErr.Seq != tt.wantSeq {
+				t.Errorf("chain broken at %d, want %d", chainErr.Seq, tt.wantSeq)
+			}
+		})
+	}
+}
+
+func TestOpenInterruptedEntry(t *testing.T) {
+	log, path := openTestLog(t)
+	appendEntries(t, log, Entry{Actor: "jane", Action: ActionUpload}, Entry{Actor: "jane", Action: ActionRun})
+	head := log.Head()
+
+	// A crash left the third entry half written
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"seq":3,"actor":"ja`)
+	file.Close()
+
+	reopened, err := Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	if reopened.Head() != head {
+		t.Errorf("Head() = %s, want the hash of the last complete entry %s", reopened.Head(), head)
+	}
+	if entries, err := reopened.Query(Filter{}); err != nil || len(entries) != 2 {
+		t.Errorf("Query() = %d entries, %v", len(entries), err)
+	}
+	var chainErr *ChainError
+	if _, err := reopened.Verify(); !errors.As(err, &chainErr) || chainErr.Seq != 3 {
+		t.Errorf("Verify() error = %v, want the interrupted entry 3", err)
+	}
+
+	// The next entry replaces the interrupted one
+	entry, err := reopened.Append(Entry{Actor: "jane", Action: ActionSignOff})
+	if err != nil || entry.Seq != 3 {
+		t.Fatalf("Append() = %+v, %v", entry, err)
+	}
+	if entries, err := reopened.Verify(); err != nil || entries != 3 {
+		t.Errorf("Verify() = %d, %v", entries, err)
+	}
+}
+
+func TestKeyedChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("0123456789abcdef")
+
+	// Entries written before the key was configured stay valid
+	plain, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendEntries(t, plain, Entry{Actor: "jane", Action: ActionUpload})
+	keyed, err := Open(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendEntries(t, keyed, Entry{Actor: "jane", Action: ActionRun}, Entry{Actor: "jane", Action: ActionSignOff})
+	if n, err := keyed.Verify(); err != nil || n != 3 {
+		t.Fatalf("Verify() = %d, %v, want 3 entries and no error", n, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+
+	// Rewriting the last entry as a plain one, with a recomputed SHA-256, is detected
+	var entry Entry
+	if err := json.Unmarshal(lines[2], &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Actor, entry.Keyed, entry.Hash = "eve", false, ""
+	encoded, _ := json.Marshal(entry)
+	sum := sha256.Sum256(encoded)
+	entry.Hash = hex.EncodeToString(sum[:])
+	lines[2], _ = json.Marshal(entry)
+	if err := os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var chainErr *ChainError
+	if _, err := keyed.Verify(); !errors.As(err, &chainErr) || chainErr.Seq != 3 {
+		t.Errorf("Verify() of a forged entry = %v, want a chain error at 3", err)
+	}
+
+	// Keyed entries cannot be verified with another key
+	other, err := Open(path, []byte("fedcba9876543210"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Verify(); !errors.As(err, &chainErr) || chainErr.Seq != 2 {
+		t.Errorf("Verify() with another key = %v, want a chain error at 2", err)
+	}
+}
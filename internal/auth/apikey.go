@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader is the request header carrying a static API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKey is a static key given to a caller, only the SHA-256 hash of the key is kept
+type APIKey struct {
+	Name   string   `json:"name"`
+	SHA256 string   `json:"sha256"`
+	Tenant string   `json:"tenant,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// HashKey returns the hex encoded SHA-256 hash of key, as stored in the configuration
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeys authenticates requests by the key found in the X-API-Key header
+type APIKeys struct {
+	keys []APIKey
+}
+
+// NewAPIKeys checks every key is named and holds a valid hash
+func NewAPIKeys(keys []APIKey) (*APIKeys, error) {
+	for _, key := range keys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("api key name is required")
+		}
+		if hash, err := hex.DecodeString(key.SHA256); err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %s must hold a hex encoded SHA-256 hash", key.Name)
+		}
+	}
+	return &APIKeys{keys: keys}, nil
+}
+
+func (a *APIKeys) Authenticate(r *http.Request) (Principal, error) {
+	given := r.Header.Get(APIKeyHeader)
+	if given == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	// Every key is compared so the time taken does not tell which one is closest
+	hash := HashKey(given)
+	found := -1
+	for i, candidate := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(strings.ToLower(candidate.SHA256))) == 1 {
+			found = i
+		}
+	}
+	if found < 0 {
+		return Principal{}, fmt.Errorf("%w: unknown api key", ErrInvalidCredentials)
+	}
+
+	key := a.keys[found]
+	return Principal{Subject: key.Name, Tenant: key.Tenant, Roles: key.Roles, Method: "api_key"}, nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when the credentials of a request cannot be verified
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Roles granted to callers
+// RoleUploader: Runs reconciliations
+// RoleReviewer: Reads stored runs and their reports, resolves their exceptions by hand
+// RoleApprover: Signs off stored runs
+// RoleAdmin: Every operation of its tenant
+const (
+	RoleUploader = "uploader"
+	RoleReviewer = "reviewer"
+	RoleApprover = "approver"
+	RoleAdmin    = "admin"
+)
+
+// Principal is the authenticated caller of a request
+// Tenant: Business unit the caller belongs to, empty for the default tenant
+// Method: How the caller authenticated, "api_key" or "jwt"
+type Principal struct {
+	Subject string   `json:"subject"`
+	Tenant  string   `json:"tenant,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+	Method  string   `json:"method"`
+}
+
+// HasAnyRole reports whether the principal holds one of roles
+func (p Principal) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies the credentials of a request.
+// It returns ErrNoCredentials when the request carries none of the kind it handles, so another
+// authenticator can be tried, and an error wrapping ErrInvalidCredentials when they do not verify.
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+// Chain tries every authenticator in order until one of them finds credentials
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(r)
+		if !errors.Is(err, ErrNoCredentials) {
+			return principal, err
+		}
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying principal
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal stored in ctx, if any
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(Principal)
+	return principal, ok
+}
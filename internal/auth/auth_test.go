@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)
+
+// signToken builds a token with the given header algorithm, signed by sign
+func signToken(t *testing.T, alg string, claims map[string]any, sign func([]byte) []byte) string {
+	t.Helper()
+
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(map[string]string{"alg": alg, "typ": "JWT"}) + "." + encode(claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func hs256(secret string) func([]byte) []byte {
+	return func(data []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(data)
+		return mac.Sum(nil)
+	}
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":    "jane",
+		"iss":    "https://issuer.example",
+		"aud":    []string{"reconciliation", "other"},
+		"exp":    testNow.Add(time.Hour).Unix(),
+		"roles":  []string{"reviewer"},
+		"tenant": "retail",
+	}
+}
+
+func authenticate(a Authenticator, header, value string) (Principal, error) {
+	req := httptest.NewRequest("GET", "/api/runs", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	return a.Authenticate(req)
+}
+
+func TestJWTHS256(t *testing.T) {
+	authenticator, err := NewJWT(JWTConfig{
+		Algorithm: AlgorithmHS256,
+		Secret:    []byte("secret"),
+		Issuer:    "https://issuer.example",
+		Audience:  "reconciliation",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator.now = func() time.Time { return testNow }
+
+	with := func(change func(map[string]any)) map[string]any {
+		claims := validClaims()
+		change(claims)
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    Principal
+		wantErr error
+	}{
+		{
+			name:  "valid token",
+			token: signToken(t, AlgorithmHS256, validClaims(), hs256("secret")),
+			want:  Principal{Subject: "jane", Tenant: "retail", Roles: []string{"reviewer"}, Method: "jwt"},
+		},
+		{
+			name:  "space separated roles and single audience",
+			token: signToken(t, AlgorithmHS256, with(func(c map[string]any) { c["roles"] = "uploader admin"; c["aud"] = "reconciliation" }), hs256("secret")),
+			want:  Principal{Subject: "jane", Tenant: "retail", Roles: []string{"uploader", "admin"}, Method: "jwt"},
+		},
+		{
+			name:    "wrong secret",
+			token:   signToken(t, AlgorithmHS256, validClaims(), hs256("other")),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "algorithm none",
+			token:   signToken(t, "none", validClaims(), func([]byte) []byte { return nil }),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "expired",
+			token:   signToken(t, AlgorithmHS256, with(func(c map[string]any) { c["exp"] = testNow.Add(-time.Minute).Unix() }), hs256("secret")),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "without expiry",
+			token:   signToken(t, AlgorithmHS256, with(func(c map[string]any) { delete(c, "exp") }), hs256("secret")),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "not valid yet",
+			token:   signToken(t, AlgorithmHS256, with(func(c map[string]any) { c["nbf"] = testNow.Add(time.Minute).Unix() }), hs256("secret")),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signToken(t, AlgorithmHS256, with(func(c map[string]any) { c["iss"] = "https://evil.example" }), hs256("secret")),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "wrong audience",
+			token:   signToken(t, AlgorithmHS256, with(func(c map[string]any) { c["aud"] = "billing" }), hs256("secret")),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "malformed",
+			token:   "not-a-token",
+			wantErr: ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := authenticate(authenticator, "Authorization", "Bearer "+tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authenticate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := authenticate(authenticator, "", ""); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Authenticate() without header error = %v, want %v", err, ErrNoCredentials)
+	}
+}
+
+func TestJWTRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	authenticator, err := NewJWT(JWTConfig{Algorithm: AlgorithmRS256, PublicKey: publicPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator.now = func() time.Time { return testNow }
+
+	rs256 := func(data []byte) []byte {
+		digest := sha256.Sum256(data)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+
+	if _, err := authenticate(authenticator, "Authorization", "Bearer "+signToken(t, AlgorithmRS256, validClaims(), rs256)); err != nil {
+		t.Errorf("Authenticate() error = %v", err)
+	}
+
+	// An HS256 token keyed with the public key must not pass for an RS256 one
+	forged := signToken(t, AlgorithmHS256, validClaims(), hs256(string(publicPEM)))
+	if _, err := authenticate(authenticator, "Authorization", "Bearer "+forged); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() forged token error = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	authenticator, err := NewAPIKeys([]APIKey{
+		{Name: "nightly", SHA256: HashKey("key-1"), Tenant: "retail", Roles: []string{"uploader"}},
+		{Name: "auditor", SHA256: HashKey("key-2")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := authenticate(authenticator, APIKeyHeader, "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Principal{Subject: "nightly", Tenant: "retail", Roles: []string{"uploader"}, Method: "api_key"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Authenticate() = %+v, want %+v", got, want)
+	}
+
+	if _, err := authenticate(authenticator, APIKeyHeader, "key-3"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() unknown key error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := NewAPIKeys([]APIKey{{Name: "plain", SHA256: "key-1"}}); err == nil {
+		t.Error("NewAPIKeys() accepted a key that is not hashed")
+	}
+}
+
+func TestChain(t *testing.T) {
+	keys, _ := NewAPIKeys([]APIKey{{Name: "nightly", SHA256: HashKey("key-1")}})
+	jwt, _ := NewJWT(JWTConfig{Algorithm: AlgorithmHS256, Secret: []byte("secret")})
+	chain := Chain{keys, jwt}
+
+	if got, err := authenticate(chain, APIKeyHeader, "key-1"); err != nil || got.Subject != "nightly" {
+		t.Errorf("Authenticate() = %+v, %v", got, err)
+	}
+	if _, err := authenticate(chain, "Authorization", "Bearer bad"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() bad token error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := authenticate(chain, "", ""); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Authenticate() without credentials error = %v, want %v", err, ErrNoCredentials)
+	}
+}
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// JWTConfig describes the bearer tokens accepted by the JWT authenticator
+// Secret: Shared secret of HS256 tokens
+// PublicKey: PEM encoded RSA public key verifying RS256 tokens
+// Issuer/Audience: When set, the iss claim must equal Issuer and the aud claim must contain Audience
+// RolesClaim: Claim holding the roles of the caller, "roles" when empty
+// TenantClaim: Claim holding the tenant of the caller, "tenant" when empty
+// Leeway: Clock skew tolerated on the exp and nbf claims
+type JWTConfig struct {
+	Algorithm   string
+	Secret      []byte
+	PublicKey   []byte
+	Issuer      string
+	Audience    string
+	RolesClaim  string
+	TenantClaim string
+	Leeway      time.Duration
+}
+
+// JWT authenticates requests by the bearer token of the Authorization header
+type JWT struct {
+	cfg       JWTConfig
+	publicKey *rsa.PublicKey
+	now       func() time.Time
+}
+
+// NewJWT checks the configuration holds the key the algorithm needs
+func NewJWT(cfg JWTConfig) (*JWT, error) {
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = "tenant"
+	}
+	j := &JWT{cfg: cfg, now: time.Now}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		if len(cfg.Secret) == 0 {
+			return nil, fmt.Errorf("HS256 tokens need a secret")
+		}
+	case AlgorithmRS256:
+		key, err := parseRSAPublicKey(cfg.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		j.publicKey = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q, use HS256 or RS256", cfg.Algorithm)
+	}
+
+	return j, nil
+}
+
+// parseRSAPublicKey reads a PEM encoded PKIX or PKCS #1 RSA public key
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("RS256 tokens need a PEM encoded public key")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
+	}
+	return key, nil
+}
+
+// claims are the registered claims checked on every token
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+// audience accepts the aud claim as a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("aud must be a string or an array of strings")
+	}
+	*a = list
+	return nil
+}
+
+func (j *JWT) Authenticate(r *http.Request) (Principal, error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return Principal{}, ErrNoCredentials
+	}
+
+	principal, err := j.verify(strings.TrimSpace(token))
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	return principal, nil
+}
+
+// verify checks the signature and the claims of token
+func (j *JWT) verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, fmt.Errorf("malformed token header")
+	}
+	// The algorithm is fixed by the configuration, never by the token, so "none" or an
+	// HS256 token signed with the RSA public key are rejected
+	if header.Algorithm != j.cfg.Algorithm {
+		return Principal{}, fmt.Errorf("unexpected signing algorithm %q", header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("malformed token signature")
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch j.cfg.Algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, j.cfg.Secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return Principal{}, fmt.Errorf("invalid signature")
+		}
+	case AlgorithmRS256:
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(j.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return Principal{}, fmt.Errorf("invalid signature")
+		}
+	}
+
+	var registered claims
+	if err := decodeSegment(parts[1], &registered); err != nil {
+		return Principal{}, fmt.Errorf("malformed token claims")
+	}
+	var raw map[string]json.RawMessage
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return Principal{}, fmt.Errorf("malformed token claims")
+	}
+
+	now := j.now()
+	if registered.ExpiresAt == nil {
+		return Principal{}, fmt.Errorf("token has no expiry")
+	}
+	if now.After(time.Unix(*registered.ExpiresAt, 0).Add(j.cfg.Leeway)) {
+		return Principal{}, fmt.Errorf("token expired")
+	}
+	if registered.NotBefore != nil && now.Add(j.cfg.Leeway).Before(time.Unix(*registered.NotBefore, 0)) {
+		return Principal{}, fmt.Errorf("token not valid yet")
+	}
+	if j.cfg.Issuer != "" && registered.Issuer != j.cfg.Issuer {
+		return Principal{}, fmt.Errorf("unexpected issuer %q", registered.Issuer)
+	}
+	if j.cfg.Audience != "" && !slices.Contains(registered.Audience, j.cfg.Audience) {
+		return Principal{}, fmt.Errorf("token is not meant for audience %q", j.cfg.Audience)
+	}
+	if registered.Subject == "" {
+		return Principal{}, fmt.Errorf("token has no subject")
+	}
+
+	roles, err := parseRoles(raw[j.cfg.RolesClaim])
+	if err != nil {
+		return Principal{}, err
+	}
+	var tenant string
+	if claim, ok := raw[j.cfg.TenantClaim]; ok {
+		if err := json.Unmarshal(claim, &tenant); err != nil {
+			return Principal{}, fmt.Errorf("tenant claim must be a string")
+		}
+	}
+
+	return Principal{Subject: registered.Subject, Tenant: tenant, Roles: roles, Method: "jwt"}, nil
+}
+
+// parseRoles reads a roles claim given as an array of strings or a space separated string
+func parseRoles(claim json.RawMessage) ([]string, error) {
+	if len(claim) == 0 {
+		return nil, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(claim, &list); err == nil {
+		return list, nil
+	}
+	var spaced string
+	if err := json.Unmarshal(claim, &spaced); err != nil {
+		return nil, fmt.Errorf("roles claim must be a string or an array of strings")
+	}
+	return strings.Fields(spaced), nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
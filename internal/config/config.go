@@ -0,0 +1,588 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/envelope"
+	"github.com/arham-abiyan/reconciliation/internal/masking"
+	"github.com/arham-abiyan/reconciliation/internal/report"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+	"github.com/arham-abiyan/reconciliation/internal/webhook"
+)
+
+// EnvConfigFile is the environment variable naming the config file when none is given explicitly
+const EnvConfigFile = "RECONCILE_CONFIG"
+
+// Config holds the settings shared by the CLI and the server.
+// Settings are resolved with the following precedence, highest first:
+// command-line flags, environment variables, the config file, then the defaults.
+type Config struct {
+	Server     Server        `json:"server"`
+	Auth       Auth          `json:"auth"`
+	Encryption Encryption    `json:"encryption"`
+	Masking    Masking       `json:"masking"`
+	Tenants    []Tenant      `json:"tenants,omitempty"`
+	Webhooks   Webhooks      `json:"webhooks"`
+	Matching   Matching      `json:"matching"`
+	Output     Output        `json:"output"`
+	Banks      []BankProfile `json:"banks,omitempty"`
+}
+
+// Server holds the settings of the HTTP server
+// GRPCAddr: Address of the gRPC server, which is only started when set
+// UploadsDir: Where uploaded files are stored, named after the SHA-256 of their content
+// RunsDir: Where reconciliation runs are stored
+// AuditLog: File of the append-only audit log
+// AuditKey: Secret keying the hash chain of the audit log with HMAC-SHA256, plain SHA-256 when empty
+// MaxConcurrent: Reconciliations running at once, further requests wait for a free slot
+// MaxStreamRecords: System and bank records a gRPC reconciliation may stream, bounding the memory
+// it holds before a slot is free
+// ReadTimeout/WriteTimeout/IdleTimeout: Timeouts of the connections, none when zero
+// ShutdownTimeout: How long running reconciliations are waited for when the server stops, must be positive
+// UploadRetention: Uploads not received again for longer are removed, kept forever when zero
+// UploadMaxTotalSize: Bytes of uploads kept per tenant, the oldest are removed beyond it, unbounded when zero
+// JanitorInterval: How often expired uploads are removed
+type Server struct {
+	Addr             string   `json:"addr"`
+	GRPCAddr         string   `json:"grpc_addr,omitempty"`
+	UploadsDir       string   `json:"uploads_dir"`
+	RunsDir          string   `json:"runs_dir"`
+	AuditLog         string   `json:"audit_log"`
+	AuditKey         string   `json:"audit_key,omitempty"`
+	MaxUploadSize    int64    `json:"max_upload_size"`
+	MaxConcurrent    int      `json:"max_concurrent"`
+	MaxStreamRecords int      `json:"max_stream_records"`
+	ReadTimeout      Duration `json:"read_timeout"`
+	WriteTimeout     Duration `json:"write_timeout"`
+	IdleTimeout      Duration `json:"idle_timeout"`
+	ShutdownTimeout  Duration `json:"shutdown_timeout"`
+
+	UploadRetention    Duration `json:"upload_retention"`
+	UploadMaxTotalSize int64    `json:"upload_max_total_size"`
+	JanitorInterval    Duration `json:"janitor_interval"`
+}
+
+// Tenant is a business unit sharing the deployment, its runs and uploads are kept apart from the others
+// Banks: Banks the tenant may reconcile, any bank when empty
+type Tenant struct {
+	Name  string   `json:"name"`
+	Banks []string `json:"banks,omitempty"`
+}
+
+// tenantNamePattern keeps tenant names usable as directory names
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidTenantName reports whether name can identify a tenant
+func ValidTenantName(name string) bool {
+	return tenantNamePattern.MatchString(name)
+}
+
+// Tenant returns the settings of the named tenant
+func (c Config) Tenant(name string) (Tenant, bool) {
+	for _, tenant := range c.Tenants {
+		if tenant.Name == name {
+			return tenant, true
+		}
+	}
+	return Tenant{}, false
+}
+
+// Auth holds the credentials accepted by the server, authentication is disabled when none is configured
+// APIKeys: Static keys, only their SHA-256 hash is kept
+type Auth struct {
+	APIKeys []auth.APIKey `json:"api_keys,omitempty"`
+	JWT     *JWT          `json:"jwt,omitempty"`
+}
+
+// JWT describes the bearer tokens accepted by the server
+// Algorithm: HS256 with Secret, or RS256 with the PEM public key at PublicKeyFile
+// Issuer/Audience: When set, tokens must come from Issuer and be meant for Audience
+// RolesClaim: Claim holding the roles of the caller, "roles" when empty
+// TenantClaim: Claim holding the tenant of the caller, "tenant" when empty
+type JWT struct {
+	Algorithm     string   `json:"algorithm"`
+	Secret        string   `json:"secret,omitempty"`
+	PublicKeyFile string   `json:"public_key_file,omitempty"`
+	Issuer        string   `json:"issuer,omitempty"`
+	Audience      string   `json:"audience,omitempty"`
+	RolesClaim    string   `json:"roles_claim,omitempty"`
+	TenantClaim   string   `json:"tenant_claim,omitempty"`
+	Leeway        Duration `json:"leeway"`
+}
+
+// Enabled reports whether the server requires callers to authenticate
+func (a Auth) Enabled() bool {
+	return len(a.APIKeys) > 0 || a.JWT != nil
+}
+
+// Authenticator builds the authenticator of the configured credentials, API keys first
+func (a Auth) Authenticator() (auth.Authenticator, error) {
+	var chain auth.Chain
+	if len(a.APIKeys) > 0 {
+		keys, err := auth.NewAPIKeys(a.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, keys)
+	}
+
+	if a.JWT != nil {
+		jwtConfig := auth.JWTConfig{
+			Algorithm:   a.JWT.Algorithm,
+			Secret:      []byte(a.JWT.Secret),
+			Issuer:      a.JWT.Issuer,
+			Audience:    a.JWT.Audience,
+			RolesClaim:  a.JWT.RolesClaim,
+			TenantClaim: a.JWT.TenantClaim,
+			Leeway:      a.JWT.Leeway.Duration,
+		}
+		if a.JWT.PublicKeyFile != "" {
+			key, err := os.ReadFile(a.JWT.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+			}
+			jwtConfig.PublicKey = key
+		}
+
+		jwt, err := auth.NewJWT(jwtConfig)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwt)
+	}
+
+	return chain, nil
+}
+
+// Encryption holds the keys encrypting uploads and stored runs at rest, nothing is encrypted without keys
+// Keys: The first key encrypts new files and every key decrypts, so a rotation adds the new key first
+// and keeps the previous ones until the files are rewrapped with the rekey command
+type Encryption struct {
+	Keys []EncryptionKey `json:"keys,omitempty"`
+}
+
+// EncryptionKey is read from File, holding 32 bytes raw or in hex or base64, or derived from Passphrase
+// ID: Recorded in the files the key encrypts, it must not change once files are encrypted
+// Salt: Required with Passphrase, at least 16 characters kept with the settings
+type EncryptionKey struct {
+	ID         string `json:"id"`
+	File       string `json:"file,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+	Salt       string `json:"salt,omitempty"`
+}
+
+// Enabled reports whether files are encrypted at rest
+func (e Encryption) Enabled() bool {
+	return len(e.Keys) > 0
+}
+
+// Keyring reads or derives the configured keys, it returns nil when encryption is disabled
+func (e Encryption) Keyring() (*envelope.Keyring, error) {
+	if !e.Enabled() {
+		return nil, nil
+	}
+
+	keys := make([]envelope.Key, 0, len(e.Keys))
+	for _, config := range e.Keys {
+		var key envelope.Key
+		var err error
+		if config.File != "" {
+			key, err = envelope.ReadKeyFile(config.ID, config.File)
+		} else {
+			key, err = envelope.DeriveKey(config.ID, config.Passphrase, []byte(config.Salt))
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return envelope.NewKeyring(keys...)
+}
+
+// Masking hides personal data of the records in API responses and reports, see masking.Rule
+// HashKey: Keys the hashes of hash rules, without it guessed values can be hashed and compared
+// ExemptRoles: Callers holding one of these roles see the records unmasked through the API
+type Masking struct {
+	Rules       []masking.Rule `json:"rules,omitempty"`
+	HashKey     string         `json:"hash_key,omitempty"`
+	ExemptRoles []string       `json:"exempt_roles,omitempty"`
+}
+
+// Masker returns the masker of the rules, nil when there are none
+func (m Masking) Masker() (*masking.Masker, error) {
+	if len(m.Rules) == 0 {
+		return nil, nil
+	}
+	return masking.New(m.Rules, []byte(m.HashKey))
+}
+
+// Webhooks notifies downstream systems of the stored runs, see webhook.Endpoint
+// DeliveryLog: File of the log of every delivery attempt
+// MaxAttempts: Attempts of a delivery before it is given up, the first one included
+// Backoff: Wait before the second attempt, doubled after each failed attempt
+// Timeout: How long an endpoint is waited for on each attempt
+type Webhooks struct {
+	Endpoints   []webhook.Endpoint `json:"endpoints,omitempty"`
+	DeliveryLog string             `json:"delivery_log"`
+	MaxAttempts int                `json:"max_attempts"`
+	Backoff     Duration           `json:"backoff"`
+	Timeout     Duration           `json:"timeout"`
+}
+
+// WebhookSecretEnv names the environment variable overriding the secret of the endpoint name:
+// RECONCILE_WEBHOOK_SECRET_ followed by the name in upper case, other characters than letters
+// and digits replaced by underscores
+func WebhookSecretEnv(name string) string {
+	return "RECONCILE_WEBHOOK_SECRET_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// Retry returns how failed deliveries are retried
+func (w Webhooks) Retry() webhook.Retry {
+	return webhook.Retry{Attempts: w.MaxAttempts, Backoff: w.Backoff.Duration, Timeout: w.Timeout.Duration}
+}
+
+// Duration is a time.Duration written as a string in the config file, e.g. "30s" or "2m"
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// Matching holds the tolerances of the reconciliation
+// MaxUnmatched/MaxDiscrepancy: Exceptions tolerated before the CLI reports a failed reconciliation
+type Matching struct {
+	TransferWindowDays int     `json:"transfer_window_days"`
+	MaxUnmatched       int     `json:"max_unmatched"`
+	MaxDiscrepancy     float64 `json:"max_discrepancy"`
+}
+
+// Output holds how results are rendered
+// Format: Report format of the CLI, text when empty
+// Matches: Matched pairs listed in results by the commands and APIs, all when empty
+// JSON: The CLI writes the result as JSON instead of a report
+type Output struct {
+	Format  string `json:"format,omitempty"`
+	Matches string `json:"matches,omitempty"`
+	JSON    bool   `json:"json,omitempty"`
+}
+
+// BankProfile holds the defaults of a bank, named after its statement files (e.g. bank-a)
+type BankProfile struct {
+	Name string                  `json:"name"`
+	Fee  *reconciliation.FeeRule `json:"fee,omitempty"`
+}
+
+// Default returns the settings used when nothing is configured
+func Default() Config {
+	return Config{
+		Server: Server{
+			Addr:             ":8080",
+			UploadsDir:       "./uploads",
+			RunsDir:          "./runs",
+			AuditLog:         "./audit/audit.log",
+			MaxUploadSize:    10 << 20, // 10 MB
+			MaxConcurrent:    4,
+			MaxStreamRecords: 1_000_000,
+			// Uploads of the maximum size must fit in the read timeout, and reconciling them in the write timeout
+			ReadTimeout:     Duration{30 * time.Second},
+			WriteTimeout:    Duration{2 * time.Minute},
+			IdleTimeout:     Duration{2 * time.Minute},
+			ShutdownTimeout: Duration{time.Minute},
+			UploadRetention: Duration{30 * 24 * time.Hour},
+			JanitorInterval: Duration{time.Hour},
+		},
+		Webhooks: Webhooks{
+			DeliveryLog: "./webhooks/deliveries.log",
+			MaxAttempts: 5,
+			Backoff:     Duration{5 * time.Second},
+			Timeout:     Duration{10 * time.Second},
+		},
+		Matching: Matching{
+			TransferWindowDays: 1,
+		},
+	}
+}
+
+// Load reads the config file at path over the defaults and applies the environment overrides.
+// When path is empty the file named by RECONCILE_CONFIG is read, if any.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// applyEnv overrides the settings with the RECONCILE_* environment variables found by lookup
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	texts := map[string]*string{
+		"RECONCILE_ADDR":                 &c.Server.Addr,
+		"RECONCILE_GRPC_ADDR":            &c.Server.GRPCAddr,
+		"RECONCILE_UPLOADS_DIR":          &c.Server.UploadsDir,
+		"RECONCILE_RUNS_DIR":             &c.Server.RunsDir,
+		"RECONCILE_AUDIT_LOG":            &c.Server.AuditLog,
+		"RECONCILE_AUDIT_KEY":            &c.Server.AuditKey,
+		"RECONCILE_WEBHOOK_DELIVERY_LOG": &c.Webhooks.DeliveryLog,
+		"RECONCILE_FORMAT":               &c.Output.Format,
+		"RECONCILE_MATCHES":              &c.Output.Matches,
+		// Keeps the hash key out of the config file
+		"RECONCILE_MASKING_HASH_KEY": &c.Masking.HashKey,
+	}
+	for name, field := range texts {
+		if value, ok := lookup(name); ok {
+			*field = value
+		}
+	}
+	// Keeps the shared secret out of the config file
+	if secret, ok := lookup("RECONCILE_JWT_SECRET"); ok {
+		if c.Auth.JWT == nil {
+			c.Auth.JWT = &JWT{Algorithm: auth.AlgorithmHS256}
+		}
+		c.Auth.JWT.Secret = secret
+	}
+	// Keeps the passphrase out of the config file, it belongs to the key encrypting new files
+	if passphrase, ok := lookup("RECONCILE_ENCRYPTION_PASSPHRASE"); ok {
+		if len(c.Encryption.Keys) == 0 {
+			c.Encryption.Keys = []EncryptionKey{{ID: "default"}}
+		}
+		c.Encryption.Keys[0].Passphrase = passphrase
+	}
+	// Keeps the signing secrets out of the config file, one variable per endpoint
+	for i, endpoint := range c.Webhooks.Endpoints {
+		if secret, ok := lookup(WebhookSecretEnv(endpoint.Name)); ok {
+			c.Webhooks.Endpoints[i].Secret = secret
+		}
+	}
+
+	values := []struct {
+		name  string
+		parse func(string) error
+	}{
+		{"RECONCILE_MAX_UPLOAD_SIZE", func(v string) (err error) {
+			c.Server.MaxUploadSize, err = strconv.ParseInt(v, 10, 64)
+			return err
+		}},
+		{"RECONCILE_MAX_CONCURRENT", func(v string) (err error) {
+			c.Server.MaxConcurrent, err = strconv.Atoi(v)
+			return err
+		}},
+		{"RECONCILE_MAX_STREAM_RECORDS", func(v string) (err error) {
+			c.Server.MaxStreamRecords, err = strconv.Atoi(v)
+			return err
+		}},
+		{"RECONCILE_READ_TIMEOUT", func(v string) (err error) {
+			c.Server.ReadTimeout.Duration, err = time.ParseDuration(v)
+			return err
+		}},
+		{"RECONCILE_WRITE_TIMEOUT", func(v string) (err error) {
+			c.Server.WriteTimeout.Duration, err = time.ParseDuration(v)
+			return err
+		}},
+		{"RECONCILE_IDLE_TIMEOUT", func(v string) (err error) {
+			c.Server.IdleTimeout.Duration, err = time.ParseDuration(v)
+			return err
+		}},
+		{"RECONCILE_SHUTDOWN_TIMEOUT", func(v string) (err error) {
+			c.Server.ShutdownTimeout.Duration, err = time.ParseDuration(v)
+			return err
+		}},
+		{"RECONCILE_UPLOAD_RETENTION", func(v string) (err error) {
+			c.Server.UploadRetention.Duration, err = time.ParseDuration(v)
+			return err
+		}},
+		{"RECONCILE_UPLOAD_MAX_TOTAL_SIZE", func(v string) (err error) {
+			c.Server.UploadMaxTotalSize, err = strconv.ParseInt(v, 10, 64)
+			return err
+		}},
+		{"RECONCILE_TRANSFER_WINDOW_DAYS", func(v string) (err error) {
+			c.Matching.TransferWindowDays, err = strconv.Atoi(v)
+			return err
+		}},
+		{"RECONCILE_MAX_UNMATCHED", func(v string) (err error) {
+			c.Matching.MaxUnmatched, err = strconv.Atoi(v)
+			return err
+		}},
+		{"RECONCILE_MAX_DISCREPANCY", func(v string) (err error) {
+			c.Matching.MaxDiscrepancy, err = strconv.ParseFloat(v, 64)
+			return err
+		}},
+	}
+	for _, v := range values {
+		if value, ok := lookup(v.name); ok {
+			if err := v.parse(value); err != nil {
+				return fmt.Errorf("invalid %s %q: %w", v.name, value, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the settings are usable
+func (c Config) Validate() error {
+	switch {
+	case c.Server.MaxUploadSize <= 0:
+		return fmt.Errorf("max upload size must be positive")
+	case c.Server.MaxConcurrent <= 0:
+		return fmt.Errorf("max concurrent reconciliations must be positive")
+	case c.Server.MaxStreamRecords <= 0:
+		return fmt.Errorf("max stream records must be positive")
+	case c.Server.ReadTimeout.Duration < 0 || c.Server.WriteTimeout.Duration < 0 || c.Server.IdleTimeout.Duration < 0:
+		return fmt.Errorf("server timeouts cannot be negative")
+	case c.Server.ShutdownTimeout.Duration <= 0:
+		return fmt.Errorf("shutdown timeout must be positive")
+	case c.Server.AuditKey != "" && len(c.Server.AuditKey) < 16:
+		return fmt.Errorf("audit key must be at least 16 characters")
+	case c.Server.UploadRetention.Duration < 0 || c.Server.UploadMaxTotalSize < 0:
+		return fmt.Errorf("upload retention and max total size cannot be negative")
+	case c.Server.JanitorInterval.Duration <= 0:
+		return fmt.Errorf("janitor interval must be positive")
+	case c.Matching.TransferWindowDays < 0:
+		return fmt.Errorf("transfer window days must be a non-negative number")
+	case c.Matching.MaxUnmatched < 0 || c.Matching.MaxDiscrepancy < 0:
+		return fmt.Errorf("max unmatched and max discrepancy must be non-negative")
+	case c.Webhooks.MaxAttempts <= 0 || c.Webhooks.Backoff.Duration <= 0 || c.Webhooks.Timeout.Duration <= 0:
+		return fmt.Errorf("webhook max attempts, backoff and timeout must be positive")
+	case c.Output.Format != "" && report.ContentType(c.Output.Format) == "":
+		return fmt.Errorf("unknown report format %q", c.Output.Format)
+	}
+
+	switch c.Output.Matches {
+	case "", reconciliation.MatchesAll, reconciliation.MatchesImperfect, reconciliation.MatchesNone:
+	default:
+		return fmt.Errorf("matches must be one of all, imperfect or none")
+	}
+
+	if c.Auth.Enabled() {
+		if _, err := c.Auth.Authenticator(); err != nil {
+			return fmt.Errorf("invalid auth settings: %w", err)
+		}
+	}
+
+	if _, err := c.Masking.Masker(); err != nil {
+		return fmt.Errorf("invalid masking settings: %w", err)
+	}
+
+	for i, key := range c.Encryption.Keys {
+		switch {
+		case key.ID == "":
+			return fmt.Errorf("encryption key id is required")
+		case (key.File == "") == (key.Passphrase == ""):
+			return fmt.Errorf("encryption key %s needs either a file or a passphrase", key.ID)
+		case key.Passphrase != "" && len(key.Salt) < 16:
+			return fmt.Errorf("encryption key %s needs a salt of at least 16 characters", key.ID)
+		}
+		for _, other := range c.Encryption.Keys[i+1:] {
+			if other.ID == key.ID {
+				return fmt.Errorf("encryption key %s is configured twice", key.ID)
+			}
+		}
+	}
+
+	for i, tenant := range c.Tenants {
+		if !ValidTenantName(tenant.Name) {
+			return fmt.Errorf("invalid tenant name %q: use lowercase letters, digits, - and _", tenant.Name)
+		}
+		for _, other := range c.Tenants[i+1:] {
+			if other.Name == tenant.Name {
+				return fmt.Errorf("tenant %s is configured twice", tenant.Name)
+			}
+		}
+	}
+	for _, key := range c.Auth.APIKeys {
+		if key.Tenant == "" && len(c.Tenants) > 0 {
+			return fmt.Errorf("api key %s needs a tenant once tenants are configured", key.Name)
+		}
+		if _, found := c.Tenant(key.Tenant); key.Tenant != "" && !found {
+			return fmt.Errorf("api key %s belongs to unknown tenant %s", key.Name, key.Tenant)
+		}
+	}
+
+	for i, endpoint := range c.Webhooks.Endpoints {
+		if err := endpoint.Validate(); err != nil {
+			return err
+		}
+		if _, found := c.Tenant(endpoint.Tenant); endpoint.Tenant != "" && !found {
+			return fmt.Errorf("webhook %s belongs to unknown tenant %s", endpoint.Name, endpoint.Tenant)
+		}
+		for _, other := range c.Webhooks.Endpoints[i+1:] {
+			if other.Name == endpoint.Name {
+				return fmt.Errorf("webhook %s is configured twice", endpoint.Name)
+			}
+		}
+	}
+
+	for _, rule := range c.FeeRules() {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, bank := range c.Banks {
+		if strings.TrimSpace(bank.Name) == "" {
+			return fmt.Errorf("bank profile name is required")
+		}
+	}
+
+	return nil
+}
+
+// FeeRules returns the fee rules of the bank profiles, keyed by the profile name
+func (c Config) FeeRules() []reconciliation.FeeRule {
+	rules := make([]reconciliation.FeeRule, 0, len(c.Banks))
+	for _, bank := range c.Banks {
+		if bank.Fee == nil {
+			continue
+		}
+		rule := *bank.Fee
+		rule.Bank = bank.Name
+		rules = append(rules, rule)
+	}
+	return rules
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+	"github.com/arham-abiyan/reconciliation/internal/webhook"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		want    func(*Config)
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: func(*Config) {},
+		},
+		{
+			name: "file over defaults",
+			file: `{"server": {"addr": ":9090"}, "matching": {"max_unmatched": 3}, "output": {"matches": "none"}}`,
+			want: func(c *Config) {
+				c.Server.Addr = ":9090"
+				c.Matching.MaxUnmatched = 3
+				c.Output.Matches = reconciliation.MatchesNone
+			},
+		},
+		{
+			name: "environment over file",
+			file: `{"server": {"addr": ":9090", "runs_dir": "/var/runs"}}`,
+			env:  map[string]string{"RECONCILE_ADDR": ":7070", "RECONCILE_GRPC_ADDR": ":7071", "RECONCILE_MAX_DISCREPANCY": "2500.5", "RECONCILE_SHUTDOWN_TIMEOUT": "5s"},
+			want: func(c *Config) {
+				c.Server.Addr = ":7070"
+				c.Server.GRPCAddr = ":7071"
+				c.Server.ShutdownTimeout = Duration{5 * time.Second}
+				c.Server.RunsDir = "/var/runs"
+				c.Matching.MaxDiscrepancy = 2500.5
+			},
+		},
+		{
+			name: "server timeouts",
+			file: `{"server": {"read_timeout": "1m", "idle_timeout": "90s", "max_concurrent": 2}}`,
+			want: func(c *Config) {
+				c.Server.ReadTimeout = Duration{time.Minute}
+				c.Server.IdleTimeout = Duration{90 * time.Second}
+				c.Server.MaxConcurrent = 2
+			},
+		},
+		{
+			name: "server timeouts from environment",
+			env:  map[string]string{"RECONCILE_READ_TIMEOUT": "45s", "RECONCILE_WRITE_TIMEOUT": "5m", "RECONCILE_IDLE_TIMEOUT": "0s"},
+			want: func(c *Config) {
+				c.Server.ReadTimeout = Duration{45 * time.Second}
+				c.Server.WriteTimeout = Duration{5 * time.Minute}
+				c.Server.IdleTimeout = Duration{}
+			},
+		},
+		{
+			name:    "zero shutdown timeout",
+			file:    `{"server": {"shutdown_timeout": "0s"}}`,
+			wantErr: true,
+		},
+		{
+			name: "upload retention",
+			file: `{"server": {"upload_retention": "168h", "upload_max_total_size": 1073741824}}`,
+			env:  map[string]string{"RECONCILE_UPLOAD_RETENTION": "72h"},
+			want: func(c *Config) {
+				c.Server.UploadRetention = Duration{72 * time.Hour}
+				c.Server.UploadMaxTotalSize = 1 << 30
+			},
+		},
+		{
+			name:    "negative upload retention",
+			file:    `{"server": {"upload_retention": "-1h"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			file:    `{"server": {"write_timeout": 30}}`,
+			wantErr: true,
+		},
+		{
+			name: "bank profiles",
+			file: `{"banks": [{"name": "bank-a", "fee": {"type": "flat", "flat": 2500}}, {"name": "bank-b"}]}`,
+			want: func(c *Config) {
+				c.Banks = []BankProfile{
+					{Name: "bank-a", Fee: &reconciliation.FeeRule{Type: reconciliation.FeeFlat, Flat: 2500}},
+					{Name: "bank-b"},
+				}
+			},
+		},
+		{
+			name: "encryption passphrase from environment",
+			file: `{"encryption": {"keys": [{"id": "2025", "salt": "4f1c9a0e7b2d6e83"}]}}`,
+			env:  map[string]string{"RECONCILE_ENCRYPTION_PASSPHRASE": "correct horse battery staple"},
+			want: func(c *Config) {
+				c.Encryption.Keys = []EncryptionKey{{ID: "2025", Passphrase: "correct horse battery staple", Salt: "4f1c9a0e7b2d6e83"}}
+			},
+		},
+		{
+			name:    "encryption key without secret",
+			file:    `{"encryption": {"keys": [{"id": "2025"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "encryption passphrase without salt",
+			env:     map[string]string{"RECONCILE_ENCRYPTION_PASSPHRASE": "correct horse battery staple"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid masking rule",
+			file:    `{"masking": {"rules": [{"field": "amount", "action": "redact"}]}}`,
+			wantErr: true,
+		},
+		{
+			name: "webhooks",
+			file: `{"webhooks": {"endpoints": [{"name": "erp", "url": "https://erp.example.com/hooks", "secret": "0123456789abcdef", "events": ["run.completed"]}], "backoff": "30s"}}`,
+			env:  map[string]string{"RECONCILE_WEBHOOK_DELIVERY_LOG": "/var/log/webhooks.log"},
+			want: func(c *Config) {
+				c.Webhooks.Endpoints = []webhook.Endpoint{{Name: "erp", URL: "https://erp.example.com/hooks", Secret: "0123456789abcdef", Events: []string{webhook.EventRunCompleted}}}
+				c.Webhooks.Backoff = Duration{30 * time.Second}
+				c.Webhooks.DeliveryLog = "/var/log/webhooks.log"
+			},
+		},
+		{
+			name: "webhook secret from environment",
+			file: `{"webhooks": {"endpoints": [{"name": "erp-eu", "url": "https://erp.example.com/hooks"}]}}`,
+			env:  map[string]string{"RECONCILE_WEBHOOK_SECRET_ERP_EU": "fedcba9876543210"},
+			want: func(c *Config) {
+				c.Webhooks.Endpoints = []webhook.Endpoint{{Name: "erp-eu", URL: "https://erp.example.com/hooks", Secret: "fedcba9876543210"}}
+			},
+		},
+		{
+			name:    "webhook of unknown tenant",
+			file:    `{"webhooks": {"endpoints": [{"name": "erp", "url": "https://erp.example.com/hooks", "secret": "0123456789abcdef", "tenant": "emea"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "webhook without attempts",
+			file:    `{"webhooks": {"max_attempts": 0}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid number in environment",
+			env:     map[string]string{"RECONCILE_MAX_UPLOAD_SIZE": "10MB"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid fee rule",
+			file:    `{"banks": [{"name": "bank-a", "fee": {"type": "weekly"}}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid matches",
+			file:    `{"output": {"matches": "some"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed file",
+			file:    `{"server": `,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvConfigFile, "")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			path := ""
+			if tt.file != "" {
+				path = writeConfig(t, tt.file)
+			}
+
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			want := Default()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadFromEnvironment(t *testing.T) {
+	t.Setenv(EnvConfigFile, writeConfig(t, `{"server": {"uploads_dir": "/srv/uploads"}}`))
+
+	got, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Server.UploadsDir != "/srv/uploads" {
+		t.Errorf("UploadsDir = %q, want /srv/uploads", got.Server.UploadsDir)
+	}
+}
+
+func TestFeeRules(t *testing.T) {
+	cfg := Config{Banks: []BankProfile{
+		{Name: "bank-a", Fee: &reconciliation.FeeRule{Type: reconciliation.FeeFlat, Flat: 2500}},
+		{Name: "bank-b"},
+	}}
+
+	want := []reconciliation.FeeRule{{Bank: "bank-a", Type: reconciliation.FeeFlat, Flat: 2500}}
+	if got := cfg.FeeRules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FeeRules() = %+v, want %+v", got, want)
+	}
+}
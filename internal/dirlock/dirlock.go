@@ -0,0 +1,52 @@
+// Package dirlock keeps the server and the maintenance commands from working on the same storage
+// directories at once, through an advisory lock on a file of each directory
+package dirlock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// lockFile is the file of a directory holding its lock, hidden so the stores and rekey skip it
+const lockFile = ".lock"
+
+// ErrLocked is returned when the lock is held by another process in a conflicting mode
+var ErrLocked = errors.New("directory is locked by another process")
+
+// Lock is a lock held on a directory until Release
+type Lock struct {
+	file *os.File
+}
+
+// Shared locks dir for a process using it along with others, e.g. a server. It fails with
+// ErrLocked while an exclusive lock is held.
+func Shared(dir string) (*Lock, error) {
+	return acquire(dir, false)
+}
+
+// Exclusive locks dir for a process rewriting its files, e.g. rekey. It fails with ErrLocked
+// while any other lock is held.
+func Exclusive(dir string) (*Lock, error) {
+	return acquire(dir, true)
+}
+
+func acquire(dir string, exclusive bool) (*Lock, error) {
+	file, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(file, exclusive); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release releases the lock, a nil lock is released already
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package dirlock
+
+import "os"
+
+// lock takes no lock where flock is not available, the directories are not guarded there
+func lock(file *os.File, exclusive bool) error {
+	return nil
+}
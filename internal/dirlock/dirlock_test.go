@@ -0,0 +1,35 @@
+//go:build unix
+
+package dirlock
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := Shared(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Shared(dir)
+	if err != nil {
+		t.Fatalf("Shared() while shared = %v, want the lock shared", err)
+	}
+	if _, err := Exclusive(dir); !errors.Is(err, ErrLocked) {
+		t.Errorf("Exclusive() while shared = %v, want ErrLocked", err)
+	}
+
+	first.Release()
+	second.Release()
+	exclusive, err := Exclusive(dir)
+	if err != nil {
+		t.Fatalf("Exclusive() once released = %v", err)
+	}
+	if _, err := Shared(dir); !errors.Is(err, ErrLocked) {
+		t.Errorf("Shared() while exclusive = %v, want ErrLocked", err)
+	}
+	exclusive.Release()
+}
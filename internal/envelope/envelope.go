@@ -0,0 +1,287 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// magic starts every sealed file, files without it are read as plain text
+const magic = "RECENV\x01"
+
+const (
+	keySize   = 32 // AES-256
+	nonceSize = 12
+	// wrappedKeySize is the size of a data key sealed by a key encryption key, with its nonce and tag
+	wrappedKeySize = nonceSize + keySize + 16
+)
+
+var (
+	// ErrNoKeys is returned when reading a sealed file without any key configured
+	ErrNoKeys = errors.New("file is encrypted but no encryption key is configured")
+	// ErrUnknownKey is returned when a file was sealed with a key that is not configured
+	ErrUnknownKey = errors.New("file is encrypted with an unknown key")
+)
+
+// Keyring holds the key encryption keys. Every file is sealed with its own random data key,
+// stored in the file wrapped by the primary key, the first of the keyring. Every key of the
+// keyring unwraps data keys, so files sealed before a rotation remain readable.
+// A nil keyring seals nothing and only reads plain files.
+type Keyring struct {
+	keys []Key
+}
+
+// NewKeyring returns a keyring sealing with the first key, key IDs must be unique
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one encryption key is required")
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate encryption key %q", key.ID)
+		}
+		seen[key.ID] = true
+	}
+
+	return &Keyring{keys: keys}, nil
+}
+
+// Primary returns the ID of the key sealing new files
+func (k *Keyring) Primary() string {
+	if k == nil {
+		return ""
+	}
+	return k.keys[0].ID
+}
+
+// IsSealed reports whether data was sealed by a keyring
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(magic))
+}
+
+// IsSealedFile reports whether the file at path was sealed by a keyring, reading its start only
+func IsSealedFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	start := make([]byte, len(magic))
+	n, err := io.ReadFull(file, start)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return IsSealed(start[:n]), nil
+}
+
+// Seal encrypts plaintext with a new data key wrapped by the primary key.
+// The result is laid out as: magic, key ID length, key ID, wrapped data key, nonce, ciphertext.
+func (k *Keyring) Seal(plaintext []byte) ([]byte, error) {
+	if k == nil {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	header, err := k.header(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append(header, nonce...)
+	return aead.Seal(sealed, nonce, plaintext, []byte(magic)), nil
+}
+
+// Open decrypts data sealed by Seal with any key of the keyring, plain data is returned as it is
+func (k *Keyring) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+
+	dataKey, body, err := k.unwrap(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < nonceSize {
+		return nil, errors.New("encrypted file is truncated")
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, body[:nonceSize], body[nonceSize:], []byte(magic))
+	if err != nil {
+		return nil, errors.New("encrypted file is corrupted")
+	}
+
+	return plaintext, nil
+}
+
+// Rewrap wraps the data key of sealed data with the primary key, leaving the content encrypted
+// as it is, and seals plain data. It returns false when data is already sealed with the primary key.
+func (k *Keyring) Rewrap(data []byte) ([]byte, bool, error) {
+	if k == nil {
+		return data, false, ErrNoKeys
+	}
+	if !IsSealed(data) {
+		sealed, err := k.Seal(data)
+		return sealed, err == nil, err
+	}
+
+	if id, _, err := parseHeader(data); err != nil {
+		return nil, false, err
+	} else if id == k.Primary() {
+		return data, false, nil
+	}
+
+	dataKey, body, err := k.unwrap(data)
+	if err != nil {
+		return nil, false, err
+	}
+	header, err := k.header(dataKey)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return append(header, body...), true, nil
+}
+
+// ReadFile reads the file at path, decrypting it when sealed
+func (k *Keyring) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return k.Open(data)
+}
+
+// RewrapFile rewraps the file at path in place, see Rewrap. It returns whether the file changed.
+func (k *Keyring) RewrapFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	rewrapped, changed, err := k.Rewrap(data)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	// Write then rename so a reader never sees a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".rewrap-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(rewrapped); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return false, err
+	}
+	// Keep the modification time, the uploads janitor relies on it
+	return true, os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// header returns the start of a sealed file: magic, primary key ID and dataKey wrapped by it
+func (k *Keyring) header(dataKey []byte) ([]byte, error) {
+	primary := k.keys[0]
+
+	aead, err := newGCM(primary.secret)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(magic), byte(len(primary.ID)))
+	header = append(header, primary.ID...)
+	header = append(header, nonce...)
+	// The key ID is authenticated, a wrapped key cannot be relabelled
+	return aead.Seal(header, nonce, dataKey, []byte(primary.ID)), nil
+}
+
+// unwrap returns the data key of sealed data and the encrypted content following the header
+func (k *Keyring) unwrap(data []byte) ([]byte, []byte, error) {
+	if k == nil {
+		return nil, nil, ErrNoKeys
+	}
+
+	id, rest, err := parseHeader(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var key *Key
+	for i := range k.keys {
+		if k.keys[i].ID == id {
+			key = &k.keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, nil, fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+
+	aead, err := newGCM(key.secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped := rest[:wrappedKeySize]
+	dataKey, err := aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], []byte(id))
+	if err != nil {
+		return nil, nil, fmt.Errorf("encryption key %q does not decrypt the file", id)
+	}
+
+	return dataKey, rest[wrappedKeySize:], nil
+}
+
+// parseHeader returns the key ID of sealed data and what follows it, starting with the wrapped data key
+func parseHeader(data []byte) (string, []byte, error) {
+	rest := data[len(magic):]
+	if len(rest) < 1 || len(rest) < 1+int(rest[0])+wrappedKeySize {
+		return "", nil, errors.New("encrypted file is truncated")
+	}
+	idLen := int(rest[0])
+	return string(rest[1 : 1+idLen]), rest[1+idLen:], nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,140 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(t *testing.T, id string, fill byte) Key {
+	t.Helper()
+	key, err := NewKey(id, bytes.Repeat([]byte{fill}, keySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestSealOpen(t *testing.T) {
+	keys, err := NewKeyring(testKey(t, "2025", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte("unique_identifier,amount,date\nB1,100,2024-12-02\n")
+
+	sealed, err := keys.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, plaintext[:20]) {
+		t.Fatal("Seal() left the content readable")
+	}
+	if got, err := keys.Open(sealed); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Open() = %q, %v", got, err)
+	}
+
+	// Plain files are read as they are, a nil keyring only reads them
+	if got, err := keys.Open(plaintext); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Open() of plain data = %q, %v", got, err)
+	}
+	var none *Keyring
+	if _, err := none.Open(sealed); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Open() without keys error = %v, want ErrNoKeys", err)
+	}
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := keys.Open(tampered); err == nil {
+		t.Error("Open() accepted tampered data")
+	}
+
+	other, _ := NewKeyring(testKey(t, "other", 2))
+	if _, err := other.Open(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Open() with another key error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey, newKey := testKey(t, "2024", 1), testKey(t, "2025", 2)
+	before, _ := NewKeyring(oldKey)
+	plaintext := []byte("trxID,amount,type,transactionTime\n")
+	sealed, err := before.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The new key seals, the old one still opens files sealed before the rotation
+	after, _ := NewKeyring(newKey, oldKey)
+	if got, err := after.Open(sealed); err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Open() after rotation = %q, %v", got, err)
+	}
+
+	rewrapped, changed, err := after.Rewrap(sealed)
+	if err != nil || !changed {
+		t.Fatalf("Rewrap() = %v, %v", changed, err)
+	}
+	newOnly, _ := NewKeyring(newKey)
+	if got, err := newOnly.Open(rewrapped); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Open() with the new key only = %q, %v", got, err)
+	}
+	if _, changed, _ := after.Rewrap(rewrapped); changed {
+		t.Error("Rewrap() changed data already sealed with the primary key")
+	}
+
+	path := filepath.Join(t.TempDir(), "run.json")
+	if err := os.WriteFile(path, plaintext, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := after.RewrapFile(path); err != nil || !changed {
+		t.Fatalf("RewrapFile() of a plain file = %v, %v", changed, err)
+	}
+	if got, err := newOnly.ReadFile(path); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("ReadFile() = %q, %v", got, err)
+	}
+}
+
+func TestReadKeyFile(t *testing.T) {
+	secret := bytes.Repeat([]byte{7}, keySize)
+	dir := t.TempDir()
+
+	for name, content := range map[string][]byte{
+		"raw": secret,
+		"hex": []byte(hex.EncodeToString(secret) + "\n"),
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, content, 0o600)
+		key, err := ReadKeyFile(name, path)
+		if err != nil || !bytes.Equal(key.secret, secret) {
+			t.Errorf("ReadKeyFile(%s) = %x, %v", name, key.secret, err)
+		}
+	}
+
+	short := filepath.Join(dir, "short")
+	os.WriteFile(short, []byte("too short"), 0o600)
+	if _, err := ReadKeyFile("short", short); err == nil {
+		t.Error("ReadKeyFile() accepted a short key")
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	// Test vectors of PBKDF2-HMAC-SHA256
+	tests := []struct {
+		iterations int
+		want       string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(pbkdf2([]byte("password"), []byte("salt"), tt.iterations, 32)); got != tt.want {
+			t.Errorf("pbkdf2(%d iterations) = %s, want %s", tt.iterations, got, tt.want)
+		}
+	}
+
+	if _, err := DeriveKey("p", "passphrase", []byte("short")); err == nil {
+		t.Error("DeriveKey() accepted a short salt")
+	}
+}
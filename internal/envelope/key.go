@@ -0,0 +1,92 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// pbkdf2Iterations is the PBKDF2-HMAC-SHA256 work factor of passphrase-derived keys
+const pbkdf2Iterations = 600_000
+
+// Key is a key encryption key, wrapping the data keys of sealed files
+// ID: Recorded in every file the key seals, so the file can be decrypted after a rotation
+type Key struct {
+	ID     string
+	secret []byte
+}
+
+// NewKey returns a key of 32 secret bytes
+func NewKey(id string, secret []byte) (Key, error) {
+	switch {
+	case id == "" || len(id) > 255:
+		return Key{}, errors.New("encryption key ID must be 1 to 255 bytes long")
+	case len(secret) != keySize:
+		return Key{}, fmt.Errorf("encryption key %q must be %d bytes long", id, keySize)
+	}
+	return Key{ID: id, secret: bytes.Clone(secret)}, nil
+}
+
+// ReadKeyFile reads a key from a file holding 32 bytes, raw or encoded in hex or base64
+func ReadKeyFile(id, path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to read encryption key %q: %w", id, err)
+	}
+
+	secret := data
+	if len(data) != keySize {
+		text := string(bytes.TrimSpace(data))
+		if decoded, err := hex.DecodeString(text); err == nil {
+			secret = decoded
+		} else if decoded, err := base64.StdEncoding.DecodeString(text); err == nil {
+			secret = decoded
+		}
+	}
+
+	return NewKey(id, secret)
+}
+
+// DeriveKey derives a key from a passphrase with PBKDF2-HMAC-SHA256. The salt must be kept
+// with the settings, the same passphrase and salt always derive the same key.
+func DeriveKey(id, passphrase string, salt []byte) (Key, error) {
+	switch {
+	case passphrase == "":
+		return Key{}, fmt.Errorf("passphrase of encryption key %q is empty", id)
+	case len(salt) < 16:
+		return Key{}, fmt.Errorf("salt of encryption key %q must be at least 16 bytes long", id)
+	}
+	return NewKey(id, pbkdf2([]byte(passphrase), salt, pbkdf2Iterations, keySize))
+}
+
+// pbkdf2 implements PBKDF2 (RFC 8018) with HMAC-SHA256
+func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	derived := make([]byte, 0, keyLen+prf.Size())
+
+	for block := uint32(1); len(derived) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := bytes.Clone(u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		derived = append(derived, t...)
+	}
+
+	return derived[:keyLen]
+}
@@ -0,0 +1,239 @@
+package masking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+)
+
+// Fields of the records a rule applies to
+// FieldIdentifier: Transaction IDs and bank statement identifiers, and the keys of exact duplicates
+// FieldDescription: Descriptions of the records, and the fingerprints of likely duplicates
+const (
+	FieldIdentifier  = "identifier"
+	FieldDescription = "description"
+)
+
+// Actions of a rule
+// ActionPartial: Keeps the last Keep characters, 4 by default, and masks the others with '*'
+// ActionHash: Replaces the value with a short keyed hash, equal values keep equal hashes
+// ActionRedact: Replaces the value with Redacted
+const (
+	ActionPartial = "partial"
+	ActionHash    = "hash"
+	ActionRedact  = "redact"
+)
+
+// Redacted replaces the values of redact rules
+const Redacted = "[redacted]"
+
+const (
+	defaultKeep = 4
+	// hashLength is the number of hex characters kept of a hash, enough to tell values apart
+	hashLength = 12
+)
+
+// Rule masks a field of the records
+// Pattern: When set, only the parts of the field matching the regular expression are masked,
+// e.g. account numbers within descriptions
+type Rule struct {
+	Field   string `json:"field"`
+	Pattern string `json:"pattern,omitempty"`
+	Action  string `json:"action"`
+	Keep    int    `json:"keep,omitempty"`
+}
+
+// rule is a Rule ready to be applied
+type rule struct {
+	Rule
+	pattern *regexp.Regexp
+}
+
+// Masker applies masking rules to reconciliation results. A nil Masker leaves them as they are.
+type Masker struct {
+	identifier  []rule
+	description []rule
+	hashKey     []byte
+}
+
+// New returns a masker applying rules in order. Hashes are keyed with hashKey, so they cannot
+// be reversed by hashing guessed values without it.
+func New(rules []Rule, hashKey []byte) (*Masker, error) {
+	m := &Masker{hashKey: hashKey}
+	for _, config := range rules {
+		r := rule{Rule: config}
+		switch r.Action {
+		case ActionPartial, ActionHash, ActionRedact:
+		default:
+			return nil, fmt.Errorf("unknown masking action %q, expected partial, hash or redact", r.Action)
+		}
+		if r.Keep < 0 {
+			return nil, fmt.Errorf("masking rule of %s cannot keep a negative number of characters", r.Field)
+		}
+		if r.Pattern != "" {
+			pattern, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid masking pattern %q: %w", r.Pattern, err)
+			}
+			r.pattern = pattern
+		}
+
+		switch r.Field {
+		case FieldIdentifier:
+			m.identifier = append(m.identifier, r)
+		case FieldDescription:
+			m.description = append(m.description, r)
+		default:
+			return nil, fmt.Errorf("unknown masking field %q, expected identifier or description", r.Field)
+		}
+	}
+
+	return m, nil
+}
+
+// Result returns a copy of result with the records masked, result itself is left unchanged
+func (m *Masker) Result(result model.ReconcileResponse) model.ReconcileResponse {
+	if m == nil {
+		return result
+	}
+
+	result.UnmatchedSystem = mapSlice(result.UnmatchedSystem, m.transaction)
+	if result.UnmatchedByBank != nil {
+		byBank := make(map[string][]model.BankStatement, len(result.UnmatchedByBank))
+		for bank, statements := range result.UnmatchedByBank {
+			byBank[bank] = mapSlice(statements, m.statement)
+		}
+		result.UnmatchedByBank = byBank
+	}
+	result.Matches = mapSlice(result.Matches, m.pair)
+	result.DiscrepantMatches = mapSlice(result.DiscrepantMatches, m.pair)
+	result.Duplicates = mapSlice(result.Duplicates, func(duplicate model.Duplicate) model.Duplicate {
+		// Exact duplicates share an identifier, likely ones a fingerprint ending with the description
+		if duplicate.Reason == reconciliation.DuplicateExactKey {
+			duplicate.Key = m.apply(m.identifier, duplicate.Key)
+		} else {
+			duplicate.Key = m.apply(m.description, duplicate.Key)
+		}
+		duplicate.Transactions = mapSlice(duplicate.Transactions, m.transaction)
+		duplicate.Statements = mapSlice(duplicate.Statements, m.statement)
+		return duplicate
+	})
+	result.InternalTransfers = mapSlice(result.InternalTransfers, func(transfer model.InternalTransfer) model.InternalTransfer {
+		transfer.From = m.statement(transfer.From)
+		transfer.To = m.statement(transfer.To)
+		return transfer
+	})
+	result.Reversals = mapSlice(result.Reversals, func(reversal model.Reversal) model.Reversal {
+		reversal.Transactions = mapSlice(reversal.Transactions, m.transaction)
+		reversal.Statements = mapSlice(reversal.Statements, m.statement)
+		return reversal
+	})
+
+	return result
+}
+
+// Exceptions returns a copy of exceptions with their records masked
+func (m *Masker) Exceptions(exceptions []model.Exception) []model.Exception {
+	if m == nil {
+		return exceptions
+	}
+	return mapSlice(exceptions, func(exception model.Exception) model.Exception {
+		exception.Identifier = m.apply(m.identifier, exception.Identifier)
+		exception.Description = m.apply(m.description, exception.Description)
+		if exception.Match != nil {
+			pair := m.pair(*exception.Match)
+			exception.Match = &pair
+		}
+		return exception
+	})
+}
+
+// Matches returns a copy of matches with their records masked
+func (m *Masker) Matches(matches []model.Match) []model.Match {
+	if m == nil {
+		return matches
+	}
+	return mapSlice(matches, func(match model.Match) model.Match {
+		match.MatchedPair = m.pair(match.MatchedPair)
+		return match
+	})
+}
+
+// Run returns a copy of run with the records of its result masked
+func (m *Masker) Run(run model.Run) model.Run {
+	run.Result = m.Result(run.Result)
+	return run
+}
+
+func (m *Masker) pair(pair model.MatchedPair) model.MatchedPair {
+	pair.System = m.transaction(pair.System)
+	pair.Statement = m.statement(pair.Statement)
+	return pair
+}
+
+func (m *Masker) transaction(tx model.Transaction) model.Transaction {
+	tx.TrxID = m.apply(m.identifier, tx.TrxID)
+	tx.Description = m.apply(m.description, tx.Description)
+	return tx
+}
+
+func (m *Masker) statement(stmt model.BankStatement) model.BankStatement {
+	stmt.UniqueIdentifier = m.apply(m.identifier, stmt.UniqueIdentifier)
+	stmt.Description = m.apply(m.description, stmt.Description)
+	return stmt
+}
+
+// apply masks value with rules in order, empty values stay empty
+func (m *Masker) apply(rules []rule, value string) string {
+	for _, r := range rules {
+		if value == "" {
+			return value
+		}
+		if r.pattern != nil {
+			value = r.pattern.ReplaceAllStringFunc(value, func(match string) string { return m.mask(r, match) })
+		} else {
+			value = m.mask(r, value)
+		}
+	}
+	return value
+}
+
+func (m *Masker) mask(r rule, value string) string {
+	switch r.Action {
+	case ActionHash:
+		mac := hmac.New(sha256.New, m.hashKey)
+		mac.Write([]byte(value))
+		return "#" + hex.EncodeToString(mac.Sum(nil))[:hashLength]
+	case ActionRedact:
+		return Redacted
+	}
+
+	keep := r.Keep
+	if keep == 0 {
+		keep = defaultKeep
+	}
+	runes := []rune(value)
+	// Values not longer than what is kept are masked entirely, showing them would reveal them whole
+	if len(runes) <= keep {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-keep) + string(runes[len(runes)-keep:])
+}
+
+// mapSlice returns a new slice holding f applied to every value, nil for a nil slice
+func mapSlice[T any](values []T, f func(T) T) []T {
+	if values == nil {
+		return nil
+	}
+	mapped := make([]T, len(values))
+	for i, value := range values {
+		mapped[i] = f(value)
+	}
+	return mapped
+}
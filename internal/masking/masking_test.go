@@ -0,0 +1,125 @@
+package masking
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		value string
+		want  string
+	}{
+		{"partial keeps the last 4", Rule{Field: FieldIdentifier, Action: ActionPartial}, "1234567890", "******7890"},
+		{"partial keeps the last n", Rule{Field: FieldIdentifier, Action: ActionPartial, Keep: 2}, "TRX-001", "*****01"},
+		{"partial masks short values entirely", Rule{Field: FieldIdentifier, Action: ActionPartial}, "T1", "**"},
+		{"redact", Rule{Field: FieldDescription, Action: ActionRedact}, "Payment to John", Redacted},
+		{
+			"pattern masks the matches only",
+			Rule{Field: FieldDescription, Pattern: `\d{8,}`, Action: ActionPartial},
+			"Transfer to acct 1234567890 ref 42",
+			"Transfer to acct ******7890 ref 42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New([]Rule{tt.rule}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rules := m.identifier
+			if tt.rule.Field == FieldDescription {
+				rules = m.description
+			}
+			if got := m.apply(rules, tt.value); got != tt.want {
+				t.Errorf("apply(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	rules := []Rule{{Field: FieldDescription, Action: ActionHash}}
+	m, _ := New(rules, []byte("key"))
+	other, _ := New(rules, []byte("other key"))
+
+	first := m.apply(m.description, "ACME Corp")
+	if !strings.HasPrefix(first, "#") || len(first) != 1+hashLength {
+		t.Fatalf("hash = %q", first)
+	}
+	if m.apply(m.description, "ACME Corp") != first {
+		t.Error("equal values must keep equal hashes")
+	}
+	if m.apply(m.description, "Globex") == first {
+		t.Error("different values share a hash")
+	}
+	if other.apply(other.description, "ACME Corp") == first {
+		t.Error("hashes do not depend on the key")
+	}
+}
+
+func TestNewInvalidRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{Field: "amount", Action: ActionRedact},
+		{Field: FieldIdentifier, Action: "shuffle"},
+		{Field: FieldDescription, Action: ActionPartial, Pattern: "("},
+		{Field: FieldIdentifier, Action: ActionPartial, Keep: -1},
+	} {
+		if _, err := New([]Rule{rule}, nil); err == nil {
+			t.Errorf("New(%+v) accepted an invalid rule", rule)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	m, err := New([]Rule{
+		{Field: FieldIdentifier, Action: ActionPartial},
+		{Field: FieldDescription, Action: ActionRedact},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := model.Transaction{TrxID: "TRX-000123", Description: "Invoice John Doe", Amount: 100}
+	stmt := model.BankStatement{UniqueIdentifier: "BNK-000456", Description: "John Doe", Amount: 100, Bank: "bank-a"}
+	result := model.ReconcileResponse{
+		UnmatchedSystem: []model.Transaction{tx},
+		UnmatchedByBank: map[string][]model.BankStatement{"bank-a": {stmt}},
+		Matches:         []model.MatchedPair{{System: tx, Statement: stmt, Bank: "bank-a"}},
+		Duplicates:      []model.Duplicate{{Source: "system", Reason: reconciliation.DuplicateExactKey, Key: tx.TrxID, Transactions: []model.Transaction{tx, tx}}},
+		Matched:         1,
+	}
+
+	masked := m.Result(result)
+	if got := masked.UnmatchedSystem[0]; got.TrxID != "******0123" || got.Description != Redacted || got.Amount != 100 {
+		t.Errorf("UnmatchedSystem[0] = %+v", got)
+	}
+	if got := masked.UnmatchedByBank["bank-a"][0]; got.UniqueIdentifier != "******0456" || got.Bank != "bank-a" {
+		t.Errorf("UnmatchedByBank = %+v", got)
+	}
+	if got := masked.Matches[0]; got.System.TrxID != "******0123" || got.Statement.Description != Redacted {
+		t.Errorf("Matches[0] = %+v", got)
+	}
+	if got := masked.Duplicates[0]; got.Key != "******0123" || got.Transactions[1].TrxID != "******0123" {
+		t.Errorf("Duplicates[0] = %+v", got)
+	}
+	if masked.Matched != 1 {
+		t.Errorf("Matched = %d, totals must be kept", masked.Matched)
+	}
+
+	// The original result is left unchanged
+	if result.UnmatchedSystem[0].TrxID != tx.TrxID || result.Matches[0].Statement.Description != stmt.Description {
+		t.Error("Result() changed the original result")
+	}
+
+	var none *Masker
+	if got := none.Result(result); got.UnmatchedSystem[0].TrxID != tx.TrxID {
+		t.Error("a nil masker must leave the result unmasked")
+	}
+}
@@ -0,0 +1,117 @@
+package model
+
+import "time"
+
+// Statuses of a run
+// RunStatusOpen: No sign-off report is attached to the run yet
+// RunStatusSignedOff: A sign-off report is attached to the run
+const (
+	RunStatusOpen      = "open"
+	RunStatusSignedOff = "signed_off"
+)
+
+// Statuses of an exception
+// ExceptionUnmatchedSystem: A system transaction without a bank statement line
+// ExceptionUnmatchedBank: A bank statement line without a system transaction
+// ExceptionDiscrepancy: A matched pair whose amounts differ by more than the expected fee
+const (
+	ExceptionUnmatchedSystem = "unmatched_system"
+	ExceptionUnmatchedBank   = "unmatched_bank"
+	ExceptionDiscrepancy     = "discrepancy"
+)
+
+// Kinds of an override
+// OverrideManualMatch: Pairs an unmatched system transaction with the unmatched bank statement lines paying it
+// OverrideWriteOff: Accepts exceptions as they are, their amount being written off
+const (
+	OverrideManualMatch = "manual_match"
+	OverrideWriteOff    = "write_off"
+)
+
+// Statuses of a match
+// MatchExact: The amounts and days are equal
+// MatchExplained: The amounts differ by the expected fee only, or the days differ
+// MatchDiscrepancy: The amounts differ by more than the expected fee
+const (
+	MatchExact       = "exact"
+	MatchExplained   = "explained"
+	MatchDiscrepancy = "discrepancy"
+)
+
+// Page is a page of a listed resource
+// Total: Number of items matching the filters, across every page
+// Offset: Position of the first item of the page among them
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// RunOverview describes a stored run with its totals, without the records of its result
+// Banks: Banks reconciled in the run
+type RunOverview struct {
+	RunSummary
+	Status        string   `json:"status"`
+	Banks         []string `json:"banks"`
+	Matched       int      `json:"matched"`
+	Unmatched     int      `json:"unmatched"`
+	Fees          float64  `json:"fees"`
+	Discrepancies float64  `json:"discrepancies"`
+}
+
+// Exception is a record of a run needing review
+// ID: Identifies the exception within its run
+// Bank: Bank of the statement line, or the bank a discrepancy matched against; empty for unmatched system transactions
+// Identifier: Transaction ID of system records, unique identifier of bank statement lines
+// Date: Transaction time of system records, date of bank statement lines
+// Residual: Unexplained part of the amount delta of a discrepancy
+// Match: The matched pair of a discrepancy
+// Override: ID of the override resolving the exception, empty while it is open
+type Exception struct {
+	ID          string       `json:"id"`
+	Status      string       `json:"status"`
+	Bank        string       `json:"bank,omitempty"`
+	Identifier  string       `json:"identifier"`
+	Date        time.Time    `json:"date"`
+	Amount      float64      `json:"amount"`
+	Description string       `json:"description,omitempty"`
+	Residual    float64      `json:"residual,omitempty"`
+	Match       *MatchedPair `json:"match,omitempty"`
+	Override    string       `json:"override,omitempty"`
+}
+
+// Override resolves exceptions of a stored run by hand, the result of the run is left as it is
+// ID: Identifies the override within its run
+// Exceptions: IDs of the exceptions resolved, see Exception.ID
+// Amount: Amount written off, the residual of discrepancies; for manual matches, the amount of the
+// system transaction left unexplained by the bank statement lines
+// CreatedBy: Caller who made the override, empty when authentication is disabled
+type Override struct {
+	ID         string    `json:"id"`
+	Kind       string    `json:"kind"`
+	Exceptions []string  `json:"exceptions"`
+	Reason     string    `json:"reason"`
+	Amount     float64   `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+	CreatedBy  string    `json:"created_by,omitempty"`
+}
+
+// Match is a matched pair of a run with its status
+// ID: Identifies the match within its run
+type Match struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	MatchedPair
+}
+
+// Bank describes a bank known to the caller: configured, or reconciled in a stored run
+// FeeType: Type of the fee rule configured for the bank: flat, percentage or tiered
+// Runs: Number of stored runs reconciling the bank
+// LastRunAt: Time of the most recent of them
+type Bank struct {
+	Name      string     `json:"name"`
+	FeeType   string     `json:"fee_type,omitempty"`
+	Runs      int        `json:"runs"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Run is a stored reconciliation: the parameters it ran with and its result
+// ID: Unique identifier of the run
+// CreatedAt: Time the reconciliation finished
+// Tenant: Business unit owning the run, empty for the default tenant
+// CreatedBy: Caller who ran the reconciliation, empty when authentication is disabled
+type Run struct {
+	ID        string            `json:"id"`
+	CreatedAt time.Time         `json:"created_at"`
+	Tenant    string            `json:"tenant,omitempty"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	Params    RunParams         `json:"params"`
+	Result    ReconcileResponse `json:"result"`
+}
+
+// RunParams holds the inputs a reconciliation ran with
+// SystemFile/BankFiles: Original names of the input files
+// InputFiles: Checksums of every input file, to prove which data the run used
+type RunParams struct {
+	StartDate  string      `json:"start_date"`
+	EndDate    string      `json:"end_date"`
+	SystemFile string      `json:"system_file"`
+	BankFiles  []string    `json:"bank_files"`
+	InputFiles []InputFile `json:"input_files,omitempty"`
+}
+
+// InputFile identifies an input file of a run by its content
+// Role: "system", "bank" or "balances"
+// SHA256: Hex encoded SHA-256 checksum of the file content
+type InputFile struct {
+	Role   string `json:"role"`
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+}
+
+// RunSummary describes a stored run without its result, for listings
+type RunSummary struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Tenant    string    `json:"tenant,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Params    RunParams `json:"params"`
+}
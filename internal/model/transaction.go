@@ -7,10 +7,12 @@ import "time"
 // Amount: Transaction amount
 // Type: Type of transaction (DEBIT or CREDIT)
 // TransactionTime: Date and time of the transaction
+// Description: Optional free-text description of the transaction
 type Transaction struct {
 	TransactionTime time.Time `json:"transaction_time"`
 	TrxID           string    `json:"trx_id"`
 	Type            string    `json:"type"`
+	Description     string    `json:"description,omitempty"`
 	Amount          float64   `json:"amount"`
 }
 
@@ -20,19 +22,135 @@ type Transaction struct {
 // Date: Date of the transaction
 // Type: Type of transaction (DEBIT or CREDIT)
 // Bank: Unmatched BankTransaction
+// Description: Optional free-text description of the statement line
 type BankStatement struct {
 	Date             time.Time `json:"date"`
 	UniqueIdentifier string    `json:"unique_identifier"`
 	Type             string    `json:"type"`
+	Description      string    `json:"description,omitempty"`
 	Amount           float64   `json:"amount"`
 	Bank             string    `json:"bank"`
 }
 
+// Duplicate groups records of a single source that share a key or look alike
+// Source: "system" or the bank name the records came from
+// Reason: "exact_key" when the identifier repeats, "likely" when amount/date/description repeat
+// Key: The repeated identifier, or the fingerprint for likely duplicates
+type Duplicate struct {
+	Source       string          `json:"source"`
+	Reason       string          `json:"reason"`
+	Key          string          `json:"key"`
+	Transactions []Transaction   `json:"transactions,omitempty"`
+	Statements   []BankStatement `json:"statements,omitempty"`
+}
+
+// InternalTransfer pairs offsetting bank lines moving money between two of our own accounts
+// From: The debit leg in the sending bank
+// To: The credit leg in the receiving bank
+type InternalTransfer struct {
+	From BankStatement `json:"from"`
+	To   BankStatement `json:"to"`
+}
+
+// Reversal pairs an original entry with the equal-and-opposite entry reversing it, netting both to zero
+// Source: "system" or the bank name the entries came from
+// Rule: "same_reference" when both share the identifier, "referenced" when the reversal mentions the original
+// Transactions/Statements: The original entry first, the reversal second
+type Reversal struct {
+	Source       string          `json:"source"`
+	Rule         string          `json:"rule"`
+	Amount       float64         `json:"amount"`
+	Transactions []Transaction   `json:"transactions,omitempty"`
+	Statements   []BankStatement `json:"statements,omitempty"`
+}
+
+// StatementBalance holds the balances printed on a bank statement
+// Bank: The bank name the balances belong to
+// Opening: Balance before the first line of the statement
+// Closing: Balance after the last line of the statement
+type StatementBalance struct {
+	Bank    string  `json:"bank"`
+	Opening float64 `json:"opening"`
+	Closing float64 `json:"closing"`
+}
+
+// BalanceCheck verifies a bank statement is complete: opening balance plus all lines must equal the closing balance
+// LinesTotal: Sum of the statement lines, credits positive and debits negative
+// Difference: Closing balance minus opening balance plus lines, non-zero when lines are missing
+// Balanced: Whether the difference is zero, matching results of unbalanced banks should not be trusted
+type BalanceCheck struct {
+	Bank       string  `json:"bank"`
+	Opening    float64 `json:"opening"`
+	Closing    float64 `json:"closing"`
+	LinesTotal float64 `json:"lines_total"`
+	Difference float64 `json:"difference"`
+	Balanced   bool    `json:"balanced"`
+}
+
+// MatchedPair details a system transaction matched with a bank statement line
+// Bank: The bank the system transaction matched against
+// AmountDelta: System amount minus bank amount
+// Fee: Part of the delta expected from the fee rule of the bank
+// Residual: Unexplained part of the delta, counted in the discrepancies
+// DateDeltaDays: Days between the system transaction and the bank line, positive when the bank is later
+// Rule: The matching rule that paired the records
+type MatchedPair struct {
+	System        Transaction   `json:"system"`
+	Statement     BankStatement `json:"statement"`
+	Bank          string        `json:"bank"`
+	AmountDelta   float64       `json:"amount_delta"`
+	Fee           float64       `json:"fee"`
+	Residual      float64       `json:"residual"`
+	DateDeltaDays float64       `json:"date_delta_days"`
+	Rule          string        `json:"rule"`
+}
+
+// BankSummary holds the reconciliation figures of a single bank
+type BankSummary struct {
+	Bank          string  `json:"bank"`
+	Matched       int     `json:"matched"`
+	Unmatched     int     `json:"unmatched"`
+	Fees          float64 `json:"fees"`
+	Discrepancies float64 `json:"discrepancies"`
+}
+
+// DailySummary holds the matched and unmatched counts of a single day
+// Date: The day in YYYY-MM-DD format, matched pairs count on the day of the system transaction
+type DailySummary struct {
+	Date      string `json:"date"`
+	Matched   int    `json:"matched"`
+	Unmatched int    `json:"unmatched"`
+}
+
+// ParseError describes a row of an input file that could not be parsed and was left out
+// Line: The 1-based line of the row in the file, the header being line 1
+type ParseError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ReconcileResponse is the result of a reconciliation
+// RecordsParsed: System and bank records read from the files, before filtering by date
+// TotalProcessed: System transactions within the timeframe that went through matching
+// DiscrepantMatches: Matched pairs with a residual, kept whatever pairs Matches lists as they are
+// exceptions to review
 type ReconcileResponse struct {
-	UnmatchedSystem []Transaction              `json:"umatched_system"`
-	UnmatchedByBank map[string][]BankStatement `json:"unmatched_by_bank"`
-	Discrepancies   float64                    `json:"discrepancies"`
-	TotalProcessed  int                        `json:"total_processed"`
-	Matched         int                        `json:"matched"`
-	Unmatched       int                        `json:"umatched"`
+	UnmatchedSystem   []Transaction              `json:"umatched_system"`
+	UnmatchedByBank   map[string][]BankStatement `json:"unmatched_by_bank"`
+	Matches           []MatchedPair              `json:"matches"`
+	DiscrepantMatches []MatchedPair              `json:"discrepant_matches,omitempty"`
+	ByBank            []BankSummary              `json:"by_bank"`
+	Daily             []DailySummary             `json:"daily"`
+	Duplicates        []Duplicate                `json:"duplicates"`
+	InternalTransfers []InternalTransfer         `json:"internal_transfers"`
+	Reversals         []Reversal                 `json:"reversals"`
+	BalanceChecks     []BalanceCheck             `json:"balance_checks"`
+	ParseErrors       []ParseError               `json:"parse_errors"`
+	Discrepancies     float64                    `json:"discrepancies"`
+	Fees              float64                    `json:"fees"`
+	RecordsParsed     int                        `json:"records_parsed"`
+	TotalProcessed    int                        `json:"total_processed"`
+	Matched           int                        `json:"matched"`
+	Unmatched         int                        `json:"umatched"`
 }
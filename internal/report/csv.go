@@ -0,0 +1,40 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// WriteCSV writes every report section as a block of the same CSV file:
+// a row with the section name, the header row, the data rows and an empty separator row
+func WriteCSV(w io.Writer, result model.ReconcileResponse) error {
+	writer := csv.NewWriter(w)
+	for i, section := range tables(result) {
+		if i > 0 {
+			if err := writer.Write([]string{}); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write([]string{section.name}); err != nil {
+			return err
+		}
+		if err := writer.Write(section.header); err != nil {
+			return err
+		}
+		for _, row := range section.rows {
+			record := make([]string, len(row))
+			for j, cell := range row {
+				record[j] = formatCell(cell)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
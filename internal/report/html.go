@@ -0,0 +1,130 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+//go:embed templates/report.html
+var htmlTemplate string
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"amount": func(value float64) string { return formatCell(value) },
+}).Parse(htmlTemplate))
+
+const (
+	chartHeight     = 220
+	chartLabelSpace = 70
+	chartBarWidth   = 18
+	chartBarGap     = 8
+)
+
+type htmlCell struct {
+	Text    string
+	Value   any
+	Numeric bool
+}
+
+type htmlSection struct {
+	Name   string
+	Header []string
+	Rows   [][]htmlCell
+}
+
+type chartBar struct {
+	Date                        string
+	Matched, Unmatched          int
+	X, LabelX, Width            int
+	MatchedY, MatchedHeight     int
+	UnmatchedY, UnmatchedHeight int
+}
+
+type chart struct {
+	Bars                  []chartBar
+	Width, Height, LabelY int
+}
+
+type htmlData struct {
+	Run       model.Run
+	Generated string
+	MatchRate string
+	Chart     chart
+	Sections  []htmlSection
+}
+
+// WriteHTML writes a self-contained HTML page with the summary, the per-bank breakdown,
+// a chart of matched and unmatched transactions per day and sortable, filterable exception tables.
+// The run ID and parameters are shown when set.
+func WriteHTML(w io.Writer, run model.Run) error {
+	result := run.Result
+
+	matchRate := "-"
+	if result.TotalProcessed > 0 {
+		matchRate = fmt.Sprintf("%.1f%%", float64(result.Matched)*100/float64(result.TotalProcessed))
+	}
+
+	// The summary is already shown as KPIs
+	sections := make([]htmlSection, 0)
+	for _, section := range tables(result)[1:] {
+		rows := make([][]htmlCell, 0, len(section.rows))
+		for _, row := range section.rows {
+			cells := make([]htmlCell, len(row))
+			for i, cell := range row {
+				_, isString := cell.(string)
+				cells[i] = htmlCell{Text: formatCell(cell), Value: cell, Numeric: !isString}
+			}
+			rows = append(rows, cells)
+		}
+		sections = append(sections, htmlSection{Name: section.name, Header: section.header, Rows: rows})
+	}
+
+	return reportTemplate.Execute(w, htmlData{
+		Run:       run,
+		Generated: time.Now().UTC().Format("2006-01-02 15:04:05 MST"),
+		MatchRate: matchRate,
+		Chart:     dailyChart(result.Daily),
+		Sections:  sections,
+	})
+}
+
+// dailyChart lays out one stacked bar per day, scaled on the busiest day
+func dailyChart(days []model.DailySummary) chart {
+	busiest := 0
+	for _, day := range days {
+		busiest = max(busiest, day.Matched+day.Unmatched)
+	}
+
+	c := chart{
+		Width:  len(days)*(chartBarWidth+chartBarGap) + chartBarGap + chartLabelSpace,
+		Height: chartHeight + chartLabelSpace,
+		LabelY: chartHeight + 12,
+	}
+	if busiest == 0 {
+		return c
+	}
+
+	for i, day := range days {
+		x := chartBarGap + chartLabelSpace/2 + i*(chartBarWidth+chartBarGap)
+		matchedHeight := day.Matched * chartHeight / busiest
+		unmatchedHeight := day.Unmatched * chartHeight / busiest
+		c.Bars = append(c.Bars, chartBar{
+			Date:            day.Date,
+			Matched:         day.Matched,
+			Unmatched:       day.Unmatched,
+			X:               x,
+			LabelX:          x + chartBarWidth/2,
+			Width:           chartBarWidth,
+			MatchedY:        chartHeight - matchedHeight,
+			MatchedHeight:   matchedHeight,
+			UnmatchedY:      chartHeight - matchedHeight - unmatchedHeight,
+			UnmatchedHeight: unmatchedHeight,
+		})
+	}
+
+	return c
+}
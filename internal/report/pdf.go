@@ -0,0 +1,335 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// Landscape A4 in points, tables need the width
+const (
+	pdfPageWidth  = 842.0
+	pdfPageHeight = 595.0
+	pdfMargin     = 40.0
+
+	pdfFontRegular = "F1"
+	pdfFontBold    = "F2"
+	pdfFontMono    = "F3"
+
+	pdfTableFontSize = 7.5
+	pdfMaxColumn     = 26
+)
+
+// SignOff holds the names printed in the sign-off block of the PDF report, empty names are left blank to be filled by hand
+type SignOff struct {
+	PreparedBy string `json:"prepared_by"`
+	Reviewer   string `json:"reviewer"`
+	Approver   string `json:"approver"`
+}
+
+// WritePDF writes the month-end sign-off document of a run: run parameters and input file checksums,
+// the summary, every exception section and the preparer, reviewer and approver fields
+func WritePDF(w io.Writer, run model.Run, signOff SignOff) error {
+	doc := newPDFDocument()
+	result := run.Result
+
+	doc.heading("Reconciliation Sign-off Report", 18)
+	doc.paragraph(pdfFontRegular, 9, "Generated "+time.Now().UTC().Format("2006-01-02 15:04:05 MST"))
+	doc.space(8)
+
+	doc.heading("Run Parameters", 12)
+	params := [][2]string{
+		{"Run ID", valueOr(run.ID, "not stored")},
+		{"Created", formatRunTime(run.CreatedAt)},
+		{"Period", run.Params.StartDate + " to " + run.Params.EndDate},
+		{"System file", run.Params.SystemFile},
+		{"Bank files", strings.Join(run.Params.BankFiles, ", ")},
+	}
+	for _, param := range params {
+		doc.keyValue(param[0], param[1])
+	}
+	doc.space(6)
+
+	doc.heading("Input File Checksums (SHA-256)", 12)
+	if len(run.Params.InputFiles) == 0 {
+		doc.paragraph(pdfFontRegular, 9, "No checksums recorded.")
+	}
+	for _, file := range run.Params.InputFiles {
+		doc.paragraph(pdfFontMono, 8, fmt.Sprintf("%-8s %s  %s", file.Role, file.SHA256, file.Name))
+	}
+	doc.space(6)
+
+	doc.heading("Summary", 12)
+	for _, row := range tables(result)[0].rows {
+		doc.keyValue(formatCell(row[0]), formatCell(row[1]))
+	}
+	for _, check := range result.BalanceChecks {
+		if !check.Balanced {
+			doc.paragraph(pdfFontBold, 9, fmt.Sprintf("WARNING: statement of %s does not balance (difference %.2f)", check.Bank, check.Difference))
+		}
+	}
+	doc.space(6)
+
+	// Exceptions only, perfect matches do not need a review
+	doc.heading("Exceptions", 14)
+	for _, section := range tables(result)[1:] {
+		if section.name == "Matched" {
+			continue
+		}
+		doc.table(section)
+	}
+
+	doc.signOff(signOff)
+
+	_, err := w.Write(doc.bytes())
+	return err
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func formatRunTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02 15:04:05 MST")
+}
+
+// pdfDocument lays out text top to bottom on as many pages as needed
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newPDFDocument() *pdfDocument {
+	doc := &pdfDocument{}
+	doc.newPage()
+	return doc
+}
+
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// advance moves the cursor down by height, starting a new page when it does not fit
+func (d *pdfDocument) advance(height float64) {
+	if d.y-height < pdfMargin+20 {
+		d.newPage()
+	}
+	d.y -= height
+}
+
+func (d *pdfDocument) space(height float64) {
+	d.y -= height
+}
+
+func (d *pdfDocument) text(font string, size, x, y float64, value string) {
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(value))
+}
+
+func (d *pdfDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+func (d *pdfDocument) heading(value string, size float64) {
+	d.advance(size + 6)
+	d.text(pdfFontBold, size, pdfMargin, d.y, value)
+}
+
+func (d *pdfDocument) paragraph(font string, size float64, value string) {
+	d.advance(size + 4)
+	d.text(font, size, pdfMargin, d.y, value)
+}
+
+func (d *pdfDocument) keyValue(key, value string) {
+	d.advance(13)
+	d.text(pdfFontBold, 9, pdfMargin, d.y, key)
+	d.text(pdfFontRegular, 9, pdfMargin+170, d.y, value)
+}
+
+// table prints a section in a monospaced font, truncating cells that do not fit their column
+func (d *pdfDocument) table(section table) {
+	d.space(6)
+	d.heading(fmt.Sprintf("%s (%d)", section.name, len(section.rows)), 10)
+	if len(section.rows) == 0 {
+		d.paragraph(pdfFontRegular, 8, "None.")
+		return
+	}
+
+	rows := make([][]string, 0, len(section.rows)+1)
+	rows = append(rows, section.header)
+	for _, row := range section.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = formatCell(cell)
+		}
+		rows = append(rows, cells)
+	}
+
+	// Courier glyphs are 0.6 em wide
+	available := (pdfPageWidth - 2*pdfMargin) / (pdfTableFontSize * 0.6)
+	widths := columnWidths(rows, int(available))
+	for i, row := range rows {
+		var line strings.Builder
+		for j, cell := range row {
+			if j > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(fit(cell, widths[j]))
+		}
+
+		if i == 0 {
+			d.advance(pdfTableFontSize + 5)
+			d.line(pdfMargin, d.y-2.5, pdfPageWidth-pdfMargin, d.y-2.5)
+		} else {
+			d.advance(pdfTableFontSize + 3)
+		}
+		d.text(pdfFontMono, pdfTableFontSize, pdfMargin, d.y, line.String())
+	}
+}
+
+// columnWidths sizes every column to its longest cell, shrinking the widest ones
+// until the line, separators included, fits in available characters
+func columnWidths(rows [][]string, available int) []int {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = min(max(widths[i], len([]rune(cell))), pdfMaxColumn)
+		}
+	}
+
+	total := func() int {
+		sum := 2 * (len(widths) - 1)
+		for _, width := range widths {
+			sum += width
+		}
+		return sum
+	}
+	for total() > available {
+		widest := 0
+		for i, width := range widths {
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 4 {
+			break
+		}
+		widths[widest]--
+	}
+
+	return widths
+}
+
+// fit pads or truncates value to exactly width characters
+func fit(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "~"
+	}
+	return value + strings.Repeat(" ", width-len(runes))
+}
+
+// signOff prints the preparer, reviewer and approver fields, kept together on one page
+func (d *pdfDocument) signOff(signOff SignOff) {
+	if d.y-150 < pdfMargin+20 {
+		d.newPage()
+	}
+
+	d.space(14)
+	d.heading("Sign-off", 14)
+	fields := [][2]string{
+		{"Prepared by", signOff.PreparedBy},
+		{"Reviewed by", signOff.Reviewer},
+		{"Approved by", signOff.Approver},
+	}
+	for _, field := range fields {
+		d.space(26)
+		d.text(pdfFontBold, 9, pdfMargin, d.y, field[0])
+		d.text(pdfFontRegular, 10, pdfMargin+80, d.y+3, field[1])
+		d.line(pdfMargin+75, d.y-2, pdfMargin+300, d.y-2)
+		d.text(pdfFontBold, 9, pdfMargin+320, d.y, "Date")
+		d.line(pdfMargin+350, d.y-2, pdfMargin+450, d.y-2)
+		d.text(pdfFontBold, 9, pdfMargin+470, d.y, "Signature")
+		d.line(pdfMargin+525, d.y-2, pdfPageWidth-pdfMargin, d.y-2)
+	}
+}
+
+// bytes assembles the PDF file: catalog, page tree, fonts, then a page and a content stream per page
+func (d *pdfDocument) bytes() []byte {
+	for i, page := range d.pages {
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		fmt.Fprintf(page, "BT /%s 8 Tf %.2f %.2f Td (%s) Tj ET\n", pdfFontRegular, pdfPageWidth-pdfMargin-50, pdfMargin/2, footer)
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // page tree, needs the page object numbers
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Title (Reconciliation Sign-off Report) /Producer (reconciliation) >>",
+	}
+
+	kids := make([]string, 0, len(d.pages))
+	for _, page := range d.pages {
+		pageObject := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObject))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /%s 3 0 R /%s 4 0 R /%s 5 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, pdfFontRegular, pdfFontBold, pdfFontMono, pageObject+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return out.Bytes()
+}
+
+// pdfEscape makes value a valid PDF literal string in WinAnsi encoding,
+// characters outside Latin-1 are replaced with a question mark
+func pdfEscape(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32:
+			b.WriteByte(' ')
+		case r > 255:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,231 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+const (
+	FormatText = "text"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+var contentTypes = map[string]string{
+	FormatText: "text/plain; charset=utf-8",
+	FormatCSV:  "text/csv",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatHTML: "text/html; charset=utf-8",
+	FormatPDF:  "application/pdf",
+}
+
+// Write renders the reconciliation run in the given format, the run ID and parameters are optional
+func Write(w io.Writer, format string, run model.Run) error {
+	switch format {
+	case FormatText:
+		return WriteText(w, run.Result)
+	case FormatCSV:
+		return WriteCSV(w, run.Result)
+	case FormatXLSX:
+		return WriteXLSX(w, run.Result)
+	case FormatHTML:
+		return WriteHTML(w, run)
+	case FormatPDF:
+		return WritePDF(w, run, SignOff{})
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// ContentType returns the MIME type of a report format, or an empty string for unknown formats
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// FormatFromAccept returns the first report format listed in an HTTP Accept header,
+// or an empty string when none of them is a report format
+func FormatFromAccept(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(mediaRange, ";")
+		mediaType = strings.TrimSpace(mediaType)
+		for format, contentType := range contentTypes {
+			if ct, _, _ := strings.Cut(contentType, ";"); ct == mediaType {
+				return format
+			}
+		}
+	}
+	return ""
+}
+
+// table is a named section of a report, rendered as a CSV block or a worksheet.
+// Cells hold a string, an int or a float64 amount.
+type table struct {
+	name   string
+	header []string
+	rows   [][]any
+}
+
+var (
+	matchHeader     = []string{"System ID", "System Time", "Type", "System Amount", "Bank", "Bank ID", "Bank Date", "Bank Amount", "Amount Delta", "Fee", "Residual", "Date Delta (days)", "Rule"}
+	transactionHead = []string{"Transaction ID", "Transaction Time", "Type", "Amount", "Description"}
+	statementHeader = []string{"Identifier", "Date", "Type", "Amount", "Description"}
+)
+
+// tables splits the result into the report sections:
+// summary, matched, mismatched, unmatched system, unmatched per bank and the other exceptions
+func tables(result model.ReconcileResponse) []table {
+	unbalanced := 0
+	for _, check := range result.BalanceChecks {
+		if !check.Balanced {
+			unbalanced++
+		}
+	}
+
+	summary := table{
+		name:   "Summary",
+		header: []string{"Metric", "Value"},
+		rows: [][]any{
+			{"Total transactions processed", result.TotalProcessed},
+			{"Total matched transactions", result.Matched},
+			{"Total unmatched transactions", result.Unmatched},
+			{"Total discrepancies", result.Discrepancies},
+			{"Total expected fees", result.Fees},
+			{"Duplicates", len(result.Duplicates)},
+			{"Reversals", len(result.Reversals)},
+			{"Internal transfers", len(result.InternalTransfers)},
+			{"Unbalanced statements", unbalanced},
+			{"Rows with parse errors", len(result.ParseErrors)},
+		},
+	}
+
+	matched := table{name: "Matched", header: matchHeader}
+	mismatched := table{name: "Mismatched", header: matchHeader}
+	for _, pair := range result.Matches {
+		row := []any{
+			pair.System.TrxID, formatTime(pair.System.TransactionTime), pair.System.Type, pair.System.Amount,
+			pair.Bank, pair.Statement.UniqueIdentifier, formatDate(pair.Statement.Date), pair.Statement.Amount,
+			pair.AmountDelta, pair.Fee, pair.Residual, int(pair.DateDeltaDays), pair.Rule,
+		}
+		matched.rows = append(matched.rows, row)
+		if pair.Residual != 0 || pair.DateDeltaDays != 0 {
+			mismatched.rows = append(mismatched.rows, row)
+		}
+	}
+
+	unmatchedSystem := table{name: "Unmatched System", header: transactionHead}
+	for _, tx := range result.UnmatchedSystem {
+		unmatchedSystem.rows = append(unmatchedSystem.rows, transactionRow(tx))
+	}
+
+	sections := []table{summary, matched, mismatched, unmatchedSystem}
+
+	banks := make([]string, 0, len(result.UnmatchedByBank))
+	for bank := range result.UnmatchedByBank {
+		banks = append(banks, bank)
+	}
+	sort.Strings(banks)
+	for _, bank := range banks {
+		unmatchedBank := table{name: "Unmatched " + bank, header: statementHeader}
+		for _, stmt := range result.UnmatchedByBank[bank] {
+			unmatchedBank.rows = append(unmatchedBank.rows, statementRow(stmt))
+		}
+		sections = append(sections, unmatchedBank)
+	}
+
+	transfers := table{
+		name:   "Internal Transfers",
+		header: []string{"From Bank", "From ID", "From Date", "To Bank", "To ID", "To Date", "Amount"},
+	}
+	for _, transfer := range result.InternalTransfers {
+		transfers.rows = append(transfers.rows, []any{
+			transfer.From.Bank, transfer.From.UniqueIdentifier, formatDate(transfer.From.Date),
+			transfer.To.Bank, transfer.To.UniqueIdentifier, formatDate(transfer.To.Date),
+			transfer.From.Amount,
+		})
+	}
+
+	reversals := table{
+		name:   "Reversals",
+		header: []string{"Source", "Rule", "Amount", "Original ID", "Original Date", "Reversal ID", "Reversal Date"},
+	}
+	for _, reversal := range result.Reversals {
+		ids, dates := make([]string, 0, 2), make([]string, 0, 2)
+		for _, tx := range reversal.Transactions {
+			ids, dates = append(ids, tx.TrxID), append(dates, formatTime(tx.TransactionTime))
+		}
+		for _, stmt := range reversal.Statements {
+			ids, dates = append(ids, stmt.UniqueIdentifier), append(dates, formatDate(stmt.Date))
+		}
+		if len(ids) != 2 {
+			continue
+		}
+		reversals.rows = append(reversals.rows, []any{reversal.Source, reversal.Rule, reversal.Amount, ids[0], dates[0], ids[1], dates[1]})
+	}
+
+	duplicates := table{
+		name:   "Duplicates",
+		header: []string{"Source", "Reason", "Key", "Identifier", "Date", "Type", "Amount", "Description"},
+	}
+	for _, dup := range result.Duplicates {
+		for _, tx := range dup.Transactions {
+			duplicates.rows = append(duplicates.rows, append([]any{dup.Source, dup.Reason, dup.Key}, transactionRow(tx)...))
+		}
+		for _, stmt := range dup.Statements {
+			duplicates.rows = append(duplicates.rows, append([]any{dup.Source, dup.Reason, dup.Key}, statementRow(stmt)...))
+		}
+	}
+
+	balances := table{
+		name:   "Balance Checks",
+		header: []string{"Bank", "Opening", "Lines Total", "Closing", "Difference", "Balanced"},
+	}
+	for _, check := range result.BalanceChecks {
+		balances.rows = append(balances.rows, []any{check.Bank, check.Opening, check.LinesTotal, check.Closing, check.Difference, strconv.FormatBool(check.Balanced)})
+	}
+
+	parseErrors := table{
+		name:   "Parse Errors",
+		header: []string{"File", "Line", "Message"},
+	}
+	for _, parseErr := range result.ParseErrors {
+		parseErrors.rows = append(parseErrors.rows, []any{parseErr.File, parseErr.Line, parseErr.Message})
+	}
+
+	return append(sections, transfers, reversals, duplicates, balances, parseErrors)
+}
+
+func transactionRow(tx model.Transaction) []any {
+	return []any{tx.TrxID, formatTime(tx.TransactionTime), tx.Type, tx.Amount, tx.Description}
+}
+
+func statementRow(stmt model.BankStatement) []any {
+	return []any{stmt.UniqueIdentifier, formatDate(stmt.Date), stmt.Type, stmt.Amount, stmt.Description}
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// formatCell renders a table cell as text, amounts with two decimals
+func formatCell(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
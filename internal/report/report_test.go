@@ -0,0 +1,231 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+func parseDate(dateStr string) time.Time {
+	parsed, _ := time.Parse("2006-01-02", dateStr)
+	return parsed
+}
+
+func sampleResult() model.ReconcileResponse {
+	return model.ReconcileResponse{
+		TotalProcessed: 2,
+		Matched:        1,
+		Unmatched:      2,
+		Discrepancies:  10,
+		Matches: []model.MatchedPair{
+			{
+				System:      model.Transaction{TrxID: "T1", Amount: 100, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				Statement:   model.BankStatement{UniqueIdentifier: "T1", Amount: 90, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				Bank:        "bank-a",
+				AmountDelta: 10,
+				Residual:    10,
+				Rule:        "trx_id",
+			},
+		},
+		UnmatchedSystem: []model.Transaction{
+			{TrxID: "T2", Amount: 200, Type: "DEBIT", Description: `Invoice "42", <urgent>`, TransactionTime: parseDate("2024-01-02")},
+		},
+		UnmatchedByBank: map[string][]model.BankStatement{
+			"bank-b": {{UniqueIdentifier: "B1", Amount: 300, Type: "CREDIT", Date: parseDate("2024-01-03"), Bank: "bank-b"}},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sampleResult()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	reader := csv.NewReader(&buf)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+
+	sections := make(map[string][][]string)
+	current := ""
+	for _, record := range records {
+		// Section titles are the only single column rows, the CSV reader skips the empty separators
+		if len(record) == 1 {
+			current = record[0]
+			continue
+		}
+		sections[current] = append(sections[current], record)
+	}
+
+	for _, name := range []string{"Summary", "Matched", "Mismatched", "Unmatched System", "Unmatched bank-b"} {
+		if _, ok := sections[name]; !ok {
+			t.Errorf("section %q missing", name)
+		}
+	}
+	if got := sections["Unmatched System"][1]; got[0] != "T2" || got[3] != "200.00" || got[4] != `Invoice "42", <urgent>` {
+		t.Errorf("unmatched system row = %v", got)
+	}
+	if got := sections["Mismatched"]; len(got) != 2 {
+		t.Errorf("mismatched rows = %d, want header and one pair", len(got))
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, sampleResult()); err != nil {
+		t.Fatalf("WriteXLSX() error = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("part %s missing", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="Unmatched bank-b"`) {
+		t.Errorf("workbook has no sheet per bank: %s", files["xl/workbook.xml"])
+	}
+	if !strings.Contains(files["xl/worksheets/sheet4.xml"], "Invoice &#34;42&#34;, &lt;urgent&gt;") {
+		t.Errorf("unmatched system sheet does not escape strings: %s", files["xl/worksheets/sheet4.xml"])
+	}
+}
+
+func TestFormatFromAccept(t *testing.T) {
+	tests := map[string]string{
+		"text/csv":                         FormatCSV,
+		"application/json, text/csv;q=0.9": FormatCSV,
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": FormatXLSX,
+		"application/json": "",
+		"":                 "",
+	}
+
+	for accept, want := range tests {
+		if got := FormatFromAccept(accept); got != want {
+			t.Errorf("FormatFromAccept(%q) = %q, want %q", accept, got, want)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	tests := map[string]string{
+		"Unmatched bank-a": "Unmatched bank-a",
+		"Unmatched bank/a": "Unmatched bank_a",
+		"Unmatched bank-with-a-very-long-statement-name":   "Unmatched bank-with-a-very-lo~3",
+		"Unmatched Банк-Центральный-Расчётный-Счёт-Клиент": "Unmatched Банк-Центральный-Ра~3",
+	}
+	for name, want := range tests {
+		got := sheetName(name, 3)
+		if got != want || !utf8.ValidString(got) || utf8.RuneCountInString(got) > 31 {
+			t.Errorf("sheetName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	result := sampleResult()
+	result.Daily = []model.DailySummary{
+		{Date: "2024-01-01", Matched: 1},
+		{Date: "2024-01-02", Unmatched: 1},
+	}
+	run := model.Run{
+		ID:     "0123456789abcdef0123456789abcdef",
+		Params: model.RunParams{StartDate: "2024-01-01", EndDate: "2024-01-31"},
+		Result: result,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, run); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	page := buf.String()
+
+	for _, want := range []string{
+		"Run 0123456789abcdef0123456789abcdef",
+		"Period 2024-01-01 to 2024-01-31",
+		"<h2>Unmatched bank-b</h2>",
+		"2024-01-02: 0 matched, 1 unmatched",
+		"Invoice &#34;42&#34;, &lt;urgent&gt;",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	if strings.Contains(page, "<urgent>") {
+		t.Errorf("report does not escape descriptions")
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	run := model.Run{
+		ID:     "0123456789abcdef0123456789abcdef",
+		Params: model.RunParams{StartDate: "2024-01-01", EndDate: "2024-01-31", SystemFile: "system (dec).csv"},
+		Result: sampleResult(),
+	}
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, run, SignOff{Reviewer: "Jane Doe", Approver: "Budi"}); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+	pdf := buf.Bytes()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("output is not a PDF document")
+	}
+	for _, want := range []string{"(Jane Doe)", "(Budi)", `system \(dec\).csv`, `Unmatched bank-b \(1\)`} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+
+	// Every cross-reference entry must point at its object
+	start := bytes.LastIndex(pdf, []byte("startxref\n"))
+	var xref int
+	if _, err := fmt.Sscanf(string(pdf[start+len("startxref\n"):]), "%d", &xref); err != nil {
+		t.Fatalf("invalid startxref: %v", err)
+	}
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	var count int
+	fmt.Sscanf(lines[1], "0 %d", &count)
+	for i := 1; i < count; i++ {
+		var offset int
+		fmt.Sscanf(lines[2+i], "%d", &offset)
+		if !bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj", i))) {
+			t.Errorf("xref entry %d points at offset %d which is not the object", i, offset)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// WriteText writes the human readable summary printed by the CLI
+func WriteText(w io.Writer, result model.ReconcileResponse) error {
+	var b bytes.Buffer
+
+	// Warn about incomplete statements before anything else, their matching results are not reliable
+	for _, check := range result.BalanceChecks {
+		if !check.Balanced {
+			fmt.Fprintf(&b, "WARNING: statement of %s does not balance: opening %.2f + lines %.2f != closing %.2f (difference %.2f)\n",
+				check.Bank, check.Opening, check.LinesTotal, check.Closing, check.Difference)
+		}
+	}
+
+	for _, parseErr := range result.ParseErrors {
+		fmt.Fprintf(&b, "WARNING: %s line %d skipped: %s\n", parseErr.File, parseErr.Line, parseErr.Message)
+	}
+
+	// Print reconciliation summary
+	fmt.Fprintln(&b, "Reconciliation Summary")
+	fmt.Fprintln(&b, "-----------------------")
+	fmt.Fprintf(&b, "Total transactions processed: %d\n", result.TotalProcessed)
+	fmt.Fprintf(&b, "Total matched transactions: %d\n", result.Matched)
+	fmt.Fprintf(&b, "Total unmatched transactions: %d\n", result.Unmatched)
+	fmt.Fprintf(&b, "Total discrepancies: %.2f\n", result.Discrepancies)
+	fmt.Fprintf(&b, "Total expected fees: %.2f\n", result.Fees)
+	fmt.Fprintln(&b, "\nMatched Pairs:")
+	for _, pair := range result.Matches {
+		fmt.Fprintf(&b, "%s <-> %s (%s, %s): amount delta %.2f, fee %.2f, residual %.2f, date delta %.0f days\n",
+			pair.System.TrxID, pair.Statement.UniqueIdentifier, pair.Bank, pair.Rule,
+			pair.AmountDelta, pair.Fee, pair.Residual, pair.DateDeltaDays)
+	}
+	fmt.Fprintln(&b, "\nUnmatched System Transactions:")
+	for _, tx := range result.UnmatchedSystem {
+		fmt.Fprintln(&b, tx)
+	}
+	fmt.Fprintln(&b, "\nUnmatched By Bank Transactions:")
+	banks := make([]string, 0, len(result.UnmatchedByBank))
+	for bank := range result.UnmatchedByBank {
+		banks = append(banks, bank)
+	}
+	sort.Strings(banks)
+	for _, bank := range banks {
+		fmt.Fprintln(&b, bank)
+		for _, tx := range result.UnmatchedByBank[bank] {
+			fmt.Fprintln(&b, tx)
+		}
+	}
+	fmt.Fprintln(&b, "\nInternal Transfers:")
+	for _, transfer := range result.InternalTransfers {
+		fmt.Fprintf(&b, "%s -> %s: %v -> %v\n", transfer.From.Bank, transfer.To.Bank, transfer.From, transfer.To)
+	}
+	fmt.Fprintln(&b, "\nReversals:")
+	for _, reversal := range result.Reversals {
+		fmt.Fprintf(&b, "%s %s %.2f\n", reversal.Source, reversal.Rule, reversal.Amount)
+		for _, tx := range reversal.Transactions {
+			fmt.Fprintln(&b, tx)
+		}
+		for _, tx := range reversal.Statements {
+			fmt.Fprintln(&b, tx)
+		}
+	}
+	fmt.Fprintln(&b, "\nDuplicates:")
+	for _, dup := range result.Duplicates {
+		fmt.Fprintf(&b, "%s %s %s\n", dup.Source, dup.Reason, dup.Key)
+		for _, tx := range dup.Transactions {
+			fmt.Fprintln(&b, tx)
+		}
+		for _, tx := range dup.Statements {
+			fmt.Fprintln(&b, tx)
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,159 @@
+package report
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+%s</Types>`
+	xlsxSheetContentType = `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>
+%s</sheets>
+</workbook>`
+	xlsxWorkbookSheet = `<sheet name="%s" sheetId="%d" r:id="rId%d"/>
+`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+%s<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+	xlsxWorkbookSheetRel = `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>
+`
+	// Style 1 is the bold header, style 2 the two decimals amount
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+</styleSheet>`
+)
+
+// WriteXLSX writes the report as an Excel workbook with one worksheet per report section
+func WriteXLSX(w io.Writer, result model.ReconcileResponse) error {
+	sections := tables(result)
+	archive := zip.NewWriter(w)
+
+	var overrides, sheets, rels strings.Builder
+	for i, section := range sections {
+		n := i + 1
+		fmt.Fprintf(&overrides, xlsxSheetContentType, n)
+		fmt.Fprintf(&sheets, xlsxWorkbookSheet, xmlEscape(sheetName(section.name, i)), n, n)
+		fmt.Fprintf(&rels, xlsxWorkbookSheetRel, n, n)
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, overrides.String())},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, sheets.String())},
+		{"xl/_rels/workbook.xml.rels", fmt.Sprintf(xlsxWorkbookRels, rels.String(), len(sections)+1)},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, section := range sections {
+		parts = append(parts, struct{ name, content string }{
+			fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1),
+			worksheet(section),
+		})
+	}
+
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// worksheet renders a section as the XML of a worksheet, strings are stored inline
+func worksheet(section table) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]any, len(section.header))
+	for i, name := range section.header {
+		header[i] = name
+	}
+	writeRow(&b, 1, header, 1)
+	for i, row := range section.rows {
+		writeRow(&b, i+2, row, 0)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func writeRow(b *strings.Builder, index int, cells []any, style int) {
+	fmt.Fprintf(b, `<row r="%d">`, index)
+	for i, cell := range cells {
+		ref := fmt.Sprintf("%s%d", columnName(i), index)
+		switch v := cell.(type) {
+		case float64:
+			fmt.Fprintf(b, `<c r="%s" s="2"><v>%s</v></c>`, ref, formatCell(v))
+		case int:
+			fmt.Fprintf(b, `<c r="%s"><v>%d</v></c>`, ref, v)
+		default:
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr" s="%d"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xmlEscape(fmt.Sprint(v)))
+		}
+	}
+	b.WriteString(`</row>`)
+}
+
+// columnName converts a zero based column index to its spreadsheet letters: 0 is A, 26 is AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName makes a section name a valid worksheet name: at most 31 characters
+// without any of []:*?/\ and unique thanks to the section index when truncated.
+// It is truncated by rune, so multi-byte bank names stay valid UTF-8.
+func sheetName(name string, index int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if runes := []rune(name); len(runes) > 31 {
+		suffix := fmt.Sprintf("~%d", index)
+		name = string(runes[:31-len(suffix)]) + suffix
+	}
+
+	return name
+}
+
+func xmlEscape(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
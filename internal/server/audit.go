@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+)
+
+// record appends an audit entry for an action of the caller, answering with an error when it
+// cannot be written: an action that is not audited must not be reported as done
+func (s *Server) record(w http.ResponseWriter, r *http.Request, action, runID string, details any) bool {
+	principal, _ := auth.FromContext(r.Context())
+	if err := s.appendAudit(principal, action, runID, details); err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error recording audit entry: %v", err),
+		})
+		return false
+	}
+	return true
+}
+
+// appendAudit appends an audit entry for an action of principal
+func (s *Server) appendAudit(principal auth.Principal, action, runID string, details any) error {
+	_, err := s.audit.Append(audit.Entry{
+		Tenant:  principal.Tenant,
+		Actor:   principal.Subject,
+		Action:  action,
+		RunID:   runID,
+		Details: audit.Details(details),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit entry %s for run %s: %v", action, runID, err)
+	}
+	return err
+}
+
+// maxAuditedError bounds the part of an error answer kept to audit it
+const maxAuditedError = 4 << 10
+
+// attemptRecorder keeps the status and the error answered to a request, so the attempts that
+// fail are audited along with the ones that succeed
+type attemptRecorder struct {
+	*statusRecorder
+	body bytes.Buffer
+}
+
+func newAttemptRecorder(w http.ResponseWriter) *attemptRecorder {
+	return &attemptRecorder{statusRecorder: &statusRecorder{ResponseWriter: w, code: http.StatusOK}}
+}
+
+func (r *attemptRecorder) Write(p []byte) (int, error) {
+	if r.code >= http.StatusBadRequest && r.body.Len() < maxAuditedError {
+		r.body.Write(p[:min(len(p), maxAuditedError-r.body.Len())])
+	}
+	return r.statusRecorder.Write(p)
+}
+
+// recordFailure audits a failed attempt of the caller with the answer kept by recorder. The
+// caller already got its answer, an entry that cannot be written is only logged.
+func (s *Server) recordFailure(r *http.Request, action, runID string, recorder *attemptRecorder) {
+	var response APIResponse
+	if json.Unmarshal(recorder.body.Bytes(), &response) != nil || response.Error == "" {
+		response.Error = http.StatusText(recorder.code)
+	}
+	principal, _ := auth.FromContext(r.Context())
+	s.appendAudit(principal, action, runID, map[string]any{"status": recorder.code, "error": response.Error})
+}
+
+// handleListAudit lists the audit entries of the caller tenant, oldest first, filtered by the
+// action, run_id, actor, since and until query parameters
+func (s *Server) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.FromContext(r.Context())
+	query := r.URL.Query()
+	filter := audit.Filter{
+		Tenant: principal.Tenant,
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		RunID:  query.Get("run_id"),
+	}
+
+	var err error
+	if filter.Since, err = parseTime(query.Get("since")); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{Success: false, Error: "since " + err.Error()})
+		return
+	}
+	if filter.Until, err = parseTime(query.Get("until")); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{Success: false, Error: "until " + err.Error()})
+		return
+	}
+
+	entries, err := s.audit.Query(filter)
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error reading audit log: %v", err),
+		})
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+// AuditVerification is the result of an audit chain verification
+// Head: Hash of the last entry, to be kept elsewhere so a truncated log can be detected
+type AuditVerification struct {
+	Entries int64  `json:"entries"`
+	Valid   bool   `json:"valid"`
+	Head    string `json:"head,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleVerifyAudit verifies the hash chain of the whole audit log
+func (s *Server) handleVerifyAudit(w http.ResponseWriter, r *http.Request) {
+	verification, err := VerifyAudit(s.audit)
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error reading audit log: %v", err),
+		})
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    verification,
+	})
+}
+
+// VerifyAudit verifies the chain of log, a broken chain is reported in the verification and
+// only read failures are returned as errors
+func VerifyAudit(log *audit.Log) (AuditVerification, error) {
+	entries, err := log.Verify()
+	var chainErr *audit.ChainError
+	if errors.As(err, &chainErr) {
+		return AuditVerification{Entries: entries, Valid: false, Error: chainErr.Error()}, nil
+	}
+	if err != nil {
+		return AuditVerification{}, err
+	}
+
+	return AuditVerification{Entries: entries, Valid: true, Head: log.Head()}, nil
+}
+
+// parseTime reads an RFC 3339 time or a YYYY-MM-DD date, an empty value is the zero time
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be an RFC 3339 time or a YYYY-MM-DD date")
+	}
+	return t, nil
+}
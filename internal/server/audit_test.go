@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/report"
+)
+
+func TestAuditTrail(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Tenants = []config.Tenant{{Name: "retail"}, {Name: "corporate"}}
+		cfg.Auth.APIKeys = []auth.APIKey{
+			{Name: "retail-admin", SHA256: auth.HashKey("retail"), Tenant: "retail", Roles: []string{auth.RoleAdmin}},
+			{Name: "corporate-admin", SHA256: auth.HashKey("corporate"), Tenant: "corporate", Roles: []string{auth.RoleAdmin}},
+		}
+	})
+	serve := func(req *http.Request, key string) *httptest.ResponseRecorder {
+		req.Header.Set(auth.APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	var reconciled struct {
+		RunID string `json:"run_id"`
+	}
+	json.NewDecoder(serve(reconcileRequest(t), "retail").Body).Decode(&reconciled)
+	// Callers approve under their own name only
+	req := httptest.NewRequest(http.MethodPost, "/api/runs/"+reconciled.RunID+"/signoff?approver=Jane", nil)
+	if rec := serve(req, "retail"); rec.Code != http.StatusForbidden {
+		t.Errorf("sign-off for another approver status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/api/runs/"+reconciled.RunID+"/signoff?reviewer=Jane", nil)
+	if rec := serve(req, "retail"); rec.Code != http.StatusCreated {
+		t.Fatalf("sign-off status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var listed struct {
+		Data []audit.Entry `json:"data"`
+	}
+	json.NewDecoder(serve(httptest.NewRequest(http.MethodGet, "/api/audit?run_id="+reconciled.RunID, nil), "retail").Body).Decode(&listed)
+	wantActions := []string{audit.ActionUpload, audit.ActionRun, audit.ActionSignOffFailed, audit.ActionSignOff}
+	if len(listed.Data) != len(wantActions) {
+		t.Fatalf("got %d audit entries, want %d", len(listed.Data), len(wantActions))
+	}
+	for i, entry := range listed.Data {
+		if entry.Action != wantActions[i] || entry.Actor != "retail-admin" || entry.Tenant != "retail" {
+			t.Errorf("entry %d = %s by %s of %s, want %s by retail-admin of retail", i, entry.Action, entry.Actor, entry.Tenant, wantActions[i])
+		}
+	}
+
+	var signOff report.SignOff
+	if json.Unmarshal(listed.Data[len(listed.Data)-1].Details, &signOff) != nil || signOff.Approver != "retail-admin" || signOff.Reviewer != "Jane" {
+		t.Errorf("sign-off details = %s, want approved by retail-admin", listed.Data[len(listed.Data)-1].Details)
+	}
+
+	json.NewDecoder(serve(httptest.NewRequest(http.MethodGet, "/api/audit", nil), "corporate").Body).Decode(&listed)
+	if len(listed.Data) != 0 {
+		t.Errorf("corporate sees %d audit entries of retail", len(listed.Data))
+	}
+
+	var verified struct {
+		Data AuditVerification `json:"data"`
+	}
+	json.NewDecoder(serve(httptest.NewRequest(http.MethodGet, "/api/audit/verify", nil), "retail").Body).Decode(&verified)
+	if !verified.Data.Valid || verified.Data.Entries != 4 || verified.Data.Head == "" {
+		t.Errorf("verification = %+v, want a valid chain of 4 entries", verified.Data)
+	}
+}
+
+func TestAuditFailures(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Server.AuditKey = "0123456789abcdef"
+	})
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Rejected attempts are audited with the answer they got
+	if rec := serve(reconcileRequest(t, "matches", "some")); rec.Code != http.StatusBadRequest {
+		t.Fatalf("reconcile status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	entries, err := srv.audit.Query(audit.Filter{Action: audit.ActionRunFailed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var details struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	}
+	if len(entries) != 1 || json.Unmarshal(entries[0].Details, &details) != nil || details.Status != http.StatusBadRequest || details.Error != "matches must be one of all, imperfect or none" || !entries[0].Keyed {
+		t.Errorf("audit entries = %+v", entries)
+	}
+
+	// A run that cannot be audited is rolled back
+	if err := os.Remove(srv.cfg.Server.AuditLog); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(srv.cfg.Server.AuditLog, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if rec := serve(reconcileRequest(t)); rec.Code != http.StatusInternalServerError {
+		t.Errorf("reconcile status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	runs, _ := srv.runsOf("")
+	if list, err := runs.List(); err != nil || len(list) != 0 {
+		t.Errorf("runs = %+v, %v, want the unaudited run rolled back", list, err)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+)
+
+// authenticate lets the request through to next once its caller is verified and holds one of roles,
+// the principal is stored in the request context. Every request goes through when authentication is disabled.
+func (s *Server) authenticate(next http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authenticator == nil {
+			next(w, r)
+			return
+		}
+
+		principal, err := s.authenticator.Authenticate(r)
+		if err != nil {
+			message := "Authentication required"
+			if !errors.Is(err, auth.ErrNoCredentials) {
+				// The reason stays in the logs, callers only learn the credentials were refused
+				log.Printf("Authentication failed for %s %s: %v", r.Method, r.URL.Path, err)
+				message = "Invalid credentials"
+			}
+			if s.cfg.Auth.JWT != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="reconciliation"`)
+			}
+			sendJSONResponse(w, http.StatusUnauthorized, APIResponse{
+				Success: false,
+				Error:   message,
+			})
+			return
+		}
+
+		if err := s.authorize(principal, roles); err != nil {
+			sendJSONResponse(w, http.StatusForbidden, APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		next(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	}
+}
+
+// authorize checks an authenticated principal belongs to a known tenant and holds one of roles when any is given
+func (s *Server) authorize(principal auth.Principal, roles []string) error {
+	// The tenant names directories, it must be valid and configured when tenants are. Callers
+	// without a tenant would reach the default tenant, which is not one of them.
+	if principal.Tenant == "" && len(s.cfg.Tenants) > 0 {
+		return errors.New("A tenant is required")
+	}
+	if principal.Tenant != "" {
+		_, known := s.cfg.Tenant(principal.Tenant)
+		if !config.ValidTenantName(principal.Tenant) || (len(s.cfg.Tenants) > 0 && !known) {
+			return errors.New("Unknown tenant")
+		}
+	}
+
+	if len(roles) > 0 && !principal.HasAnyRole(roles...) {
+		return errors.New("Insufficient role for this operation")
+	}
+	return nil
+}
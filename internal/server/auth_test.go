@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+)
+
+func TestAuthentication(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.APIKeys = []auth.APIKey{
+			{Name: "nightly", SHA256: auth.HashKey("nightly-key"), Roles: []string{"uploader"}},
+			{Name: "auditor", SHA256: auth.HashKey("auditor-key"), Roles: []string{"reviewer"}},
+		}
+	})
+	srv.handle("GET /api/test/reviewers", func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := auth.FromContext(r.Context())
+		sendJSONResponse(w, http.StatusOK, APIResponse{Success: true, Data: principal.Subject})
+	}, "reviewer")
+
+	tests := []struct {
+		name      string
+		path      string
+		key       string
+		wantCode  int
+		wantError string
+	}{
+		{name: "missing credentials", path: "/api/runs", wantCode: http.StatusUnauthorized, wantError: "Authentication required"},
+		{name: "unknown key", path: "/api/runs", key: "other", wantCode: http.StatusUnauthorized, wantError: "Invalid credentials"},
+		{name: "valid key", path: "/api/runs", key: "nightly-key", wantCode: http.StatusOK},
+		{name: "missing role", path: "/api/test/reviewers", key: "nightly-key", wantCode: http.StatusForbidden, wantError: "Insufficient role for this operation"},
+		{name: "role held", path: "/api/test/reviewers", key: "auditor-key", wantCode: http.StatusOK},
+		{name: "probes stay open", path: "/healthz", wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			var response APIResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			if response.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", response.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,532 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+	"github.com/arham-abiyan/reconciliation/pkg"
+	reconciliationv1 "github.com/arham-abiyan/reconciliation/proto/reconciliation/v1"
+)
+
+// grpcBatchSize is the number of records of a result message, keeping messages well below the
+// default 4 MB limit of gRPC
+const grpcBatchSize = 500
+
+// grpcRoles holds the roles allowed to call each gRPC method, as for the matching HTTP routes
+var grpcRoles = map[string][]string{
+	reconciliationv1.ReconciliationService_Reconcile_FullMethodName: {auth.RoleUploader, auth.RoleAdmin},
+}
+
+// grpcService serves the reconciliation service over gRPC
+type grpcService struct {
+	reconciliationv1.UnimplementedReconciliationServiceServer
+	s *Server
+}
+
+// GRPCServer returns a gRPC server exposing the reconciliation service. It shares the
+// authentication, reconciliation slots, run stores and audit log of the HTTP server.
+func (s *Server) GRPCServer() *grpc.Server {
+	srv := grpc.NewServer(grpc.StreamInterceptor(s.authenticateStream))
+	reconciliationv1.RegisterReconciliationServiceServer(srv, &grpcService{s: s})
+	return srv
+}
+
+// authenticateStream lets a stream through once its caller is verified from the x-api-key or
+// authorization metadata, the principal is stored in the stream context
+func (s *Server) authenticateStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if s.authenticator == nil {
+		return handler(srv, stream)
+	}
+
+	// Authenticators read HTTP headers, the metadata holds the same values
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	r := &http.Request{Header: http.Header{}}
+	for _, name := range []string{auth.APIKeyHeader, "Authorization"} {
+		for _, value := range md.Get(name) {
+			r.Header.Add(name, value)
+		}
+	}
+
+	principal, err := s.authenticator.Authenticate(r)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoCredentials) {
+			return status.Error(codes.Unauthenticated, "Authentication required")
+		}
+		log.Printf("Authentication failed for %s: %v", info.FullMethod, err)
+		return status.Error(codes.Unauthenticated, "Invalid credentials")
+	}
+	if err := s.authorize(principal, grpcRoles[info.FullMethod]); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return handler(srv, &principalStream{ServerStream: stream, ctx: auth.NewContext(stream.Context(), principal)})
+}
+
+// principalStream is a server stream whose context holds the authenticated principal
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (p *principalStream) Context() context.Context {
+	return p.ctx
+}
+
+// Reconcile reconciles the records streamed by the client, stores the run and streams the results back
+func (g *grpcService) Reconcile(stream grpc.BidiStreamingServer[reconciliationv1.ReconcileRequest, reconciliationv1.ReconcileResponse]) (err error) {
+	s := g.s
+	principal, _ := auth.FromContext(stream.Context())
+
+	// Rejected and failed attempts are audited as well as the stored runs
+	var runID string
+	defer func() {
+		if runID == "" && err != nil {
+			st := status.Convert(err)
+			s.appendAudit(principal, audit.ActionRunFailed, "", map[string]any{"code": st.Code().String(), "error": st.Message()})
+		}
+	}()
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) || (err == nil && first.GetOptions() == nil) {
+		return status.Error(codes.InvalidArgument, "The first message must hold the options")
+	}
+	if err != nil {
+		return err
+	}
+	options := first.GetOptions()
+	if err := pkg.ValidateDates(options.StartDate, options.EndDate); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	opts, err := s.grpcOptions(options)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Tenants only reconcile their own banks
+	var allowedBanks []string
+	if tenant, ok := s.cfg.Tenant(principal.Tenant); ok {
+		allowedBanks = tenant.Banks
+	}
+
+	// The records are checksummed per source, as uploaded files are, to prove which data the run used
+	var systemTransactions []model.Transaction
+	var bankStatements []model.BankStatement
+	var banks []string
+	checksums := map[string]hash.Hash{}
+	checksum := func(source string, batch proto.Message) {
+		if checksums[source] == nil {
+			checksums[source] = sha256.New()
+		}
+		data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(batch)
+		checksums[source].Write(data)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Records are held until a slot is free, a stream cannot hold more than the configured maximum
+		if records := len(systemTransactions) + len(bankStatements) + len(req.GetSystem().GetTransactions()) + len(req.GetBank().GetStatements()); records > s.cfg.Server.MaxStreamRecords {
+			return status.Errorf(codes.ResourceExhausted, "The stream holds more than %d records", s.cfg.Server.MaxStreamRecords)
+		}
+
+		switch payload := req.Payload.(type) {
+		case *reconciliationv1.ReconcileRequest_System:
+			for _, tx := range payload.System.Transactions {
+				transaction, err := transactionFromProto(tx)
+				if err != nil {
+					return status.Errorf(codes.InvalidArgument, "System transaction %d: %v", len(systemTransactions)+1, err)
+				}
+				systemTransactions = append(systemTransactions, transaction)
+			}
+			checksum("", payload.System)
+		case *reconciliationv1.ReconcileRequest_Bank:
+			bank := payload.Bank.Bank
+			if bank == "" {
+				return status.Error(codes.InvalidArgument, "Bank batches must name their bank")
+			}
+			// Bank names end up in stored runs and the exceptions index, they are checked as the bank form field is
+			if !bankNamePattern.MatchString(bank) || reconciliation.BankName(bank) != bank {
+				return status.Errorf(codes.InvalidArgument, "Invalid bank %q: use letters, digits and -", bank)
+			}
+			if len(allowedBanks) > 0 && !slices.Contains(allowedBanks, bank) {
+				return status.Errorf(codes.PermissionDenied, "Bank %s is not available to tenant %s", bank, principal.Tenant)
+			}
+			for i, stmt := range payload.Bank.Statements {
+				statement, err := statementFromProto(stmt, bank)
+				if err != nil {
+					return status.Errorf(codes.InvalidArgument, "Statement %d of bank %s: %v", i+1, bank, err)
+				}
+				bankStatements = append(bankStatements, statement)
+			}
+			if !slices.Contains(banks, bank) {
+				banks = append(banks, bank)
+			}
+			checksum(bank, payload.Bank)
+		default:
+			return status.Error(codes.InvalidArgument, "Only the first message may hold the options")
+		}
+	}
+	if len(banks) == 0 {
+		return status.Error(codes.InvalidArgument, "At least one bank batch is required")
+	}
+
+	// Wait for a free slot, the stream is dropped if the client goes away first
+	if err := s.acquire(stream.Context()); err != nil {
+		return status.Error(codes.Unavailable, "Server is busy, try again later")
+	}
+	svc := reconciliation.New(nil, "", options.StartDate, options.EndDate, opts...)
+	result := svc.ReconcileRecords(systemTransactions, bankStatements)
+	s.release()
+	s.metrics.observeReconciliation(result, nil)
+
+	run := model.Run{
+		Tenant:    principal.Tenant,
+		CreatedBy: principal.Subject,
+		Params: model.RunParams{
+			StartDate: options.StartDate,
+			EndDate:   options.EndDate,
+			BankFiles: banks,
+		},
+		Result: result,
+	}
+	if h := checksums[""]; h != nil {
+		run.Params.InputFiles = append(run.Params.InputFiles, model.InputFile{Role: "system", Name: "system", SHA256: hex.EncodeToString(h.Sum(nil))})
+	}
+	for _, bank := range banks {
+		run.Params.InputFiles = append(run.Params.InputFiles, model.InputFile{Role: "bank", Name: bank, SHA256: hex.EncodeToString(checksums[bank].Sum(nil))})
+	}
+
+	runs, err := s.runsOf(principal.Tenant)
+	if err == nil {
+		err = runs.Save(&run)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "Error storing reconciliation run: %v", err)
+	}
+	// A run that cannot be audited is not kept, every stored run can be traced in the audit log
+	err = s.appendAudit(principal, audit.ActionUpload, run.ID, map[string]any{"files": run.Params.InputFiles})
+	if err == nil {
+		err = s.appendAudit(principal, audit.ActionRun, run.ID, map[string]any{
+			"params": run.Params,
+			"options": map[string]any{
+				"transfer_window_days": options.TransferWindowDays,
+				"matches":              options.Matches,
+				"fee_rules":            options.FeeRules,
+			},
+			"matched":       result.Matched,
+			"unmatched":     result.Unmatched,
+			"discrepancies": result.Discrepancies,
+		})
+	}
+	if err != nil {
+		if err := runs.Delete(run.ID); err != nil {
+			log.Printf("Failed to roll back unaudited run %s: %v", run.ID, err)
+		}
+		return status.Errorf(codes.Internal, "Error recording audit entry: %v", err)
+	}
+	s.notify(run)
+	runID = run.ID
+
+	// Runs are stored as they are, masking only applies to what callers receive
+	return sendGRPCResults(stream, run.ID, s.maskerOf(principal).Result(result))
+}
+
+// grpcOptions returns the reconciliation options of a gRPC request, the settings of the server
+// apply to the options left unset
+func (s *Server) grpcOptions(options *reconciliationv1.ReconcileOptions) ([]reconciliation.Option, error) {
+	opts := []reconciliation.Option{
+		reconciliation.WithTransferWindow(s.cfg.Matching.TransferWindowDays),
+		reconciliation.WithFeeRules(s.cfg.FeeRules()...),
+		reconciliation.WithMatches(s.cfg.Output.Matches),
+	}
+	if options.TransferWindowDays != nil {
+		if *options.TransferWindowDays < 0 {
+			return nil, errors.New("transfer_window_days must be a non-negative number")
+		}
+		opts = append(opts, reconciliation.WithTransferWindow(int(*options.TransferWindowDays)))
+	}
+	if matches := options.Matches; matches != "" {
+		if matches != reconciliation.MatchesAll && matches != reconciliation.MatchesImperfect && matches != reconciliation.MatchesNone {
+			return nil, errors.New("matches must be one of all, imperfect or none")
+		}
+		opts = append(opts, reconciliation.WithMatches(matches))
+	}
+
+	rules := make([]reconciliation.FeeRule, 0, len(options.FeeRules))
+	for _, rule := range options.FeeRules {
+		feeRule := reconciliation.FeeRule{Bank: rule.Bank, Type: rule.Type, Flat: rule.Flat, Percent: rule.Percent, Min: rule.Min, Cap: rule.Cap}
+		for _, tier := range rule.Tiers {
+			feeRule.Tiers = append(feeRule.Tiers, reconciliation.FeeTier{UpTo: tier.UpTo, Flat: tier.Flat, Percent: tier.Percent})
+		}
+		if err := feeRule.Validate(); err != nil {
+			return nil, err
+		}
+		rules = append(rules, feeRule)
+	}
+	opts = append(opts, reconciliation.WithFeeRules(rules...))
+
+	balances := make([]model.StatementBalance, 0, len(options.Balances))
+	for _, balance := range options.Balances {
+		balances = append(balances, model.StatementBalance{Bank: balance.Bank, Opening: balance.Opening, Closing: balance.Closing})
+	}
+	opts = append(opts, reconciliation.WithStatementBalances(balances...))
+
+	return opts, nil
+}
+
+// sendGRPCResults streams the records of result in batches, then its summary
+func sendGRPCResults(stream grpc.BidiStreamingServer[reconciliationv1.ReconcileRequest, reconciliationv1.ReconcileResponse], runID string, result model.ReconcileResponse) error {
+	for _, batch := range chunks(result.UnmatchedSystem) {
+		if err := stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_UnmatchedSystem{
+			UnmatchedSystem: &reconciliationv1.SystemBatch{Transactions: mapProto(batch, transactionToProto)},
+		}}); err != nil {
+			return err
+		}
+	}
+
+	banks := make([]string, 0, len(result.UnmatchedByBank))
+	for bank := range result.UnmatchedByBank {
+		banks = append(banks, bank)
+	}
+	sort.Strings(banks)
+	for _, bank := range banks {
+		for _, batch := range chunks(result.UnmatchedByBank[bank]) {
+			if err := stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_UnmatchedBank{
+				UnmatchedBank: &reconciliationv1.BankBatch{Bank: bank, Statements: mapProto(batch, statementToProto)},
+			}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, batch := range chunks(result.Matches) {
+		if err := stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_Matches{
+			Matches: &reconciliationv1.MatchBatch{Matches: mapProto(batch, matchToProto)},
+		}}); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range chunks(result.DiscrepantMatches) {
+		if err := stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_DiscrepantMatches{
+			DiscrepantMatches: &reconciliationv1.MatchBatch{Matches: mapProto(batch, matchToProto)},
+		}}); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range chunks(result.Duplicates) {
+		if err := stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_Duplicates{
+			Duplicates: &reconciliationv1.DuplicateBatch{Duplicates: mapProto(batch, duplicateToProto)},
+		}}); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range chunks(result.Reversals) {
+		if err := stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_Reversals{
+			Reversals: &reconciliationv1.ReversalBatch{Reversals: mapProto(batch, reversalToProto)},
+		}}); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range chunks(result.InternalTransfers) {
+		if err := stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_InternalTransfers{
+			InternalTransfers: &reconciliationv1.TransferBatch{Transfers: mapProto(batch, transferToProto)},
+		}}); err != nil {
+			return err
+		}
+	}
+
+	summary := &reconciliationv1.Summary{
+		RunId:             runID,
+		RecordsParsed:     int32(result.RecordsParsed),
+		TotalProcessed:    int32(result.TotalProcessed),
+		Matched:           int32(result.Matched),
+		Unmatched:         int32(result.Unmatched),
+		Fees:              result.Fees,
+		Discrepancies:     result.Discrepancies,
+		Duplicates:        int32(len(result.Duplicates)),
+		Reversals:         int32(len(result.Reversals)),
+		InternalTransfers: int32(len(result.InternalTransfers)),
+	}
+	for _, bank := range result.ByBank {
+		summary.ByBank = append(summary.ByBank, &reconciliationv1.BankSummary{
+			Bank:          bank.Bank,
+			Matched:       int32(bank.Matched),
+			Unmatched:     int32(bank.Unmatched),
+			Fees:          bank.Fees,
+			Discrepancies: bank.Discrepancies,
+		})
+	}
+	for _, check := range result.BalanceChecks {
+		summary.BalanceChecks = append(summary.BalanceChecks, &reconciliationv1.BalanceCheck{
+			Bank:       check.Bank,
+			Opening:    check.Opening,
+			Closing:    check.Closing,
+			LinesTotal: check.LinesTotal,
+			Difference: check.Difference,
+			Balanced:   check.Balanced,
+		})
+	}
+
+	return stream.Send(&reconciliationv1.ReconcileResponse{Payload: &reconciliationv1.ReconcileResponse_Summary{Summary: summary}})
+}
+
+// transactionFromProto checks a system transaction as rows of system files are checked
+func transactionFromProto(tx *reconciliationv1.Transaction) (model.Transaction, error) {
+	switch {
+	case tx.TrxId == "":
+		return model.Transaction{}, errors.New("missing transaction ID")
+	case tx.Type != "DEBIT" && tx.Type != "CREDIT":
+		return model.Transaction{}, fmt.Errorf("invalid type %q, expected DEBIT or CREDIT", tx.Type)
+	case tx.TransactionTime == nil:
+		return model.Transaction{}, errors.New("missing transaction time")
+	}
+	return model.Transaction{
+		TrxID:           tx.TrxId,
+		Amount:          tx.Amount,
+		Type:            tx.Type,
+		TransactionTime: tx.TransactionTime.AsTime(),
+		Description:     tx.Description,
+	}, nil
+}
+
+// statementFromProto checks a bank statement line as rows of bank files are checked, the sign
+// of the amount gives its type
+func statementFromProto(stmt *reconciliationv1.BankStatement, bank string) (model.BankStatement, error) {
+	switch {
+	case stmt.UniqueIdentifier == "":
+		return model.BankStatement{}, errors.New("missing unique identifier")
+	case stmt.Date == nil:
+		return model.BankStatement{}, errors.New("missing date")
+	}
+	trxType := "CREDIT"
+	if stmt.Amount < 0 {
+		trxType = "DEBIT"
+	}
+	return model.BankStatement{
+		UniqueIdentifier: stmt.UniqueIdentifier,
+		Amount:           math.Abs(stmt.Amount),
+		Type:             trxType,
+		Date:             stmt.Date.AsTime(),
+		Bank:             bank,
+		Description:      stmt.Description,
+	}, nil
+}
+
+func transactionToProto(tx model.Transaction) *reconciliationv1.Transaction {
+	return &reconciliationv1.Transaction{
+		TrxId:           tx.TrxID,
+		Amount:          tx.Amount,
+		Type:            tx.Type,
+		TransactionTime: timestamppb.New(tx.TransactionTime),
+		Description:     tx.Description,
+	}
+}
+
+// statementToProto signs the amount of debits back, as in statement files
+func statementToProto(stmt model.BankStatement) *reconciliationv1.BankStatement {
+	amount := stmt.Amount
+	if stmt.Type == "DEBIT" {
+		amount = -amount
+	}
+	return &reconciliationv1.BankStatement{
+		UniqueIdentifier: stmt.UniqueIdentifier,
+		Amount:           amount,
+		Date:             timestamppb.New(stmt.Date),
+		Description:      stmt.Description,
+	}
+}
+
+func matchToProto(pair model.MatchedPair) *reconciliationv1.MatchedPair {
+	return &reconciliationv1.MatchedPair{
+		System:        transactionToProto(pair.System),
+		Statement:     statementToProto(pair.Statement),
+		Bank:          pair.Bank,
+		AmountDelta:   pair.AmountDelta,
+		Fee:           pair.Fee,
+		Residual:      pair.Residual,
+		DateDeltaDays: pair.DateDeltaDays,
+		Rule:          pair.Rule,
+	}
+}
+
+func duplicateToProto(duplicate model.Duplicate) *reconciliationv1.Duplicate {
+	return &reconciliationv1.Duplicate{
+		Source:       duplicate.Source,
+		Reason:       duplicate.Reason,
+		Key:          duplicate.Key,
+		Transactions: mapProto(duplicate.Transactions, transactionToProto),
+		Statements:   mapProto(duplicate.Statements, statementToProto),
+	}
+}
+
+func reversalToProto(reversal model.Reversal) *reconciliationv1.Reversal {
+	return &reconciliationv1.Reversal{
+		Source:       reversal.Source,
+		Rule:         reversal.Rule,
+		Amount:       reversal.Amount,
+		Transactions: mapProto(reversal.Transactions, transactionToProto),
+		Statements:   mapProto(reversal.Statements, statementToProto),
+	}
+}
+
+func transferToProto(transfer model.InternalTransfer) *reconciliationv1.InternalTransfer {
+	return &reconciliationv1.InternalTransfer{
+		FromBank: transfer.From.Bank,
+		From:     statementToProto(transfer.From),
+		ToBank:   transfer.To.Bank,
+		To:       statementToProto(transfer.To),
+	}
+}
+
+// chunks splits values in batches of grpcBatchSize
+func chunks[T any](values []T) [][]T {
+	var batches [][]T
+	for len(values) > grpcBatchSize {
+		batches = append(batches, values[:grpcBatchSize])
+		values = values[grpcBatchSize:]
+	}
+	if len(values) > 0 {
+		batches = append(batches, values)
+	}
+	return batches
+}
+
+// mapProto converts every value with f
+func mapProto[T, P any](values []T, f func(T) P) []P {
+	converted := make([]P, 0, len(values))
+	for _, value := range values {
+		converted = append(converted, f(value))
+	}
+	return converted
+}
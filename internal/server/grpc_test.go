@@ -0,0 +1,285 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	reconciliationv1 "github.com/arham-abiyan/reconciliation/proto/reconciliation/v1"
+)
+
+// newGRPCClient serves the gRPC service of srv in memory and returns a client of it
+func newGRPCClient(t *testing.T, srv *Server) reconciliationv1.ReconciliationServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	grpcSrv := srv.GRPCServer()
+	go grpcSrv.Serve(listener)
+	t.Cleanup(grpcSrv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return reconciliationv1.NewReconciliationServiceClient(conn)
+}
+
+// reconcileStream sends requests on a new Reconcile stream and returns the responses received
+// until the server closes it
+func reconcileStream(ctx context.Context, client reconciliationv1.ReconciliationServiceClient, requests ...*reconciliationv1.ReconcileRequest) ([]*reconciliationv1.ReconcileResponse, error) {
+	stream, err := client.Reconcile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range requests {
+		if err := stream.Send(req); err != nil {
+			break
+		}
+	}
+	stream.CloseSend()
+
+	var responses []*reconciliationv1.ReconcileResponse
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return responses, nil
+		}
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, resp)
+	}
+}
+
+func optionsRequest(start, end string) *reconciliationv1.ReconcileRequest {
+	return &reconciliationv1.ReconcileRequest{Payload: &reconciliationv1.ReconcileRequest_Options{
+		Options: &reconciliationv1.ReconcileOptions{StartDate: start, EndDate: end},
+	}}
+}
+
+func systemRequest(transactions ...*reconciliationv1.Transaction) *reconciliationv1.ReconcileRequest {
+	return &reconciliationv1.ReconcileRequest{Payload: &reconciliationv1.ReconcileRequest_System{
+		System: &reconciliationv1.SystemBatch{Transactions: transactions},
+	}}
+}
+
+func bankRequest(bank string, statements ...*reconciliationv1.BankStatement) *reconciliationv1.ReconcileRequest {
+	return &reconciliationv1.ReconcileRequest{Payload: &reconciliationv1.ReconcileRequest_Bank{
+		Bank: &reconciliationv1.BankBatch{Bank: bank, Statements: statements},
+	}}
+}
+
+func TestGRPCReconcile(t *testing.T) {
+	srv := newTestServer(t, nil)
+	client := newGRPCClient(t, srv)
+	day := time.Date(2024, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	// The system transactions come in two batches, unmatched ones exceed a result batch
+	var unmatched []*reconciliationv1.Transaction
+	for i := 0; i < grpcBatchSize+10; i++ {
+		unmatched = append(unmatched, &reconciliationv1.Transaction{TrxId: fmt.Sprintf("U%d", i), Amount: 10, Type: "DEBIT", TransactionTime: timestamppb.New(day)})
+	}
+	responses, err := reconcileStream(context.Background(), client,
+		optionsRequest("2024-12-01", "2024-12-31"),
+		systemRequest(&reconciliationv1.Transaction{TrxId: "T1", Amount: 100000, Type: "CREDIT", TransactionTime: timestamppb.New(day)}),
+		systemRequest(unmatched...),
+		bankRequest("bank-a", &reconciliationv1.BankStatement{UniqueIdentifier: "T1", Amount: 100000, Date: timestamppb.New(day)}),
+		bankRequest("bank-a", &reconciliationv1.BankStatement{UniqueIdentifier: "B9", Amount: -25, Date: timestamppb.New(day)}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var unmatchedSystem, unmatchedBank, matches int
+	for _, resp := range responses[:len(responses)-1] {
+		unmatchedSystem += len(resp.GetUnmatchedSystem().GetTransactions())
+		unmatchedBank += len(resp.GetUnmatchedBank().GetStatements())
+		matches += len(resp.GetMatches().GetMatches())
+	}
+	if len(responses) != 5 || unmatchedSystem != grpcBatchSize+10 || unmatchedBank != 1 || matches != 1 {
+		t.Errorf("%d responses: %d unmatched system, %d unmatched bank, %d matches", len(responses), unmatchedSystem, unmatchedBank, matches)
+	}
+	if got := responses[2].GetUnmatchedBank().GetStatements(); len(got) != 1 || got[0].Amount != -25 {
+		t.Errorf("unmatched bank = %+v, debits must keep their sign", got)
+	}
+
+	summary := responses[len(responses)-1].GetSummary()
+	if summary == nil || summary.Matched != 1 || summary.Unmatched != grpcBatchSize+11 || summary.RunId == "" {
+		t.Fatalf("summary = %+v", summary)
+	}
+
+	// The run is stored as runs reconciled over HTTP are
+	runs, _ := srv.runsOf("")
+	run, err := runs.Get(summary.RunId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Params.BankFiles) != 1 || len(run.Params.InputFiles) != 2 || run.Params.InputFiles[1].Name != "bank-a" {
+		t.Errorf("params = %+v", run.Params)
+	}
+}
+
+func TestGRPCReconcileExceptions(t *testing.T) {
+	client := newGRPCClient(t, newTestServer(t, nil))
+	day := timestamppb.New(time.Date(2024, 12, 2, 10, 0, 0, 0, time.UTC))
+	tx := func(id string, amount float64, trxType string) *reconciliationv1.Transaction {
+		return &reconciliationv1.Transaction{TrxId: id, Amount: amount, Type: trxType, TransactionTime: day}
+	}
+	stmt := func(id string, amount float64) *reconciliationv1.BankStatement {
+		return &reconciliationv1.BankStatement{UniqueIdentifier: id, Amount: amount, Date: day}
+	}
+
+	// Every record of the result is streamed, as the HTTP API lists it, not only counted in the summary
+	responses, err := reconcileStream(context.Background(), client,
+		optionsRequest("2024-12-01", "2024-12-31"),
+		systemRequest(tx("T1", 100000, "CREDIT"), tx("T2", 200, "CREDIT"), tx("T2-REV", 200, "DEBIT"), tx("D1", 50, "CREDIT"), tx("D1", 50, "CREDIT")),
+		bankRequest("bank-a", stmt("T1", 99000), stmt("X1", -500)),
+		bankRequest("bank-b", stmt("X2", 500)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var discrepant, duplicates, reversals []string
+	var transfers []*reconciliationv1.InternalTransfer
+	for _, resp := range responses {
+		for _, pair := range resp.GetDiscrepantMatches().GetMatches() {
+			discrepant = append(discrepant, pair.System.TrxId)
+		}
+		for _, duplicate := range resp.GetDuplicates().GetDuplicates() {
+			duplicates = append(duplicates, duplicate.Key)
+		}
+		for _, reversal := range resp.GetReversals().GetReversals() {
+			reversals = append(reversals, reversal.Transactions[1].TrxId)
+		}
+		transfers = append(transfers, resp.GetInternalTransfers().GetTransfers()...)
+	}
+	summary := responses[len(responses)-1].GetSummary()
+	if len(discrepant) != 1 || discrepant[0] != "T1" {
+		t.Errorf("discrepant matches = %v, want T1", discrepant)
+	}
+	if len(duplicates) != int(summary.GetDuplicates()) || len(duplicates) != 1 || duplicates[0] != "D1" {
+		t.Errorf("duplicates = %v, summary counts %d", duplicates, summary.GetDuplicates())
+	}
+	if len(reversals) != int(summary.GetReversals()) || len(reversals) != 1 || reversals[0] != "T2-REV" {
+		t.Errorf("reversals = %v, summary counts %d", reversals, summary.GetReversals())
+	}
+	if len(transfers) != int(summary.GetInternalTransfers()) || len(transfers) != 1 ||
+		transfers[0].FromBank != "bank-a" || transfers[0].From.Amount != -500 || transfers[0].ToBank != "bank-b" {
+		t.Errorf("internal transfers = %v, summary counts %d", transfers, summary.GetInternalTransfers())
+	}
+}
+
+func TestGRPCReconcileInvalid(t *testing.T) {
+	client := newGRPCClient(t, newTestServer(t, nil))
+	day := timestamppb.New(time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		requests []*reconciliationv1.ReconcileRequest
+	}{
+		{"no options", []*reconciliationv1.ReconcileRequest{bankRequest("bank-a")}},
+		{"invalid dates", []*reconciliationv1.ReconcileRequest{optionsRequest("2024-12-31", "2024-12-01"), bankRequest("bank-a")}},
+		{"no bank", []*reconciliationv1.ReconcileRequest{optionsRequest("2024-12-01", "2024-12-31")}},
+		{"unnamed bank", []*reconciliationv1.ReconcileRequest{optionsRequest("2024-12-01", "2024-12-31"), bankRequest("")}},
+		{"invalid bank", []*reconciliationv1.ReconcileRequest{optionsRequest("2024-12-01", "2024-12-31"), bankRequest("../bank-a")}},
+		{"invalid type", []*reconciliationv1.ReconcileRequest{
+			optionsRequest("2024-12-01", "2024-12-31"),
+			systemRequest(&reconciliationv1.Transaction{TrxId: "T1", Type: "REFUND", TransactionTime: day}),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reconcileStream(context.Background(), client, tt.requests...)
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("error = %v, want InvalidArgument", err)
+			}
+		})
+	}
+}
+
+func TestGRPCReconcileTooManyRecords(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Server.MaxStreamRecords = 2
+	})
+	client := newGRPCClient(t, srv)
+	day := timestamppb.New(time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC))
+	tx := func(id string) *reconciliationv1.Transaction {
+		return &reconciliationv1.Transaction{TrxId: id, Amount: 10, Type: "CREDIT", TransactionTime: day}
+	}
+
+	_, err := reconcileStream(context.Background(), client,
+		optionsRequest("2024-12-01", "2024-12-31"),
+		systemRequest(tx("T1"), tx("T2")),
+		bankRequest("bank-a", &reconciliationv1.BankStatement{UniqueIdentifier: "T1", Amount: 10, Date: day}),
+	)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("error = %v, want ResourceExhausted", err)
+	}
+
+	// Up to the maximum, records are reconciled
+	if _, err := reconcileStream(context.Background(), client,
+		optionsRequest("2024-12-01", "2024-12-31"),
+		systemRequest(tx("T1")),
+		bankRequest("bank-a", &reconciliationv1.BankStatement{UniqueIdentifier: "T1", Amount: 10, Date: day}),
+	); err != nil {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestGRPCAuthentication(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.APIKeys = []auth.APIKey{
+			{Name: "nightly", SHA256: auth.HashKey("nightly-key"), Roles: []string{"uploader"}},
+			{Name: "auditor", SHA256: auth.HashKey("auditor-key"), Roles: []string{"reviewer"}},
+		}
+	})
+	client := newGRPCClient(t, srv)
+	day := timestamppb.New(time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC))
+	requests := []*reconciliationv1.ReconcileRequest{
+		optionsRequest("2024-12-01", "2024-12-31"),
+		bankRequest("bank-a", &reconciliationv1.BankStatement{UniqueIdentifier: "B1", Amount: 10, Date: day}),
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want codes.Code
+	}{
+		{"missing credentials", "", codes.Unauthenticated},
+		{"unknown key", "other", codes.Unauthenticated},
+		{"missing role", "auditor-key", codes.PermissionDenied},
+		{"uploader", "nightly-key", codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, auth.APIKeyHeader, tt.key)
+			}
+			_, err := reconcileStream(ctx, client, requests...)
+			if status.Code(err) != tt.want {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,579 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/report"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+	"github.com/arham-abiyan/reconciliation/internal/store"
+	"github.com/arham-abiyan/reconciliation/pkg"
+)
+
+const signOffAttachment = "signoff.pdf"
+
+// APIResponse wraps every JSON response
+// Data: The reconciliation result, or the stored run for the runs endpoints
+// RunID: ID of the stored run the result belongs to
+type APIResponse struct {
+	Success bool   `json:"success"`
+	Data    any    `json:"data"`
+	RunID   string `json:"run_id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func sendJSONResponse(w http.ResponseWriter, statusCode int, response APIResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) handleReconciliation(w http.ResponseWriter, r *http.Request) {
+	// Rejected and failed attempts are audited as well as the stored runs
+	var runID string
+	recorder := newAttemptRecorder(w)
+	w = recorder
+	defer func() {
+		if runID == "" {
+			s.recordFailure(r, audit.ActionRunFailed, "", recorder)
+		}
+	}()
+
+	if r.Method != http.MethodPost {
+		sendJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
+			Success: false,
+			Error:   "Method not allowed",
+		})
+		return
+	}
+
+	// Validate content type
+	if !strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Content-Type must be multipart/form-data",
+		})
+		return
+	}
+
+	// The job is started before the upload is read, so its progress can be followed from the start
+	job, finish, ok := s.startJob(w, r)
+	if !ok {
+		return
+	}
+	defer func() { finish(runID, recorder.code) }()
+
+	// Reports are rendered as JSON unless another format is asked through the query or the Accept header
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = report.FormatFromAccept(r.Header.Get("Accept"))
+	}
+	if format != "" && format != "json" && report.ContentType(format) == "" {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "format must be one of json, text, csv, xlsx, html or pdf",
+		})
+		return
+	}
+
+	// Limit request size
+	r.Body = http.MaxBytesReader(w, r.Body, s.cfg.Server.MaxUploadSize)
+	if err := r.ParseMultipartForm(s.cfg.Server.MaxUploadSize); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Request too large. Max size is %dMB", s.cfg.Server.MaxUploadSize>>20),
+		})
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	// Validate and parse dates
+	startDate := r.FormValue("start_date")
+	endDate := r.FormValue("end_date")
+	if err := pkg.ValidateDates(startDate, endDate); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Tenants only reconcile their own banks, and their uploads are kept apart
+	principal, _ := auth.FromContext(r.Context())
+	banks, code, err := s.requestBanks(principal.Tenant, r.MultipartForm.File["bank_files"], r.MultipartForm.Value["bank"])
+	if err != nil {
+		sendJSONResponse(w, code, APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+	blobs, ok := s.tenantUploads(w, r)
+	if !ok {
+		return
+	}
+
+	// Handle system transaction file
+	systemFile, systemHeader, err := r.FormFile("system_file")
+	if err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "System transaction file is required",
+		})
+		return
+	}
+	defer systemFile.Close()
+
+	if err := pkg.ValidateFile(systemHeader); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Failed to process system file",
+		})
+		return
+	}
+
+	// Stored files are named after their content, the reconciliation still reports the uploaded names
+	systemBlob, err := blobs.SaveFile(systemHeader)
+	if err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Failed to process system file",
+		})
+		return
+	}
+	defer blobs.Release(systemBlob.SHA256)
+	inputFiles := []model.InputFile{{Role: "system", Name: systemHeader.Filename, SHA256: systemBlob.SHA256}}
+
+	// Handle bank transaction files
+	bankFiles := r.MultipartForm.File["bank_files"]
+	if len(bankFiles) == 0 {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "At least one bank transaction file is required",
+		})
+		return
+	}
+
+	bankTransactions := make([]string, 0, len(bankFiles))
+	bankNames := make([]string, 0, len(bankFiles))
+	for i, fileHeader := range bankFiles {
+		if err := pkg.ValidateFile(fileHeader); err != nil {
+			sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Error processing bank file %s: %v", fileHeader.Filename, err),
+			})
+			return
+		}
+
+		bankBlob, err := blobs.SaveFile(fileHeader)
+		if err != nil {
+			sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Error processing bank file %s: %v", fileHeader.Filename, err),
+			})
+			return
+		}
+		defer blobs.Release(bankBlob.SHA256)
+		bankTransactions = append(bankTransactions, bankBlob.Path)
+		bankNames = append(bankNames, banks[i])
+		inputFiles = append(inputFiles, model.InputFile{Role: "bank", Name: fileHeader.Filename, SHA256: bankBlob.SHA256})
+	}
+
+	// Request fields override the configured defaults
+	opts := []reconciliation.Option{
+		reconciliation.WithTransferWindow(s.cfg.Matching.TransferWindowDays),
+		reconciliation.WithFeeRules(s.cfg.FeeRules()...),
+		reconciliation.WithMatches(s.cfg.Output.Matches),
+		reconciliation.WithInputNames(systemHeader.Filename, bankNames...),
+		reconciliation.WithOpener(blobs.Open),
+	}
+	if job != nil {
+		opts = append(opts, reconciliation.WithProgress(job.progress))
+	}
+	var balancesInput *model.InputFile
+	if window := r.FormValue("transfer_window_days"); window != "" {
+		days, err := strconv.Atoi(window)
+		if err != nil || days < 0 {
+			sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "transfer_window_days must be a non-negative number",
+			})
+			return
+		}
+		opts = append(opts, reconciliation.WithTransferWindow(days))
+	}
+	if matches := r.FormValue("matches"); matches != "" {
+		if matches != reconciliation.MatchesAll && matches != reconciliation.MatchesImperfect && matches != reconciliation.MatchesNone {
+			sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   "matches must be one of all, imperfect or none",
+			})
+			return
+		}
+		opts = append(opts, reconciliation.WithMatches(matches))
+	}
+	if feeRules := r.FormValue("fee_rules"); feeRules != "" {
+		rules, err := reconciliation.ParseFeeRules(strings.NewReader(feeRules))
+		if err != nil {
+			sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+			return
+		}
+		opts = append(opts, reconciliation.WithFeeRules(rules...))
+	}
+	if balancesFile, balancesHeader, err := r.FormFile("balances_file"); err == nil {
+		defer balancesFile.Close()
+
+		if err := pkg.ValidateFile(balancesHeader, ".csv", ".sta", ".mt940"); err != nil {
+			sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Error processing balances file %s: %v", balancesHeader.Filename, err),
+			})
+			return
+		}
+
+		hash := sha256.New()
+		balances, err := reconciliation.ParseBalances(io.TeeReader(balancesFile, hash), balancesHeader.Filename)
+		if err != nil {
+			sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Error processing balances file %s: %v", balancesHeader.Filename, err),
+			})
+			return
+		}
+		opts = append(opts, reconciliation.WithStatementBalances(balances...))
+		balancesInput = &model.InputFile{Role: "balances", Name: balancesHeader.Filename, SHA256: hex.EncodeToString(hash.Sum(nil))}
+	}
+
+	// Wait for a free slot, the request is dropped if the client goes away first
+	if err := s.acquire(r.Context()); err != nil {
+		sendJSONResponse(w, http.StatusServiceUnavailable, APIResponse{
+			Success: false,
+			Error:   "Server is busy, try again later",
+		})
+		return
+	}
+	svc := reconciliation.New(bankTransactions, systemBlob.Path, startDate, endDate, opts...)
+	result, err := svc.Reconcile()
+	s.release()
+	s.metrics.observeReconciliation(result, err)
+	if err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error reconcile transaction: %v", err),
+		})
+		return
+	}
+
+	run := model.Run{
+		Tenant:    principal.Tenant,
+		CreatedBy: principal.Subject,
+		Params: model.RunParams{
+			StartDate:  startDate,
+			EndDate:    endDate,
+			SystemFile: systemHeader.Filename,
+		},
+		Result: result,
+	}
+	for _, fileHeader := range bankFiles {
+		run.Params.BankFiles = append(run.Params.BankFiles, fileHeader.Filename)
+	}
+	run.Params.InputFiles = inputFiles
+	if balancesInput != nil {
+		run.Params.InputFiles = append(run.Params.InputFiles, *balancesInput)
+	}
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+	if err := runs.Save(&run); err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error storing reconciliation run: %v", err),
+		})
+		return
+	}
+	// A run that cannot be audited is not kept, every stored run can be traced in the audit log
+	audited := s.record(w, r, audit.ActionUpload, run.ID, map[string]any{"files": run.Params.InputFiles}) &&
+		s.record(w, r, audit.ActionRun, run.ID, map[string]any{
+			"params": run.Params,
+			"options": map[string]string{
+				"transfer_window_days": r.FormValue("transfer_window_days"),
+				"matches":              r.FormValue("matches"),
+				"fee_rules":            r.FormValue("fee_rules"),
+			},
+			"matched":       result.Matched,
+			"unmatched":     result.Unmatched,
+			"discrepancies": result.Discrepancies,
+		})
+	if !audited {
+		if err := runs.Delete(run.ID); err != nil {
+			log.Printf("Failed to roll back unaudited run %s: %v", run.ID, err)
+		}
+		return
+	}
+	s.notify(run)
+	w.Header().Set("X-Run-ID", run.ID)
+	runID = run.ID
+
+	// Runs are stored as they are, masking only applies to what callers receive
+	masked := s.maskerFor(r).Run(run)
+	if format != "" && format != "json" {
+		sendReport(w, format, masked)
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    &masked.Result,
+		RunID:   run.ID,
+	})
+}
+
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := runs.List()
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error listing reconciliation runs: %v", err),
+		})
+		return
+	}
+
+	// Results can be large, the list only describes the runs
+	summaries := make([]model.RunSummary, 0, len(list))
+	for _, run := range list {
+		summaries = append(summaries, run.RunSummary)
+	}
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    summaries,
+	})
+}
+
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.findRun(w, r)
+	if !ok {
+		return
+	}
+	run = s.maskerFor(r).Run(run)
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    &run,
+		RunID:   run.ID,
+	})
+}
+
+// handleRunReport downloads the report of a stored run, as HTML unless another format is asked
+// through the query or the Accept header
+func (s *Server) handleRunReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = report.FormatFromAccept(r.Header.Get("Accept"))
+	}
+	if format == "" {
+		format = report.FormatHTML
+	}
+	if report.ContentType(format) == "" {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "format must be one of html, text, csv, xlsx or pdf",
+		})
+		return
+	}
+
+	run, ok := s.findRun(w, r)
+	if !ok {
+		return
+	}
+
+	sendReport(w, format, s.maskerFor(r).Run(run))
+}
+
+// handleCreateSignOff renders the PDF sign-off report of a stored run with the names given in the form,
+// attaches it to the run and returns it. A new sign-off replaces the previous one. Once
+// authentication is enabled, the approver is the caller.
+func (s *Server) handleCreateSignOff(w http.ResponseWriter, r *http.Request) {
+	// Rejected and failed attempts are audited as well as the sign-offs
+	signedOff := false
+	recorder := newAttemptRecorder(w)
+	w = recorder
+	defer func() {
+		if !signedOff {
+			s.recordFailure(r, audit.ActionSignOffFailed, r.PathValue("id"), recorder)
+		}
+	}()
+
+	run, ok := s.findRun(w, r)
+	if !ok {
+		return
+	}
+
+	signOff := report.SignOff{
+		PreparedBy: r.FormValue("prepared_by"),
+		Reviewer:   r.FormValue("reviewer"),
+		Approver:   r.FormValue("approver"),
+	}
+	// Authenticated callers approve under their own name only
+	if principal, ok := auth.FromContext(r.Context()); ok && s.authenticator != nil {
+		if signOff.Approver != "" && signOff.Approver != principal.Subject {
+			sendJSONResponse(w, http.StatusForbidden, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("approver must be the caller %s", principal.Subject),
+			})
+			return
+		}
+		signOff.Approver = principal.Subject
+	}
+
+	// The report is kept and read by every role, it is masked whoever signs off
+	var buf bytes.Buffer
+	if err := report.WritePDF(&buf, s.masker.Run(run), signOff); err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error writing report: %v", err),
+		})
+		return
+	}
+
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+	s.closing.Lock()
+	defer s.closing.Unlock()
+	previous, err := runs.GetAttachment(run.ID, signOffAttachment)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error reading sign-off report: %v", err),
+		})
+		return
+	}
+	if err := runs.SaveAttachment(run.ID, signOffAttachment, buf.Bytes()); err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error attaching sign-off report: %v", err),
+		})
+		return
+	}
+
+	// A sign-off that cannot be audited is rolled back to the previous one, if any
+	if !s.record(w, r, audit.ActionSignOff, run.ID, signOff) {
+		if previous != nil {
+			err = runs.SaveAttachment(run.ID, signOffAttachment, previous)
+		} else {
+			err = runs.DeleteAttachment(run.ID, signOffAttachment)
+		}
+		if err != nil {
+			log.Printf("Failed to roll back unaudited sign-off of run %s: %v", run.ID, err)
+		}
+		return
+	}
+	signedOff = true
+
+	sendDocument(w, http.StatusCreated, report.FormatPDF, "signoff-"+run.ID, buf.Bytes())
+}
+
+// handleGetSignOff downloads the sign-off report attached to a stored run
+func (s *Server) handleGetSignOff(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("id")
+	data, err := runs.GetAttachment(id, signOffAttachment)
+	if errors.Is(err, store.ErrNotFound) {
+		sendJSONResponse(w, http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "No sign-off report attached to this run",
+		})
+		return
+	}
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error loading sign-off report: %v", err),
+		})
+		return
+	}
+
+	sendDocument(w, http.StatusOK, report.FormatPDF, "signoff-"+id, data)
+}
+
+// findRun loads the stored run named in the path from the runs of the caller tenant,
+// answering with an error when it cannot
+func (s *Server) findRun(w http.ResponseWriter, r *http.Request) (model.Run, bool) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return model.Run{}, false
+	}
+
+	run, err := runs.Get(r.PathValue("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		sendJSONResponse(w, http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Reconciliation run not found",
+		})
+		return model.Run{}, false
+	}
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error loading reconciliation run: %v", err),
+		})
+		return model.Run{}, false
+	}
+
+	return run, true
+}
+
+func sendReport(w http.ResponseWriter, format string, run model.Run) {
+	var buf bytes.Buffer
+	if err := report.Write(&buf, format, run); err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error writing report: %v", err),
+		})
+		return
+	}
+
+	name := "reconciliation"
+	if run.ID != "" {
+		name += "-" + run.ID
+	}
+
+	sendDocument(w, http.StatusOK, format, name, buf.Bytes())
+}
+
+// sendDocument writes a rendered report, as an attachment except for plain text
+func sendDocument(w http.ResponseWriter, statusCode int, format, name string, data []byte) {
+	w.Header().Set("Content-Type", report.ContentType(format))
+	if format != report.FormatText {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	}
+	w.WriteHeader(statusCode)
+	w.Write(data)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"os"
+)
+
+// handleHealth reports the process is alive
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"status": "ok"},
+	})
+}
+
+// handleReady reports whether the server takes reconciliations: it is not shutting down and
+// its upload and run directories are reachable
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		sendJSONResponse(w, http.StatusServiceUnavailable, APIResponse{
+			Success: false,
+			Error:   "Server is shutting down",
+		})
+		return
+	}
+
+	for _, dir := range []string{s.cfg.Server.UploadsDir, s.cfg.Server.RunsDir} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			sendJSONResponse(w, http.StatusServiceUnavailable, APIResponse{
+				Success: false,
+				Error:   "Storage directory unavailable: " + dir,
+			})
+			return
+		}
+	}
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    map[string]string{"status": "ready"},
+	})
+}
+
+// handleMetrics exposes the metrics in the Prometheus text exposition format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.writeTo(w, len(s.slots))
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthAndReadiness(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+	}
+
+	srv.draining.Store(true)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz while draining status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	srv.Handler().ServeHTTP(httptest.NewRecorder(), reconcileRequest(t))
+	srv.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/runs/unknown", nil))
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`http_requests_total{method="POST",route="/api/reconcile",code="200"} 1`,
+		`http_requests_total{method="GET",route="GET /api/runs/{id}",code="404"} 1`,
+		`http_request_duration_seconds_count{route="/api/reconcile"} 1`,
+		`http_request_duration_seconds_bucket{route="/api/reconcile",le="+Inf"} 1`,
+		`reconciliations_total{outcome="success"} 1`,
+		`reconciliation_records_parsed_total 3`,
+		`reconciliation_parse_errors_total 0`,
+		`reconciliation_transactions_total{side="system",result="matched"} 1`,
+		`reconciliation_transactions_total{side="system",result="unmatched"} 1`,
+		`reconciliation_transactions_total{side="bank",result="matched"} 1`,
+		`reconciliation_transactions_total{side="bank",result="unmatched"} 0`,
+		`reconciliation_match_rate 0.5`,
+		`reconciliation_jobs_queued 0`,
+		`reconciliation_jobs_running 0`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
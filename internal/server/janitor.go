@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/uploads"
+)
+
+// runJanitor removes the uploads the retention policy no longer keeps, every janitor interval
+// until ctx is done
+func (s *Server) runJanitor(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Server.JanitorInterval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if removed, err := s.sweepUploads(now); err != nil {
+				log.Printf("Upload cleanup failed: %v", err)
+			} else if removed > 0 {
+				log.Printf("Upload cleanup removed %d files", removed)
+			}
+		}
+	}
+}
+
+// sweepUploads applies the retention policy to the uploads of every tenant, including the
+// tenants that did not upload since the server started
+func (s *Server) sweepUploads(now time.Time) (int, error) {
+	policy := uploads.Policy{
+		MaxAge:       s.cfg.Server.UploadRetention.Duration,
+		MaxTotalSize: s.cfg.Server.UploadMaxTotalSize,
+	}
+	if policy == (uploads.Policy{}) {
+		return 0, nil
+	}
+
+	tenants := []string{""}
+	entries, err := os.ReadDir(filepath.Join(s.cfg.Server.UploadsDir, "tenants"))
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && config.ValidTenantName(entry.Name()) {
+			tenants = append(tenants, entry.Name())
+		}
+	}
+
+	total := 0
+	for _, tenant := range tenants {
+		blobs, err := s.uploadsOf(tenant)
+		if err != nil {
+			return total, err
+		}
+		removed, err := blobs.Sweep(policy, now)
+		total += removed
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
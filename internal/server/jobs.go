@@ -0,0 +1,225 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+)
+
+const (
+	// JobIDHeader names the job of a reconciliation request, callers choose it to follow the
+	// progress of the request while it is running
+	JobIDHeader = "X-Job-ID"
+	// jobRetention is how long the events of a finished job are kept for late subscribers
+	jobRetention = 5 * time.Minute
+	// heartbeatInterval is the time between two comments sent on an idle event stream, so proxies
+	// do not close it
+	heartbeatInterval = 15 * time.Second
+)
+
+// Events sent on the stream of a job
+const (
+	eventProgress = "progress"
+	eventDone     = "done"
+	eventFailed   = "failed"
+)
+
+var jobIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// jobEvent is an event of a job, its ID is its position in the events of the job from 1
+type jobEvent struct {
+	ID   int
+	Name string
+	Data []byte
+}
+
+// job follows a running reconciliation request, its events are kept until jobRetention after it
+// finished so subscribers can catch up
+type job struct {
+	mu       sync.Mutex
+	events   []jobEvent
+	finished bool
+	// changed is closed and replaced whenever an event is added
+	changed chan struct{}
+}
+
+// add appends an event to the job, data is sent as JSON. The job is finished by its last event.
+func (j *job) add(name string, data any, last bool) {
+	encoded, _ := json.Marshal(data)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.finished {
+		return
+	}
+	j.events = append(j.events, jobEvent{ID: len(j.events) + 1, Name: name, Data: encoded})
+	j.finished = last
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// progress reports the progress of the reconciliation, it is the progress callback of the service
+func (j *job) progress(p reconciliation.Progress) {
+	j.add(eventProgress, p, false)
+}
+
+// finish ends the job with the status of the response, the run ID being set when a run was stored
+func (j *job) finish(runID string, code int) {
+	if runID != "" {
+		j.add(eventDone, map[string]string{"run_id": runID}, true)
+		return
+	}
+	j.add(eventFailed, map[string]any{"status": code, "error": http.StatusText(code)}, true)
+}
+
+// since returns the events following the event with ID last, a channel closed once more events
+// are added and whether the job is finished
+func (j *job) since(last int) ([]jobEvent, <-chan struct{}, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if last < 0 || last > len(j.events) {
+		last = len(j.events)
+	}
+	return j.events[last:], j.changed, j.finished
+}
+
+// jobs holds the jobs of every tenant by tenant and ID
+type jobs struct {
+	mu     sync.Mutex
+	byKey  map[string]*job
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newJobs() *jobs {
+	return &jobs{byKey: make(map[string]*job), closed: make(chan struct{})}
+}
+
+func jobKey(tenant, id string) string {
+	return tenant + "/" + id
+}
+
+// start registers a new job of tenant, it fails when a job with the same ID is running or kept.
+// The job is forgotten jobRetention after it finished.
+func (js *jobs) start(tenant, id string) (*job, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	key := jobKey(tenant, id)
+	if _, ok := js.byKey[key]; ok {
+		return nil, fmt.Errorf("job %s already exists", id)
+	}
+	j := &job{changed: make(chan struct{})}
+	js.byKey[key] = j
+	return j, nil
+}
+
+// forget removes the job of tenant after jobRetention
+func (js *jobs) forget(tenant, id string) {
+	time.AfterFunc(jobRetention, func() {
+		js.mu.Lock()
+		defer js.mu.Unlock()
+		delete(js.byKey, jobKey(tenant, id))
+	})
+}
+
+// get returns the job of tenant with the ID
+func (js *jobs) get(tenant, id string) (*job, bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	j, ok := js.byKey[jobKey(tenant, id)]
+	return j, ok
+}
+
+// close ends the event streams, so they do not hold the server open when it shuts down
+func (js *jobs) close() {
+	js.once.Do(func() { close(js.closed) })
+}
+
+// startJob registers the job of a reconciliation request under the ID of the X-Job-ID header and
+// returns it in the response header. Requests without the header are not followed, the job is nil:
+// a generated ID would only reach the caller with the response, once there is nothing to follow.
+// It answers the request itself on failure.
+func (s *Server) startJob(w http.ResponseWriter, r *http.Request) (*job, func(runID string, code int), bool) {
+	id := r.Header.Get(JobIDHeader)
+	if id == "" {
+		return nil, func(string, int) {}, true
+	}
+	if !jobIDPattern.MatchString(id) {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   JobIDHeader + " must be 1 to 64 letters, digits, dashes or underscores",
+		})
+		return nil, nil, false
+	}
+
+	principal, _ := auth.FromContext(r.Context())
+	j, err := s.jobs.start(principal.Tenant, id)
+	if err != nil {
+		sendJSONResponse(w, http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Job %s is already in use", id),
+		})
+		return nil, nil, false
+	}
+	w.Header().Set(JobIDHeader, id)
+
+	finish := func(runID string, code int) {
+		j.finish(runID, code)
+		s.jobs.forget(principal.Tenant, id)
+	}
+	return j, finish, true
+}
+
+// handleJobEvents streams the progress of a reconciliation job as server-sent events. Events
+// already sent are replayed first, from the one following Last-Event-ID when the header is set.
+// The stream ends after the done or failed event.
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.FromContext(r.Context())
+	j, ok := s.jobs.get(principal.Tenant, r.PathValue("id"))
+	if !ok {
+		sendJSONResponse(w, http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Job not found",
+		})
+		return
+	}
+	last, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+	// Streams outlive the write timeout of the server, heartbeats detect gone clients instead
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		events, changed, finished := j.since(last)
+		for _, event := range events {
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Name, event.Data)
+			last = event.ID
+		}
+		if err := rc.Flush(); err != nil || finished {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case <-s.jobs.closed:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+)
+
+type sseEvent struct {
+	id, name, data string
+}
+
+// readEvents reads the server-sent events of a stream until it ends
+func readEvents(t *testing.T, body io.Reader) []sseEvent {
+	t.Helper()
+
+	var events []sseEvent
+	var event sseEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		field, value, _ := strings.Cut(scanner.Text(), ": ")
+		switch field {
+		case "id":
+			event.id = value
+		case "event":
+			event.name = value
+		case "data":
+			event.data = value
+		case "":
+			if event.name != "" {
+				events = append(events, event)
+			}
+			event = sseEvent{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestJobEvents(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	req := reconcileRequest(t)
+	req.Header.Set(JobIDHeader, "upload-1")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get(JobIDHeader) != "upload-1" {
+		t.Fatalf("status = %d, job %q, body %s", rec.Code, rec.Header().Get(JobIDHeader), rec.Body)
+	}
+	runID := rec.Header().Get("X-Run-ID")
+
+	// The events of a finished job are replayed to late subscribers
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/jobs/upload-1/events", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	events := readEvents(t, rec.Body)
+	if len(events) < 3 {
+		t.Fatalf("events = %+v", events)
+	}
+	var first reconciliation.Progress
+	if err := json.Unmarshal([]byte(events[0].data), &first); err != nil || events[0].name != eventProgress || first.Stage != reconciliation.StageParsed || first.File != "system.csv" {
+		t.Errorf("first event = %+v", events[0])
+	}
+	last := events[len(events)-1]
+	if last.name != eventDone || last.data != `{"run_id":"`+runID+`"}` {
+		t.Errorf("last event = %+v, want done with run %s", last, runID)
+	}
+
+	// Reconnecting clients only receive the events they missed
+	req = httptest.NewRequest(http.MethodGet, "/api/jobs/upload-1/events", nil)
+	req.Header.Set("Last-Event-ID", events[len(events)-2].id)
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if resumed := readEvents(t, rec.Body); len(resumed) != 1 || resumed[0] != last {
+		t.Errorf("resumed events = %+v", resumed)
+	}
+
+	// Job IDs are not reused while kept, and must be valid
+	for id, want := range map[string]int{"upload-1": http.StatusConflict, "bad id!": http.StatusBadRequest} {
+		req := reconcileRequest(t)
+		req.Header.Set(JobIDHeader, id)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("job %q: status = %d, want %d", id, rec.Code, want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/jobs/unknown/events", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown job status = %d", rec.Code)
+	}
+}
+
+func TestJobEventsLive(t *testing.T) {
+	srv := newTestServer(t, nil)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	job, err := srv.jobs.start("", "live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.progress(reconciliation.Progress{Stage: reconciliation.StageParsing, File: "system.csv", Rows: 10, Percent: 5})
+
+	resp, err := http.Get(ts.URL + "/api/jobs/live/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// Events are sent as they happen, the stream ends with the job
+	received := make(chan []sseEvent)
+	go func() { received <- readEvents(t, resp.Body) }()
+	job.progress(reconciliation.Progress{Stage: reconciliation.StageDone, Percent: 100})
+	job.finish("", http.StatusBadRequest)
+
+	events := <-received
+	var names []string
+	for _, event := range events {
+		names = append(names, event.name)
+	}
+	if strings.Join(names, ",") != "progress,progress,failed" || events[2].data != `{"error":"Bad Request","status":400}` {
+		t.Errorf("events = %+v", events)
+	}
+}
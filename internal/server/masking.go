@@ -0,0 +1,22 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/masking"
+)
+
+// maskerFor returns the masker applying to the caller of r, nil when one of its roles exempts it
+func (s *Server) maskerFor(r *http.Request) *masking.Masker {
+	principal, _ := auth.FromContext(r.Context())
+	return s.maskerOf(principal)
+}
+
+// maskerOf returns the masker applying to principal, nil when one of its roles exempts it
+func (s *Server) maskerOf(principal auth.Principal) *masking.Masker {
+	if principal.HasAnyRole(s.cfg.Masking.ExemptRoles...) {
+		return nil
+	}
+	return s.masker
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/masking"
+)
+
+func TestMasking(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.APIKeys = []auth.APIKey{
+			{Name: "nightly", SHA256: auth.HashKey("nightly-key"), Roles: []string{auth.RoleUploader}},
+			{Name: "auditor", SHA256: auth.HashKey("auditor-key"), Roles: []string{auth.RoleReviewer}},
+			{Name: "admin", SHA256: auth.HashKey("admin-key"), Roles: []string{auth.RoleAdmin}},
+		}
+		cfg.Masking = config.Masking{
+			Rules:       []masking.Rule{{Field: masking.FieldIdentifier, Action: masking.ActionPartial, Keep: 1}},
+			ExemptRoles: []string{auth.RoleAdmin},
+		}
+	})
+
+	req := reconcileRequest(t)
+	req.Header.Set(auth.APIKeyHeader, "nightly-key")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	runID := rec.Header().Get("X-Run-ID")
+	if body := rec.Body.String(); !strings.Contains(body, `"trx_id":"*2"`) || strings.Contains(body, `"T2"`) {
+		t.Errorf("reconcile response is not masked: %s", body)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantMasked bool
+	}{
+		{"run for a reviewer", "/api/runs/" + runID, "auditor-key", true},
+		{"report for a reviewer", "/api/runs/" + runID + "/report?format=csv", "auditor-key", true},
+		{"run for an exempt role", "/api/runs/" + runID, "admin-key", false},
+		{"report for an exempt role", "/api/runs/" + runID + "/report?format=csv", "admin-key", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(auth.APIKeyHeader, tt.key)
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+			}
+
+			if masked := strings.Contains(rec.Body.String(), "*1"); masked != tt.wantMasked {
+				t.Errorf("masked = %v, want %v: %s", masked, tt.wantMasked, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the request duration histogram
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// requestKey identifies a request counter
+type requestKey struct {
+	method, route string
+	code          int
+}
+
+// histogram counts observations per bucket, counts[i] holds the observations up to latencyBuckets[i]
+// and the last count the ones above every bucket
+type histogram struct {
+	counts []uint64
+	sum    float64
+	total  uint64
+}
+
+func (h *histogram) observe(value float64) {
+	i := sort.SearchFloat64s(latencyBuckets, value)
+	h.counts[i]++
+	h.sum += value
+	h.total++
+}
+
+// metrics collects the server metrics exposed in the Prometheus text format on /metrics
+type metrics struct {
+	mu              sync.Mutex
+	requests        map[requestKey]uint64
+	latencies       map[string]*histogram
+	reconciliations map[string]uint64
+	recordsParsed   uint64
+	parseErrors     uint64
+	matched         uint64
+	// unmatched counts the unmatched records by side, system or bank
+	unmatched     map[string]uint64
+	lastMatchRate float64
+
+	// queued counts the reconciliations waiting for a free slot
+	queued atomic.Int64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:        make(map[requestKey]uint64),
+		latencies:       make(map[string]*histogram),
+		reconciliations: make(map[string]uint64),
+		unmatched:       make(map[string]uint64),
+	}
+}
+
+func (m *metrics) observeRequest(method, route string, code int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{method: method, route: route, code: code}]++
+	h, ok := m.latencies[route]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets)+1)}
+		m.latencies[route] = h
+	}
+	h.observe(elapsed.Seconds())
+}
+
+// observeReconciliation records the outcome of a reconciliation, result is ignored when it failed
+func (m *metrics) observeReconciliation(result model.ReconcileResponse, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		m.reconciliations["error"]++
+		return
+	}
+
+	m.reconciliations["success"]++
+	m.recordsParsed += uint64(result.RecordsParsed)
+	m.parseErrors += uint64(len(result.ParseErrors))
+	m.matched += uint64(result.Matched)
+	m.unmatched["system"] += uint64(len(result.UnmatchedSystem))
+	for _, statements := range result.UnmatchedByBank {
+		m.unmatched["bank"] += uint64(len(statements))
+	}
+	if result.TotalProcessed > 0 {
+		m.lastMatchRate = float64(result.Matched) / float64(result.TotalProcessed)
+	}
+}
+
+// writeTo writes every metric in the Prometheus text exposition format, running is the number
+// of reconciliations in progress
+func (m *metrics) writeTo(w io.Writer, running int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP http_requests_total HTTP requests served, by method, route and status code.")
+	fmt.Fprintln(w, "# TYPE http_requests_total counter")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "http_requests_total{method=%q,route=%q,code=\"%d\"} %d\n", key.method, key.route, key.code, m.requests[key])
+	}
+
+	fmt.Fprintln(w, "# HELP http_request_duration_seconds HTTP request latencies, by route.")
+	fmt.Fprintln(w, "# TYPE http_request_duration_seconds histogram")
+	routes := make([]string, 0, len(m.latencies))
+	for route := range m.latencies {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	for _, route := range routes {
+		h := m.latencies[route]
+		var cumulative uint64
+		for i, bound := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{route=%q,le=%q} %d\n", route, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(w, "http_request_duration_seconds_bucket{route=%q,le=\"+Inf\"} %d\n", route, h.total)
+		fmt.Fprintf(w, "http_request_duration_seconds_sum{route=%q} %g\n", route, h.sum)
+		fmt.Fprintf(w, "http_request_duration_seconds_count{route=%q} %d\n", route, h.total)
+	}
+
+	fmt.Fprintln(w, "# HELP reconciliations_total Reconciliations run, by outcome.")
+	fmt.Fprintln(w, "# TYPE reconciliations_total counter")
+	for _, outcome := range []string{"success", "error"} {
+		fmt.Fprintf(w, "reconciliations_total{outcome=%q} %d\n", outcome, m.reconciliations[outcome])
+	}
+
+	fmt.Fprintln(w, "# HELP reconciliation_records_parsed_total System and bank records read from uploaded files.")
+	fmt.Fprintln(w, "# TYPE reconciliation_records_parsed_total counter")
+	fmt.Fprintf(w, "reconciliation_records_parsed_total %d\n", m.recordsParsed)
+
+	fmt.Fprintln(w, "# HELP reconciliation_parse_errors_total Rows of uploaded files that could not be parsed.")
+	fmt.Fprintln(w, "# TYPE reconciliation_parse_errors_total counter")
+	fmt.Fprintf(w, "reconciliation_parse_errors_total %d\n", m.parseErrors)
+
+	fmt.Fprintln(w, "# HELP reconciliation_transactions_total System transactions and bank statement lines processed, by side and matching result.")
+	fmt.Fprintln(w, "# TYPE reconciliation_transactions_total counter")
+	for _, side := range []string{"system", "bank"} {
+		// Every matched pair holds a record of each side
+		fmt.Fprintf(w, "reconciliation_transactions_total{side=%q,result=\"matched\"} %d\n", side, m.matched)
+		fmt.Fprintf(w, "reconciliation_transactions_total{side=%q,result=\"unmatched\"} %d\n", side, m.unmatched[side])
+	}
+
+	fmt.Fprintln(w, "# HELP reconciliation_match_rate Share of system transactions matched by the last reconciliation.")
+	fmt.Fprintln(w, "# TYPE reconciliation_match_rate gauge")
+	fmt.Fprintf(w, "reconciliation_match_rate %g\n", m.lastMatchRate)
+
+	fmt.Fprintln(w, "# HELP reconciliation_jobs_queued Reconciliations waiting for a free slot.")
+	fmt.Fprintln(w, "# TYPE reconciliation_jobs_queued gauge")
+	fmt.Fprintf(w, "reconciliation_jobs_queued %d\n", m.queued.Load())
+
+	fmt.Fprintln(w, "# HELP reconciliation_jobs_running Reconciliations in progress.")
+	fmt.Fprintln(w, "# TYPE reconciliation_jobs_running gauge")
+	fmt.Fprintf(w, "reconciliation_jobs_running %d\n", running)
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// instrument counts the requests served by next and their latency under route
+func (s *Server) instrument(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		next(recorder, r)
+		s.metrics.observeRequest(r.Method, route, recorder.code, time.Since(start))
+	}
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer, e.g. to flush it
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type openAPISpec struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components map[string]map[string]json.RawMessage `json:"components"`
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("status = %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var spec openAPISpec
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if spec.OpenAPI != "3.0.3" {
+		t.Errorf("openapi = %q", spec.OpenAPI)
+	}
+
+	t.Run("routes are documented", func(t *testing.T) {
+		for _, route := range srv.routes {
+			method, path, found := strings.Cut(route, " ")
+			if !found {
+				// Routes without a method check it themselves, only POST is served
+				method, path = http.MethodPost, route
+			}
+			if _, ok := spec.Paths[path][strings.ToLower(method)]; !ok {
+				t.Errorf("%s %s is not in the OpenAPI document", method, path)
+			}
+		}
+	})
+
+	t.Run("operations are served", func(t *testing.T) {
+		for path, operations := range spec.Paths {
+			for method := range operations {
+				target := strings.ReplaceAll(path, "{id}", strings.Repeat("0", 32))
+				rec := httptest.NewRecorder()
+				srv.Handler().ServeHTTP(rec, httptest.NewRequest(strings.ToUpper(method), target, nil))
+
+				// The mux answers unknown routes in plain text, handlers never do
+				if body := rec.Body.String(); body == "404 page not found\n" || rec.Code == http.StatusMethodNotAllowed {
+					t.Errorf("%s %s is documented but not served: %d %s", method, path, rec.Code, body)
+				}
+			}
+		}
+	})
+
+	t.Run("references resolve", func(t *testing.T) {
+		for _, ref := range findRefs(json.RawMessage(openAPIDocument)) {
+			parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+			if len(parts) != 2 || spec.Components[parts[0]][parts[1]] == nil {
+				t.Errorf("unresolved reference %s", ref)
+			}
+		}
+	})
+}
+
+// findRefs returns every $ref of a JSON document
+func findRefs(doc json.RawMessage) []string {
+	var value any
+	json.Unmarshal(doc, &value)
+
+	var refs []string
+	var walk func(any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if ref, ok := child.(string); ok && key == "$ref" {
+					refs = append(refs, ref)
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(value)
+	return refs
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/store"
+)
+
+// overrideActions audits the overrides by kind
+var overrideActions = map[string]string{
+	model.OverrideManualMatch: audit.ActionManualMatch,
+	model.OverrideWriteOff:    audit.ActionWriteOff,
+}
+
+// handleCreateOverride resolves exceptions of a stored run by hand with the kind, exception and
+// reason form values, exception being repeated for each exception resolved. Exceptions resolved
+// already, and runs signed off, cannot be overridden.
+func (s *Server) handleCreateOverride(w http.ResponseWriter, r *http.Request) {
+	// Rejected and failed attempts are audited as well as the overrides
+	created := false
+	recorder := newAttemptRecorder(w)
+	w = recorder
+	defer func() {
+		if !created {
+			s.recordFailure(r, audit.ActionOverrideFailed, r.PathValue("id"), recorder)
+		}
+	}()
+
+	kind := r.FormValue("kind")
+	reason := strings.TrimSpace(r.FormValue("reason"))
+	ids := r.Form["exception"]
+	action, ok := overrideActions[kind]
+	if !ok {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("kind must be %s or %s", model.OverrideManualMatch, model.OverrideWriteOff),
+		})
+		return
+	}
+	if reason == "" || len(ids) == 0 {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "reason and at least one exception are required",
+		})
+		return
+	}
+
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+	s.closing.Lock()
+	defer s.closing.Unlock()
+
+	id := r.PathValue("id")
+	overrides, ok := findIndex(w, r, runs.Overrides)
+	if !ok {
+		return
+	}
+	if runs.HasAttachment(id, signOffAttachment) {
+		sendJSONResponse(w, http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   "Run is signed off, its exceptions cannot be overridden anymore",
+		})
+		return
+	}
+	exceptions, ok := findIndex(w, r, runs.Exceptions)
+	if !ok {
+		return
+	}
+
+	selected, status, err := selectExceptions(resolveExceptions(exceptions, overrides), ids)
+	if err != nil {
+		sendJSONResponse(w, status, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	amount, err := overrideAmount(kind, selected)
+	if err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+
+	principal, _ := auth.FromContext(r.Context())
+	override := model.Override{
+		ID:         fmt.Sprintf("override-%d", len(overrides)+1),
+		Kind:       kind,
+		Exceptions: ids,
+		Reason:     reason,
+		Amount:     amount,
+		CreatedAt:  time.Now().UTC(),
+		CreatedBy:  principal.Subject,
+	}
+	if err := runs.SaveOverrides(id, append(slices.Clone(overrides), override)); err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error storing override: %v", err),
+		})
+		return
+	}
+
+	// An override that cannot be audited is rolled back
+	if !s.record(w, r, action, id, override) {
+		if err := runs.SaveOverrides(id, overrides); err != nil {
+			log.Printf("Failed to roll back unaudited override of run %s: %v", id, err)
+		}
+		return
+	}
+	created = true
+
+	sendJSONResponse(w, http.StatusCreated, APIResponse{
+		Success: true,
+		Data:    override,
+		RunID:   id,
+	})
+}
+
+// handleListOverrides lists the overrides of a stored run, oldest first
+func (s *Server) handleListOverrides(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+	overrides, ok := findIndex(w, r, runs.Overrides)
+	if !ok {
+		return
+	}
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    overrides,
+		RunID:   r.PathValue("id"),
+	})
+}
+
+// runExceptions reads the exceptions of the run named by the request path with the override
+// resolving each of them
+func (s *Server) runExceptions(w http.ResponseWriter, r *http.Request, runs *store.Store) ([]model.Exception, bool) {
+	exceptions, ok := findIndex(w, r, runs.Exceptions)
+	if !ok {
+		return nil, false
+	}
+	overrides, ok := findIndex(w, r, runs.Overrides)
+	if !ok {
+		return nil, false
+	}
+	return resolveExceptions(exceptions, overrides), true
+}
+
+// resolveExceptions returns a copy of exceptions with the override resolving each of them
+func resolveExceptions(exceptions []model.Exception, overrides []model.Override) []model.Exception {
+	resolved := make(map[string]string)
+	for _, override := range overrides {
+		for _, id := range override.Exceptions {
+			resolved[id] = override.ID
+		}
+	}
+	list := slices.Clone(exceptions)
+	for i := range list {
+		list[i].Override = resolved[list[i].ID]
+	}
+	return list
+}
+
+// selectExceptions returns the exceptions named by ids, with the status answered when one is
+// unknown, repeated or resolved already
+func selectExceptions(exceptions []model.Exception, ids []string) ([]model.Exception, int, error) {
+	byID := make(map[string]model.Exception, len(exceptions))
+	for _, exception := range exceptions {
+		byID[exception.ID] = exception
+	}
+
+	selected := make([]model.Exception, 0, len(ids))
+	for i, id := range ids {
+		exception, ok := byID[id]
+		switch {
+		case !ok:
+			return nil, http.StatusBadRequest, fmt.Errorf("unknown exception %s", id)
+		case slices.Contains(ids[:i], id):
+			return nil, http.StatusBadRequest, fmt.Errorf("exception %s is given twice", id)
+		case exception.Override != "":
+			return nil, http.StatusConflict, fmt.Errorf("exception %s is already resolved by %s", id, exception.Override)
+		}
+		selected = append(selected, exception)
+	}
+	return selected, http.StatusOK, nil
+}
+
+// overrideAmount checks the exceptions can be resolved by an override of kind and returns its
+// amount. A manual match pairs one unmatched system transaction with unmatched bank lines, its
+// amount is the part of the transaction they leave unexplained.
+func overrideAmount(kind string, exceptions []model.Exception) (float64, error) {
+	var cents int64
+	if kind == model.OverrideWriteOff {
+		for _, exception := range exceptions {
+			if exception.Status == model.ExceptionDiscrepancy {
+				cents += toCents(exception.Residual)
+			} else {
+				cents += toCents(exception.Amount)
+			}
+		}
+		return float64(cents) / 100, nil
+	}
+
+	systems := 0
+	for _, exception := range exceptions {
+		switch exception.Status {
+		case model.ExceptionUnmatchedSystem:
+			systems++
+			cents += toCents(exception.Amount)
+		case model.ExceptionUnmatchedBank:
+			cents -= toCents(exception.Amount)
+		default:
+			return 0, errors.New("a manual match resolves unmatched records only")
+		}
+	}
+	if systems != 1 || len(exceptions) < 2 {
+		return 0, errors.New("a manual match pairs one unmatched system transaction with unmatched bank statement lines")
+	}
+	return float64(cents) / 100, nil
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
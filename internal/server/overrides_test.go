@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+func TestOverrides(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Auth.APIKeys = []auth.APIKey{
+			{Name: "uploader", SHA256: auth.HashKey("uploader"), Roles: []string{auth.RoleUploader}},
+			{Name: "reviewer", SHA256: auth.HashKey("reviewer"), Roles: []string{auth.RoleReviewer}},
+			{Name: "approver", SHA256: auth.HashKey("approver"), Roles: []string{auth.RoleApprover}},
+		}
+	})
+	serve := func(req *http.Request, key string) *httptest.ResponseRecorder {
+		req.Header.Set(auth.APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	var reconciled struct {
+		RunID string `json:"run_id"`
+	}
+	json.NewDecoder(serve(reconcileRequest(t), "uploader").Body).Decode(&reconciled)
+	override := func(key string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/runs/"+reconciled.RunID+"/overrides", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return serve(req, key)
+	}
+
+	// T2 is the only exception of the run
+	writeOff := url.Values{"kind": {model.OverrideWriteOff}, "exception": {"unmatched_system-1"}, "reason": {"Booked twice in the ERP"}}
+	tests := []struct {
+		name string
+		key  string
+		form url.Values
+		want int
+	}{
+		{"uploader", "uploader", writeOff, http.StatusForbidden},
+		{"unknown kind", "reviewer", url.Values{"kind": {"delete"}, "exception": {"unmatched_system-1"}, "reason": {"r"}}, http.StatusBadRequest},
+		{"no reason", "reviewer", url.Values{"kind": {model.OverrideWriteOff}, "exception": {"unmatched_system-1"}}, http.StatusBadRequest},
+		{"unknown exception", "reviewer", url.Values{"kind": {model.OverrideWriteOff}, "exception": {"unmatched_system-9"}, "reason": {"r"}}, http.StatusBadRequest},
+		{"match without bank line", "reviewer", url.Values{"kind": {model.OverrideManualMatch}, "exception": {"unmatched_system-1"}, "reason": {"r"}}, http.StatusBadRequest},
+		{"write-off", "reviewer", writeOff, http.StatusCreated},
+		{"resolved already", "reviewer", writeOff, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if rec := override(tt.key, tt.form); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d, body %s", tt.name, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	var listed struct {
+		Data []model.Override `json:"data"`
+	}
+	json.NewDecoder(serve(httptest.NewRequest(http.MethodGet, "/api/runs/"+reconciled.RunID+"/overrides", nil), "approver").Body).Decode(&listed)
+	if len(listed.Data) != 1 || listed.Data[0].ID != "override-1" || listed.Data[0].Amount != 50000 || listed.Data[0].CreatedBy != "reviewer" {
+		t.Errorf("overrides = %+v", listed.Data)
+	}
+	var exceptions struct {
+		Data model.Page[model.Exception] `json:"data"`
+	}
+	json.NewDecoder(serve(httptest.NewRequest(http.MethodGet, "/api/v1/runs/"+reconciled.RunID+"/exceptions", nil), "reviewer").Body).Decode(&exceptions)
+	if len(exceptions.Data.Items) != 1 || exceptions.Data.Items[0].Override != "override-1" {
+		t.Errorf("exceptions = %+v, want T2 resolved by override-1", exceptions.Data.Items)
+	}
+
+	// Signed off runs are closed
+	if rec := serve(httptest.NewRequest(http.MethodPost, "/api/runs/"+reconciled.RunID+"/signoff", nil), "approver"); rec.Code != http.StatusCreated {
+		t.Fatalf("sign-off status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := override("reviewer", writeOff); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "signed off") {
+		t.Errorf("override of a signed off run: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	for action, want := range map[string]int{audit.ActionWriteOff: 1, audit.ActionOverrideFailed: 6} {
+		entries, err := srv.audit.Query(audit.Filter{Action: action, RunID: reconciled.RunID})
+		if err != nil || len(entries) != want {
+			t.Errorf("%s audit entries = %d, %v, want %d", action, len(entries), err, want)
+		}
+	}
+}
+
+func TestOverrideAmount(t *testing.T) {
+	system := model.Exception{Status: model.ExceptionUnmatchedSystem, Amount: 100000}
+	bank := model.Exception{Status: model.ExceptionUnmatchedBank, Amount: 60000.5}
+	discrepancy := model.Exception{Status: model.ExceptionDiscrepancy, Amount: 70000, Residual: 1500}
+
+	tests := []struct {
+		name       string
+		kind       string
+		exceptions []model.Exception
+		want       float64
+		wantErr    bool
+	}{
+		{"manual match", model.OverrideManualMatch, []model.Exception{system, bank, bank}, -20001, false},
+		{"manual match of bank lines", model.OverrideManualMatch, []model.Exception{bank, bank}, 0, true},
+		{"manual match of two system transactions", model.OverrideManualMatch, []model.Exception{system, system, bank}, 0, true},
+		{"manual match of a discrepancy", model.OverrideManualMatch, []model.Exception{system, discrepancy}, 0, true},
+		{"write-off", model.OverrideWriteOff, []model.Exception{bank, discrepancy}, 61500.5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := overrideAmount(tt.kind, tt.exceptions)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("overrideAmount() = %v, %v, want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
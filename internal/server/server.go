@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/arham-abiyan/reconciliation/internal/audit"
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/dirlock"
+	"github.com/arham-abiyan/reconciliation/internal/envelope"
+	"github.com/arham-abiyan/reconciliation/internal/masking"
+	"github.com/arham-abiyan/reconciliation/internal/store"
+	"github.com/arham-abiyan/reconciliation/internal/uploads"
+	"github.com/arham-abiyan/reconciliation/internal/webhook"
+)
+
+// readHeaderTimeout bounds the time a client takes to send the request headers, so slow clients
+// cannot hold connections open
+const readHeaderTimeout = 10 * time.Second
+
+// Server exposes the reconciliation service and the stored runs over HTTP
+type Server struct {
+	cfg config.Config
+	mux *http.ServeMux
+	// stores holds the run store of every tenant, the default tenant being the empty name
+	stores map[string]*store.Store
+	// uploads holds the upload store of every tenant, guarded by storesMu as well
+	uploads  map[string]*uploads.Store
+	storesMu sync.Mutex
+	// slots holds a token per running reconciliation, bounding them to MaxConcurrent
+	slots   chan struct{}
+	metrics *metrics
+	// draining is set once the server stops accepting new work
+	draining atomic.Bool
+	// authenticator verifies the callers of the API, nil when authentication is disabled
+	authenticator auth.Authenticator
+	audit         *audit.Log
+	// keys encrypts uploads and stored runs at rest, nil when encryption is disabled
+	keys *envelope.Keyring
+	// routes lists the patterns of the API routes, each is described in the OpenAPI document
+	routes []string
+	// masker hides personal data of the results sent to callers, nil when no rule is configured
+	masker *masking.Masker
+	// jobs follows the progress of the running reconciliation requests
+	jobs *jobs
+	// webhooks notifies downstream systems of the stored runs, nil when no webhook is configured,
+	// its attempts are recorded in deliveries
+	webhooks   *webhook.Dispatcher
+	deliveries *webhook.Log
+	// closing serializes the overrides and sign-offs of the runs, so no override follows a sign-off
+	// and two overrides never resolve the same exception
+	closing sync.Mutex
+}
+
+// New prepares the upload and run directories and registers the routes
+func New(cfg config.Config) (*Server, error) {
+	keys, err := cfg.Encryption.Keyring()
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption settings: %w", err)
+	}
+
+	blobs, err := uploads.New(cfg.Server.UploadsDir, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create uploads directory: %w", err)
+	}
+
+	runs, err := store.New(cfg.Server.RunsDir, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runs directory: %w", err)
+	}
+
+	masker, err := cfg.Masking.Masker()
+	if err != nil {
+		return nil, fmt.Errorf("invalid masking settings: %w", err)
+	}
+
+	auditLog, err := audit.Open(cfg.Server.AuditLog, []byte(cfg.Server.AuditKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	s := &Server{
+		cfg:     cfg,
+		stores:  map[string]*store.Store{"": runs},
+		uploads: map[string]*uploads.Store{"": blobs},
+		mux:     http.NewServeMux(),
+		slots:   make(chan struct{}, cfg.Server.MaxConcurrent),
+		metrics: newMetrics(),
+		audit:   auditLog,
+		keys:    keys,
+		masker:  masker,
+		jobs:    newJobs(),
+	}
+	if len(cfg.Webhooks.Endpoints) > 0 {
+		if s.deliveries, err = webhook.OpenLog(cfg.Webhooks.DeliveryLog); err != nil {
+			return nil, fmt.Errorf("failed to open webhook delivery log: %w", err)
+		}
+		s.webhooks = webhook.New(cfg.Webhooks.Endpoints, s.deliveries, cfg.Webhooks.Retry())
+	}
+	if cfg.Auth.Enabled() {
+		if s.authenticator, err = cfg.Auth.Authenticator(); err != nil {
+			return nil, fmt.Errorf("invalid auth settings: %w", err)
+		}
+	} else {
+		log.Println("Authentication is disabled, configure api keys or JWT to require it")
+	}
+
+	readers := []string{auth.RoleUploader, auth.RoleReviewer, auth.RoleApprover, auth.RoleAdmin}
+	s.handle("/api/reconcile", s.handleReconciliation, auth.RoleUploader, auth.RoleAdmin)
+	s.handle("GET /api/jobs/{id}/events", s.handleJobEvents, readers...)
+	s.handle("GET /api/runs", s.handleListRuns, readers...)
+	s.handle("GET /api/runs/{id}", s.handleGetRun, readers...)
+	s.handle("GET /api/runs/{id}/report", s.handleRunReport, readers...)
+	s.handle("POST /api/runs/{id}/signoff", s.handleCreateSignOff, auth.RoleApprover, auth.RoleAdmin)
+	s.handle("GET /api/runs/{id}/signoff", s.handleGetSignOff, readers...)
+	s.handle("POST /api/runs/{id}/overrides", s.handleCreateOverride, auth.RoleReviewer, auth.RoleAdmin)
+	s.handle("GET /api/runs/{id}/overrides", s.handleListOverrides, readers...)
+	s.handle("GET /api/v1/runs", s.handleV1ListRuns, readers...)
+	s.handle("GET /api/v1/runs/{id}", s.handleV1GetRun, readers...)
+	s.handle("GET /api/v1/runs/{id}/exceptions", s.handleV1ListExceptions, readers...)
+	s.handle("GET /api/v1/runs/{id}/matches", s.handleV1ListMatches, readers...)
+	s.handle("GET /api/v1/banks", s.handleV1ListBanks, readers...)
+	s.handle("GET /api/audit", s.handleListAudit, auth.RoleReviewer, auth.RoleApprover, auth.RoleAdmin)
+	s.handle("GET /api/audit/verify", s.handleVerifyAudit, auth.RoleAdmin)
+	s.handle("GET /api/webhooks/deliveries", s.handleListDeliveries, auth.RoleReviewer, auth.RoleApprover, auth.RoleAdmin)
+
+	// The API description is public, integrators read it before holding credentials
+	s.mux.HandleFunc("GET /api/openapi.json", s.instrument("GET /api/openapi.json", s.handleOpenAPI))
+
+	// Probes and metrics are not instrumented, scrapes would drown the API traffic
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /readyz", s.handleReady)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+
+	return s, nil
+}
+
+// handle registers an API route, its requests are counted under the route pattern.
+// Callers must authenticate, and hold one of roles when any is given.
+func (s *Server) handle(pattern string, handler http.HandlerFunc, roles ...string) {
+	s.routes = append(s.routes, pattern)
+	s.mux.HandleFunc(pattern, s.instrument(pattern, s.authenticate(handler, roles...)))
+}
+
+// Handler returns the HTTP handler serving every route
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Run serves the routes on the configured address, and the gRPC service when its address is set,
+// until ctx is done. It then stops accepting connections and waits for the running requests and
+// streams to finish, at most the shutdown timeout.
+func (s *Server) Run(ctx context.Context) error {
+	// rekey rewrites the stored files, it refuses to run while the server holds them
+	for _, dir := range []string{s.cfg.Server.UploadsDir, s.cfg.Server.RunsDir} {
+		lock, err := dirlock.Shared(dir)
+		if errors.Is(err, dirlock.ErrLocked) {
+			return fmt.Errorf("%s is being rekeyed, start the server once rekey is done", dir)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock %s: %w", dir, err)
+		}
+		defer lock.Release()
+	}
+	if err := s.webhooks.Resume(); err != nil {
+		return fmt.Errorf("failed to resume webhook deliveries: %w", err)
+	}
+
+	srv := &http.Server{
+		Addr:              s.cfg.Server.Addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       s.cfg.Server.ReadTimeout.Duration,
+		WriteTimeout:      s.cfg.Server.WriteTimeout.Duration,
+		IdleTimeout:       s.cfg.Server.IdleTimeout.Duration,
+	}
+	// Event streams would otherwise hold the shutdown until its timeout
+	srv.RegisterOnShutdown(s.jobs.close)
+
+	janitorCtx, stopJanitor := context.WithCancel(ctx)
+	defer stopJanitor()
+	go s.runJanitor(janitorCtx)
+
+	serveErr := make(chan error, 2)
+	go func() {
+		log.Println("Server starting on...", s.cfg.Server.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	var grpcSrv *grpc.Server
+	if s.cfg.Server.GRPCAddr != "" {
+		listener, err := net.Listen("tcp", s.cfg.Server.GRPCAddr)
+		if err != nil {
+			srv.Close()
+			return fmt.Errorf("gRPC server: %w", err)
+		}
+		grpcSrv = s.GRPCServer()
+		go func() {
+			log.Println("gRPC server starting on...", s.cfg.Server.GRPCAddr)
+			serveErr <- grpcSrv.Serve(listener)
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		srv.Close()
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	s.draining.Store(true)
+	log.Println("Server shutting down, waiting for running reconciliations")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
+	grpcStopped := make(chan struct{})
+	if grpcSrv != nil {
+		go func() {
+			grpcSrv.GracefulStop()
+			close(grpcStopped)
+		}()
+	} else {
+		close(grpcStopped)
+	}
+	err := srv.Shutdown(shutdownCtx)
+	// Streams still running when the timeout ends are cut, as HTTP requests are
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		if grpcSrv != nil {
+			grpcSrv.Stop()
+		}
+		<-grpcStopped
+	}
+	// Deliveries of the last runs are given what is left of the timeout
+	s.webhooks.Close(shutdownCtx)
+	if err != nil {
+		srv.Close()
+		return fmt.Errorf("server shutdown: %w", err)
+	}
+
+	log.Println("Server stopped")
+	return nil
+}
+
+// acquire waits for a free reconciliation slot, it fails when ctx is done first
+func (s *Server) acquire(ctx context.Context) error {
+	s.metrics.queued.Add(1)
+	defer s.metrics.queued.Add(-1)
+
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot taken by acquire
+func (s *Server) release() {
+	<-s.slots
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/envelope"
+)
+
+const (
+	testSystemCSV = "trxID,amount,type,transactionTime\n" +
+		"T1,100000,CREDIT,2024-12-02 10:00:00\n" +
+		"T2,50000,DEBIT,2024-12-02 11:00:00\n"
+	testBankCSV = "unique_identifier,amount,date\n" +
+		"T1,100000,2024-12-02\n"
+)
+
+// newTestServer returns a server storing its files under a temporary directory
+func newTestServer(t *testing.T, configure func(*config.Config)) *Server {
+	t.Helper()
+
+	cfg := config.Default()
+	dir := t.TempDir()
+	cfg.Server.UploadsDir = filepath.Join(dir, "uploads")
+	cfg.Server.RunsDir = filepath.Join(dir, "runs")
+	cfg.Server.AuditLog = filepath.Join(dir, "audit", "audit.log")
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	srv, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+// reconcileRequest builds a reconciliation request over the test files, with the extra form fields
+// given as name and value pairs
+func reconcileRequest(t *testing.T, fields ...string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for field, file := range map[string]struct{ name, content string }{
+		"system_file": {"system.csv", testSystemCSV},
+		"bank_files":  {"bank-a.csv", testBankCSV},
+	} {
+		part, err := form.CreateFormFile(field, file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(file.content))
+	}
+	form.WriteField("start_date", "2024-12-01")
+	form.WriteField("end_date", "2024-12-31")
+	for i := 0; i+1 < len(fields); i += 2 {
+		form.WriteField(fields[i], fields[i+1])
+	}
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/reconcile", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestReconcile(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, reconcileRequest(t))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var response struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Matched   int `json:"matched"`
+			Unmatched int `json:"umatched"`
+		} `json:"data"`
+		RunID string `json:"run_id"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if !response.Success || response.Data.Matched != 1 || response.Data.Unmatched != 1 || response.RunID == "" {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if _, err := os.Stat(filepath.Join(srv.cfg.Server.RunsDir, response.RunID+".json")); err != nil {
+		t.Errorf("run not stored: %v", err)
+	}
+}
+
+func TestReconcileBusy(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Server.MaxConcurrent = 1
+	})
+
+	// Hold the only slot, the request waits until its context ends
+	if err := srv.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, reconcileRequest(t).WithContext(ctx))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	srv.release()
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, reconcileRequest(t))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status after release = %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRunShutdown(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Server.Addr = "127.0.0.1:0"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Run(ctx) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+}
+
+func TestReconcileDeduplicatesUploads(t *testing.T) {
+	srv := newTestServer(t, nil)
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, reconcileRequest(t))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+		}
+
+		// Banks are still named after the uploaded files
+		var response struct {
+			Data struct {
+				ByBank []struct {
+					Bank string `json:"bank"`
+				} `json:"by_bank"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Data.ByBank) != 1 || response.Data.ByBank[0].Bank != "bank-a" {
+			t.Errorf("by_bank = %+v, want bank-a", response.Data.ByBank)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(srv.cfg.Server.UploadsDir, "sha256"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("stored %d files, want the system and bank files once", len(entries))
+	}
+}
+
+func TestReconcileEncrypted(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, bytes.Repeat([]byte{1}, 32), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Encryption.Keys = []config.EncryptionKey{{ID: "test", File: keyFile}}
+	})
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, reconcileRequest(t))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	runID := rec.Header().Get("X-Run-ID")
+
+	// Nothing is readable on disk, the API still returns the run
+	for _, dir := range []string{filepath.Join(srv.cfg.Server.UploadsDir, "sha256"), srv.cfg.Server.RunsDir} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !envelope.IsSealed(data) {
+				t.Errorf("%s is stored in plain text", entry.Name())
+			}
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/runs/"+runID, nil))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"matched":1`)) {
+		t.Errorf("GET run = %d, body %s", rec.Code, rec.Body)
+	}
+}
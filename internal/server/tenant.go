@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+	"github.com/arham-abiyan/reconciliation/internal/store"
+	"github.com/arham-abiyan/reconciliation/internal/uploads"
+)
+
+// bankNamePattern matches the bank names given with the bank form field, which name the bank
+// as they are in results and fee rules
+var bankNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,62}$`)
+
+// tenantDir returns the directory of a tenant under root, the default tenant uses root itself
+func tenantDir(root, tenant string) string {
+	if tenant == "" {
+		return root
+	}
+	return filepath.Join(root, "tenants", tenant)
+}
+
+// runsOf returns the run store of a tenant, creating it on first use
+func (s *Server) runsOf(tenant string) (*store.Store, error) {
+	s.storesMu.Lock()
+	defer s.storesMu.Unlock()
+
+	if runs, ok := s.stores[tenant]; ok {
+		return runs, nil
+	}
+	runs, err := store.New(tenantDir(s.cfg.Server.RunsDir, tenant), s.keys)
+	if err != nil {
+		return nil, err
+	}
+	s.stores[tenant] = runs
+	return runs, nil
+}
+
+// tenantRuns returns the run store of the caller tenant, answering with an error when it cannot
+func (s *Server) tenantRuns(w http.ResponseWriter, r *http.Request) (*store.Store, bool) {
+	principal, _ := auth.FromContext(r.Context())
+	runs, err := s.runsOf(principal.Tenant)
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error opening reconciliation runs: %v", err),
+		})
+		return nil, false
+	}
+	return runs, true
+}
+
+// uploadsOf returns the upload store of a tenant, creating it on first use
+func (s *Server) uploadsOf(tenant string) (*uploads.Store, error) {
+	s.storesMu.Lock()
+	defer s.storesMu.Unlock()
+
+	if blobs, ok := s.uploads[tenant]; ok {
+		return blobs, nil
+	}
+	blobs, err := uploads.New(tenantDir(s.cfg.Server.UploadsDir, tenant), s.keys)
+	if err != nil {
+		return nil, err
+	}
+	s.uploads[tenant] = blobs
+	return blobs, nil
+}
+
+// tenantUploads returns the upload store of the caller tenant, answering with an error when it cannot be created
+func (s *Server) tenantUploads(w http.ResponseWriter, r *http.Request) (*uploads.Store, bool) {
+	principal, _ := auth.FromContext(r.Context())
+	blobs, err := s.uploadsOf(principal.Tenant)
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error creating uploads directory: %v", err),
+		})
+		return nil, false
+	}
+	return blobs, true
+}
+
+// requestBanks names the banks of the uploaded bank files: by the bank fields of the form, one per
+// file in the same order, or else by the file names. File names are chosen by the client, so
+// tenants limited to some banks must name them with the bank fields, and only those banks. The
+// status code to answer with is returned along with the error.
+func (s *Server) requestBanks(tenant string, files []*multipart.FileHeader, names []string) ([]string, int, error) {
+	allowed, _ := s.cfg.Tenant(tenant)
+	if len(names) == 0 {
+		if len(allowed.Banks) > 0 && len(files) > 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("Tenant %s must name the bank of every bank file with the bank field", tenant)
+		}
+		banks := make([]string, 0, len(files))
+		for _, file := range files {
+			banks = append(banks, file.Filename)
+		}
+		return banks, 0, nil
+	}
+
+	if len(names) != len(files) {
+		return nil, http.StatusBadRequest, errors.New("One bank field is required per bank file")
+	}
+	for _, name := range names {
+		if !bankNamePattern.MatchString(name) || reconciliation.BankName(name) != name {
+			return nil, http.StatusBadRequest, fmt.Errorf("Invalid bank %q: use letters, digits and -", name)
+		}
+		if len(allowed.Banks) > 0 && !slices.Contains(allowed.Banks, name) {
+			return nil, http.StatusForbidden, fmt.Errorf("Bank %s is not available to tenant %s", name, tenant)
+		}
+	}
+	return names, 0, nil
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Tenants = []config.Tenant{{Name: "retail"}, {Name: "corporate", Banks: []string{"bank-c"}}}
+		cfg.Auth.APIKeys = []auth.APIKey{
+			{Name: "retail-uploader", SHA256: auth.HashKey("retail-up"), Tenant: "retail", Roles: []string{auth.RoleUploader}},
+			{Name: "retail-approver", SHA256: auth.HashKey("retail-ap"), Tenant: "retail", Roles: []string{auth.RoleApprover}},
+			{Name: "corporate-admin", SHA256: auth.HashKey("corporate-ad"), Tenant: "corporate", Roles: []string{auth.RoleAdmin}},
+		}
+	})
+
+	serve := func(req *http.Request, key string) *httptest.ResponseRecorder {
+		req.Header.Set(auth.APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(reconcileRequest(t), "retail-up")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reconcile status = %d, body %s", rec.Code, rec.Body)
+	}
+	var response struct {
+		RunID string `json:"run_id"`
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+
+	if _, err := os.Stat(filepath.Join(srv.cfg.Server.RunsDir, "tenants", "retail", response.RunID+".json")); err != nil {
+		t.Errorf("run not stored under the tenant directory: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(srv.cfg.Server.UploadsDir, "tenants", "retail")); len(entries) == 0 {
+		t.Error("uploads not saved under the tenant directory")
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		wantCode int
+	}{
+		{name: "owner tenant reads the run", method: http.MethodGet, path: "/api/runs/" + response.RunID, key: "retail-ap", wantCode: http.StatusOK},
+		{name: "other tenant cannot see the run", method: http.MethodGet, path: "/api/runs/" + response.RunID, key: "corporate-ad", wantCode: http.StatusNotFound},
+		{name: "uploader cannot sign off", method: http.MethodPost, path: "/api/runs/" + response.RunID + "/signoff", key: "retail-up", wantCode: http.StatusForbidden},
+		{name: "approver signs off", method: http.MethodPost, path: "/api/runs/" + response.RunID + "/signoff", key: "retail-ap", wantCode: http.StatusCreated},
+		{name: "approver cannot reconcile", method: http.MethodPost, path: "/api/reconcile", key: "retail-ap", wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serve(httptest.NewRequest(tt.method, tt.path, nil), tt.key); rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.wantCode, rec.Body)
+			}
+		})
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	json.NewDecoder(serve(httptest.NewRequest(http.MethodGet, "/api/runs", nil), "corporate-ad").Body).Decode(&list)
+	if len(list.Data) != 0 {
+		t.Errorf("corporate lists %d runs of another tenant", len(list.Data))
+	}
+
+	// Tenants limited to some banks name them with the bank field, not with the file names
+	for _, tt := range []struct {
+		name     string
+		fields   []string
+		wantCode int
+	}{
+		{"bank not named", nil, http.StatusBadRequest},
+		{"foreign bank", []string{"bank", "bank-a"}, http.StatusForbidden},
+		{"invalid bank", []string{"bank", "../bank-c"}, http.StatusBadRequest},
+		{"bank of the tenant", []string{"bank", "bank-c"}, http.StatusOK},
+	} {
+		rec := serve(reconcileRequest(t, tt.fields...), "corporate-ad")
+		if rec.Code != tt.wantCode {
+			t.Errorf("%s: reconcile status = %d, want %d, body %s", tt.name, rec.Code, tt.wantCode, rec.Body)
+		}
+	}
+	var result struct {
+		Data struct {
+			ByBank []struct {
+				Bank string `json:"bank"`
+			} `json:"by_bank"`
+		} `json:"data"`
+	}
+	rec = serve(reconcileRequest(t, "bank", "bank-c"), "corporate-ad")
+	if json.NewDecoder(rec.Body).Decode(&result); len(result.Data.ByBank) != 1 || result.Data.ByBank[0].Bank != "bank-c" {
+		t.Errorf("banks of the result = %+v, want bank-c", result.Data.ByBank)
+	}
+}
+
+func TestTenantRequired(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Tenants = []config.Tenant{{Name: "retail"}}
+	})
+
+	// Callers without a tenant, e.g. tokens without the tenant claim, would reach the default
+	// tenant, shared by no one
+	if err := srv.authorize(auth.Principal{Subject: "alice", Roles: []string{auth.RoleAdmin}}, nil); err == nil {
+		t.Error("authorize() accepted a caller without a tenant")
+	}
+	if err := srv.authorize(auth.Principal{Subject: "alice", Tenant: "retail", Roles: []string{auth.RoleAdmin}}, nil); err != nil {
+		t.Errorf("authorize() = %v", err)
+	}
+
+	cfg := srv.cfg
+	cfg.Auth.APIKeys = []auth.APIKey{{Name: "shared", SHA256: auth.HashKey("shared"), Roles: []string{auth.RoleAdmin}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted an api key without a tenant")
+	}
+}
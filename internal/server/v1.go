@@ -0,0 +1,411 @@
+package server
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/store"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 1000
+)
+
+// resource describes how the items of a listed resource are filtered and sorted, the filters
+// left nil are rejected in queries
+// sorts: Comparisons of the fields items can be sorted by, in ascending order
+// defaultSort: Sort applied when the query has none, prefixed with "-" when descending
+type resource[T any] struct {
+	banks       func(T) []string
+	status      func(T) string
+	amount      func(T) float64
+	date        func(T) time.Time
+	sorts       map[string]func(a, b T) int
+	defaultSort string
+}
+
+// listQuery holds the pagination, filters and sort of a listing
+// banks/statuses: Accepted values, any value when empty
+// since/until: Items dated since, inclusive, and until, exclusive, unbounded when zero
+type listQuery struct {
+	limit, offset        int
+	sort                 string
+	descending           bool
+	banks, statuses      []string
+	minAmount, maxAmount *float64
+	since, until         time.Time
+}
+
+// parseQuery reads the query parameters of a listing of res:
+// limit, offset, sort, bank, status, min_amount, max_amount, since and until
+func (res resource[T]) parseQuery(values url.Values) (listQuery, error) {
+	q := listQuery{limit: defaultPageLimit}
+
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return q, fmt.Errorf("limit must be a number between 1 and %d", maxPageLimit)
+		}
+		q.limit = limit
+	}
+	if value := values.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return q, fmt.Errorf("offset must be a non-negative number")
+		}
+		q.offset = offset
+	}
+
+	sort := cmp.Or(values.Get("sort"), res.defaultSort)
+	q.sort, q.descending = strings.CutPrefix(sort, "-")
+	if _, ok := res.sorts[q.sort]; !ok {
+		fields := make([]string, 0, len(res.sorts))
+		for field := range res.sorts {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		return q, fmt.Errorf("sort must be one of %s, prefixed with - for descending order", strings.Join(fields, ", "))
+	}
+
+	filters := []struct {
+		name      string
+		supported bool
+		parse     func(value string) error
+	}{
+		{"bank", res.banks != nil, func(value string) error {
+			q.banks = strings.Split(value, ",")
+			return nil
+		}},
+		{"status", res.status != nil, func(value string) error {
+			q.statuses = strings.Split(value, ",")
+			return nil
+		}},
+		{"min_amount", res.amount != nil, func(value string) (err error) {
+			q.minAmount, err = parseAmount(value)
+			return err
+		}},
+		{"max_amount", res.amount != nil, func(value string) (err error) {
+			q.maxAmount, err = parseAmount(value)
+			return err
+		}},
+		{"since", res.date != nil, func(value string) (err error) {
+			q.since, err = parseTime(value)
+			return err
+		}},
+		{"until", res.date != nil, func(value string) (err error) {
+			q.until, err = parseTime(value)
+			return err
+		}},
+	}
+	for _, filter := range filters {
+		value := values.Get(filter.name)
+		if value == "" {
+			continue
+		}
+		if !filter.supported {
+			return q, fmt.Errorf("this resource cannot be filtered by %s", filter.name)
+		}
+		if err := filter.parse(value); err != nil {
+			return q, fmt.Errorf("%s %w", filter.name, err)
+		}
+	}
+
+	return q, nil
+}
+
+func parseAmount(value string) (*float64, error) {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("must be a number")
+	}
+	return &amount, nil
+}
+
+// list returns the page of items matching q, in the order of q. Items comparing equal keep
+// their order, so pages stay stable between requests.
+func (res resource[T]) list(items []T, q listQuery) model.Page[T] {
+	matching := make([]T, 0, len(items))
+	for _, item := range items {
+		if res.matches(item, q) {
+			matching = append(matching, item)
+		}
+	}
+
+	compare := res.sorts[q.sort]
+	slices.SortStableFunc(matching, func(a, b T) int {
+		if q.descending {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+
+	start := min(q.offset, len(matching))
+	end := min(start+q.limit, len(matching))
+	return model.Page[T]{
+		Items:  matching[start:end],
+		Total:  len(matching),
+		Limit:  q.limit,
+		Offset: q.offset,
+	}
+}
+
+func (res resource[T]) matches(item T, q listQuery) bool {
+	if len(q.banks) > 0 && !slices.ContainsFunc(res.banks(item), func(bank string) bool { return slices.Contains(q.banks, bank) }) {
+		return false
+	}
+	if len(q.statuses) > 0 && !slices.Contains(q.statuses, res.status(item)) {
+		return false
+	}
+	if q.minAmount != nil && res.amount(item) < *q.minAmount {
+		return false
+	}
+	if q.maxAmount != nil && res.amount(item) > *q.maxAmount {
+		return false
+	}
+	if !q.since.IsZero() && res.date(item).Before(q.since) {
+		return false
+	}
+	if !q.until.IsZero() && !res.date(item).Before(q.until) {
+		return false
+	}
+	return true
+}
+
+// sendPage answers with the page of items matching the query of r, or with the error of an invalid query.
+// Items are filtered and sorted on their stored values, mask, when set, only applies to the page sent.
+func sendPage[T any](w http.ResponseWriter, r *http.Request, res resource[T], items []T, mask func([]T) []T) {
+	q, err := res.parseQuery(r.URL.Query())
+	if err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	page := res.list(items, q)
+	if mask != nil {
+		page.Items = mask(page.Items)
+	}
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    &page,
+	})
+}
+
+var runResource = resource[model.RunOverview]{
+	banks:  func(run model.RunOverview) []string { return run.Banks },
+	status: func(run model.RunOverview) string { return run.Status },
+	date:   func(run model.RunOverview) time.Time { return run.CreatedAt },
+	sorts: map[string]func(a, b model.RunOverview) int{
+		"created_at":    func(a, b model.RunOverview) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		"start_date":    func(a, b model.RunOverview) int { return cmp.Compare(a.Params.StartDate, b.Params.StartDate) },
+		"unmatched":     func(a, b model.RunOverview) int { return cmp.Compare(a.Unmatched, b.Unmatched) },
+		"discrepancies": func(a, b model.RunOverview) int { return cmp.Compare(a.Discrepancies, b.Discrepancies) },
+	},
+	defaultSort: "-created_at",
+}
+
+var exceptionResource = resource[model.Exception]{
+	banks:  func(e model.Exception) []string { return []string{e.Bank} },
+	status: func(e model.Exception) string { return e.Status },
+	amount: func(e model.Exception) float64 { return e.Amount },
+	date:   func(e model.Exception) time.Time { return e.Date },
+	sorts: map[string]func(a, b model.Exception) int{
+		"date":       func(a, b model.Exception) int { return a.Date.Compare(b.Date) },
+		"amount":     func(a, b model.Exception) int { return cmp.Compare(a.Amount, b.Amount) },
+		"bank":       func(a, b model.Exception) int { return cmp.Compare(a.Bank, b.Bank) },
+		"identifier": func(a, b model.Exception) int { return cmp.Compare(a.Identifier, b.Identifier) },
+		"residual":   func(a, b model.Exception) int { return cmp.Compare(a.Residual, b.Residual) },
+	},
+	defaultSort: "date",
+}
+
+var matchResource = resource[model.Match]{
+	banks:  func(m model.Match) []string { return []string{m.Bank} },
+	status: func(m model.Match) string { return m.Status },
+	amount: func(m model.Match) float64 { return m.System.Amount },
+	date:   func(m model.Match) time.Time { return m.System.TransactionTime },
+	sorts: map[string]func(a, b model.Match) int{
+		"date":            func(a, b model.Match) int { return a.System.TransactionTime.Compare(b.System.TransactionTime) },
+		"amount":          func(a, b model.Match) int { return cmp.Compare(a.System.Amount, b.System.Amount) },
+		"bank":            func(a, b model.Match) int { return cmp.Compare(a.Bank, b.Bank) },
+		"residual":        func(a, b model.Match) int { return cmp.Compare(a.Residual, b.Residual) },
+		"date_delta_days": func(a, b model.Match) int { return cmp.Compare(a.DateDeltaDays, b.DateDeltaDays) },
+	},
+	defaultSort: "date",
+}
+
+var bankResource = resource[model.Bank]{
+	sorts: map[string]func(a, b model.Bank) int{
+		"name": func(a, b model.Bank) int { return cmp.Compare(a.Name, b.Name) },
+		"runs": func(a, b model.Bank) int { return cmp.Compare(a.Runs, b.Runs) },
+	},
+	defaultSort: "name",
+}
+
+// handleV1ListRuns lists the runs of the caller tenant with their totals, most recent first
+func (s *Server) handleV1ListRuns(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := runs.List()
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error listing reconciliation runs: %v", err),
+		})
+		return
+	}
+
+	for i := range list {
+		if runs.HasAttachment(list[i].ID, signOffAttachment) {
+			list[i].Status = model.RunStatusSignedOff
+		}
+	}
+
+	sendPage(w, r, runResource, list, nil)
+}
+
+// handleV1GetRun describes a stored run with its totals, its records are listed by the
+// exceptions and matches resources
+func (s *Server) handleV1GetRun(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+	overview, ok := findIndex(w, r, runs.GetOverview)
+	if !ok {
+		return
+	}
+
+	if runs.HasAttachment(overview.ID, signOffAttachment) {
+		overview.Status = model.RunStatusSignedOff
+	}
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    &overview,
+		RunID:   overview.ID,
+	})
+}
+
+// handleV1ListExceptions lists the records of a stored run needing review, whatever matched
+// pairs the run kept
+func (s *Server) handleV1ListExceptions(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+	list, ok := s.runExceptions(w, r, runs)
+	if !ok {
+		return
+	}
+
+	sendPage(w, r, exceptionResource, list, s.maskerFor(r).Exceptions)
+}
+
+// handleV1ListMatches lists the matched pairs of a stored run, as far as the run kept them
+func (s *Server) handleV1ListMatches(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+	list, ok := findIndex(w, r, runs.Matches)
+	if !ok {
+		return
+	}
+
+	sendPage(w, r, matchResource, list, s.maskerFor(r).Matches)
+}
+
+// findIndex reads an index entry of the run named by the request path with read, answering with
+// an error when the run does not exist or cannot be read. The run itself is not loaded.
+func findIndex[T any](w http.ResponseWriter, r *http.Request, read func(id string) (T, error)) (T, bool) {
+	entry, err := read(r.PathValue("id"))
+	if errors.Is(err, store.ErrNotFound) {
+		sendJSONResponse(w, http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   "Reconciliation run not found",
+		})
+		return entry, false
+	}
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error loading reconciliation run: %v", err),
+		})
+		return entry, false
+	}
+
+	return entry, true
+}
+
+// handleV1ListBanks lists the banks known to the caller tenant: the configured banks it may
+// reconcile, and the banks of its stored runs
+func (s *Server) handleV1ListBanks(w http.ResponseWriter, r *http.Request) {
+	runs, ok := s.tenantRuns(w, r)
+	if !ok {
+		return
+	}
+
+	list, err := runs.List()
+	if err != nil {
+		sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Error listing reconciliation runs: %v", err),
+		})
+		return
+	}
+
+	principal, _ := auth.FromContext(r.Context())
+	tenant, _ := s.cfg.Tenant(principal.Tenant)
+	banks := map[string]*model.Bank{}
+	bank := func(name string) *model.Bank {
+		if banks[name] == nil {
+			banks[name] = &model.Bank{Name: name}
+		}
+		return banks[name]
+	}
+
+	for _, name := range tenant.Banks {
+		bank(name)
+	}
+	for _, profile := range s.cfg.Banks {
+		if len(tenant.Banks) > 0 && !slices.Contains(tenant.Banks, profile.Name) {
+			continue
+		}
+		if profile.Fee != nil {
+			bank(profile.Name).FeeType = profile.Fee.Type
+		} else {
+			bank(profile.Name)
+		}
+	}
+	for _, run := range list {
+		for _, name := range run.Banks {
+			b := bank(name)
+			b.Runs++
+			if b.LastRunAt == nil || run.CreatedAt.After(*b.LastRunAt) {
+				createdAt := run.CreatedAt
+				b.LastRunAt = &createdAt
+			}
+		}
+	}
+
+	items := make([]model.Bank, 0, len(banks))
+	for _, b := range banks {
+		items = append(items, *b)
+	}
+	sendPage(w, r, bankResource, items, nil)
+}
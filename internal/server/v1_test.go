@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/masking"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// largeRun returns a run with n unmatched system transactions, one per hour from 2024-12-01,
+// unmatched lines of two banks and a discrepancy
+func largeRun(n int) model.Run {
+	start := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	var result model.ReconcileResponse
+	for i := 0; i < n; i++ {
+		result.UnmatchedSystem = append(result.UnmatchedSystem, model.Transaction{
+			TrxID:           fmt.Sprintf("S%05d", i),
+			Amount:          float64(i),
+			TransactionTime: start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	result.UnmatchedByBank = map[string][]model.BankStatement{
+		"bank-a": {{UniqueIdentifier: "A1", Amount: -20, Date: start, Bank: "bank-a"}},
+		"bank-b": {{UniqueIdentifier: "B1", Amount: 30, Date: start, Bank: "bank-b"}},
+	}
+	system := model.Transaction{TrxID: "M1", Amount: 100, TransactionTime: start}
+	result.Matches = []model.MatchedPair{
+		{System: system, Bank: "bank-a"},
+		{System: system, Bank: "bank-a", AmountDelta: 2, Fee: 2},
+		{System: system, Bank: "bank-b", AmountDelta: 5, Residual: 5},
+	}
+	result.ByBank = []model.BankSummary{{Bank: "bank-a"}, {Bank: "bank-b"}}
+	result.Unmatched = n + 2
+
+	return model.Run{Params: model.RunParams{StartDate: "2024-12-01", EndDate: "2024-12-31"}, Result: result}
+}
+
+// getPage requests a v1 listing and decodes its page
+func getPage[T any](t *testing.T, srv *Server, target string, wantStatus int) model.Page[T] {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != wantStatus {
+		t.Fatalf("GET %s status = %d, want %d, body %s", target, rec.Code, wantStatus, rec.Body)
+	}
+
+	var response struct {
+		Data model.Page[T] `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	return response.Data
+}
+
+func TestV1Exceptions(t *testing.T) {
+	srv := newTestServer(t, nil)
+	runs, err := srv.runsOf("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := largeRun(20000)
+	if err := runs.Save(&run); err != nil {
+		t.Fatal(err)
+	}
+	base := "/api/v1/runs/" + run.ID + "/exceptions"
+
+	page := getPage[model.Exception](t, srv, base, http.StatusOK)
+	if page.Total != 20003 || len(page.Items) != defaultPageLimit || page.Items[0].Identifier != "S00000" {
+		t.Fatalf("first page: total %d, %d items", page.Total, len(page.Items))
+	}
+
+	page = getPage[model.Exception](t, srv, base+"?status=unmatched_system&sort=-amount&offset=10&limit=5", http.StatusOK)
+	if page.Total != 20000 || len(page.Items) != 5 || page.Items[0].Identifier != "S19989" {
+		t.Errorf("sorted page = %+v", page)
+	}
+
+	page = getPage[model.Exception](t, srv, base+"?min_amount=100&max_amount=199&since=2024-12-05&until=2024-12-06", http.StatusOK)
+	if page.Total != 20 || page.Items[0].Identifier != "S00100" {
+		t.Errorf("amount and date filters: total %d, first %+v", page.Total, page.Items[0])
+	}
+
+	page = getPage[model.Exception](t, srv, base+"?bank=bank-b", http.StatusOK)
+	if page.Total != 2 || page.Items[0].Status != model.ExceptionUnmatchedBank || page.Items[1].Status != model.ExceptionDiscrepancy || page.Items[1].Residual != 5 {
+		t.Errorf("bank-b exceptions = %+v", page.Items)
+	}
+
+	page = getPage[model.Exception](t, srv, base+"?offset=30000", http.StatusOK)
+	if page.Total != 20003 || len(page.Items) != 0 {
+		t.Errorf("page past the end = %+v", page)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=5000", "?offset=-1", "?sort=description", "?min_amount=abc", "?since=yesterday"} {
+		getPage[model.Exception](t, srv, base+query, http.StatusBadRequest)
+	}
+	getPage[model.Exception](t, srv, "/api/v1/runs/0123456789abcdef0123456789abcdef/exceptions", http.StatusNotFound)
+}
+
+func TestV1ExceptionsMasked(t *testing.T) {
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Masking = config.Masking{Rules: []masking.Rule{{Field: masking.FieldIdentifier, Action: masking.ActionPartial, Keep: 1}}}
+	})
+	runs, err := srv.runsOf("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := largeRun(1)
+	if err := runs.Save(&run); err != nil {
+		t.Fatal(err)
+	}
+
+	// Exceptions are sorted on their identifiers, A1, B1, M1 and S00000, not on the masked ones
+	page := getPage[model.Exception](t, srv, "/api/v1/runs/"+run.ID+"/exceptions?sort=identifier", http.StatusOK)
+	if page.Total != 4 || page.Items[0].Bank != "bank-a" || page.Items[3].Status != model.ExceptionUnmatchedSystem {
+		t.Fatalf("exceptions sorted by identifier = %+v", page.Items)
+	}
+	for _, exception := range page.Items {
+		if !strings.HasPrefix(exception.Identifier, "*") {
+			t.Errorf("identifier %q is not masked", exception.Identifier)
+		}
+	}
+}
+
+func TestV1ExceptionsWithoutMatches(t *testing.T) {
+	srv := newTestServer(t, nil)
+	runs, err := srv.runsOf("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A run reconciled with matches=none keeps its discrepant pairs only
+	run := largeRun(1)
+	run.Result.DiscrepantMatches = run.Result.Matches[2:]
+	run.Result.Matches = nil
+	if err := runs.Save(&run); err != nil {
+		t.Fatal(err)
+	}
+
+	page := getPage[model.Exception](t, srv, "/api/v1/runs/"+run.ID+"/exceptions?status=discrepancy", http.StatusOK)
+	if page.Total != 1 || page.Items[0].ID != "discrepancy-1" || page.Items[0].Residual != 5 || page.Items[0].Match == nil {
+		t.Errorf("discrepancies = %+v", page.Items)
+	}
+	if matches := getPage[model.Match](t, srv, "/api/v1/runs/"+run.ID+"/matches", http.StatusOK); matches.Total != 0 {
+		t.Errorf("matches = %+v, want none kept", matches)
+	}
+}
+
+func TestV1RunsMatchesAndBanks(t *testing.T) {
+	srv := newTestServer(t, nil)
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, reconcileRequest(t))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("reconcile status = %d, body %s", rec.Code, rec.Body)
+		}
+	}
+	runs, _ := srv.runsOf("")
+	run := largeRun(3)
+	if err := runs.Save(&run); err != nil {
+		t.Fatal(err)
+	}
+	if err := runs.SaveAttachment(run.ID, signOffAttachment, []byte("%PDF")); err != nil {
+		t.Fatal(err)
+	}
+
+	overviews := getPage[model.RunOverview](t, srv, "/api/v1/runs", http.StatusOK)
+	if overviews.Total != 3 || overviews.Items[0].ID != run.ID || overviews.Items[0].Status != model.RunStatusSignedOff {
+		t.Fatalf("runs = %+v", overviews)
+	}
+	if got := overviews.Items[1]; got.Status != model.RunStatusOpen || got.Matched != 1 || got.Unmatched != 1 || len(got.Banks) != 1 || got.Banks[0] != "bank-a" {
+		t.Errorf("reconciled run = %+v", got)
+	}
+	if open := getPage[model.RunOverview](t, srv, "/api/v1/runs?status=open", http.StatusOK); open.Total != 2 {
+		t.Errorf("open runs = %d, want 2", open.Total)
+	}
+	if withB := getPage[model.RunOverview](t, srv, "/api/v1/runs?bank=bank-b", http.StatusOK); withB.Total != 1 {
+		t.Errorf("runs of bank-b = %d, want 1", withB.Total)
+	}
+	getPage[model.RunOverview](t, srv, "/api/v1/runs?min_amount=1", http.StatusBadRequest)
+
+	matches := getPage[model.Match](t, srv, "/api/v1/runs/"+run.ID+"/matches", http.StatusOK)
+	var statuses []string
+	for _, m := range matches.Items {
+		statuses = append(statuses, m.Status)
+	}
+	if fmt.Sprint(statuses) != "[exact explained discrepancy]" {
+		t.Errorf("match statuses = %v", statuses)
+	}
+	if discrepancies := getPage[model.Match](t, srv, "/api/v1/runs/"+run.ID+"/matches?status=discrepancy", http.StatusOK); discrepancies.Total != 1 || discrepancies.Items[0].ID != "match-3" {
+		t.Errorf("discrepancies = %+v", discrepancies)
+	}
+
+	banks := getPage[model.Bank](t, srv, "/api/v1/banks?sort=-runs", http.StatusOK)
+	if banks.Total != 2 || banks.Items[0].Name != "bank-a" || banks.Items[0].Runs != 3 || banks.Items[1].Runs != 1 || banks.Items[0].LastRunAt == nil {
+		t.Errorf("banks = %+v", banks.Items)
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/store"
+	"github.com/arham-abiyan/reconciliation/internal/webhook"
+)
+
+// notify sends the events of a stored run to the webhooks of its tenant, in the background.
+// Runs are notified as they are stored, before anyone could sign them off.
+func (s *Server) notify(run model.Run) {
+	s.webhooks.Notify(run.Tenant, store.Overview(run))
+}
+
+// handleListDeliveries lists the webhook delivery attempts of the caller tenant, oldest first
+func (s *Server) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.FromContext(r.Context())
+	query := r.URL.Query()
+	filter := webhook.Filter{
+		Tenant:   principal.Tenant,
+		RunID:    query.Get("run_id"),
+		Endpoint: query.Get("endpoint"),
+		Event:    query.Get("event"),
+		Outcome:  query.Get("outcome"),
+	}
+
+	var err error
+	if filter.Since, err = parseTime(query.Get("since")); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{Success: false, Error: "since " + err.Error()})
+		return
+	}
+	if filter.Until, err = parseTime(query.Get("until")); err != nil {
+		sendJSONResponse(w, http.StatusBadRequest, APIResponse{Success: false, Error: "until " + err.Error()})
+		return
+	}
+
+	// Without webhooks there is no delivery log
+	deliveries := make([]webhook.Delivery, 0)
+	if s.deliveries != nil {
+		if deliveries, err = s.deliveries.Query(filter); err != nil {
+			sendJSONResponse(w, http.StatusInternalServerError, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Error reading delivery log: %v", err),
+			})
+			return
+		}
+	}
+
+	sendJSONResponse(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data:    deliveries,
+	})
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/webhook"
+)
+
+func TestWebhooks(t *testing.T) {
+	const secret = "0123456789abcdef"
+	var mu sync.Mutex
+	var events []webhook.Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(secret, r.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			t.Errorf("delivery signature: %v", err)
+		}
+		var event webhook.Event
+		json.Unmarshal(body, &event)
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	srv := newTestServer(t, func(cfg *config.Config) {
+		cfg.Webhooks.DeliveryLog = filepath.Join(t.TempDir(), "deliveries.log")
+		cfg.Webhooks.Endpoints = []webhook.Endpoint{{Name: "erp", URL: receiver.URL, Secret: secret}}
+	})
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, reconcileRequest(t))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reconcile status = %d, body %s", rec.Code, rec.Body)
+	}
+	runID := rec.Header().Get("X-Run-ID")
+	srv.webhooks.Close(context.Background())
+
+	// The run has an unmatched transaction, beyond the default threshold of the endpoint
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want completed and threshold exceeded", events)
+	}
+	for _, event := range events {
+		if event.Run.ID != runID || event.Run.Matched != 1 || event.Run.Unmatched != 1 {
+			t.Errorf("%s event = %+v", event.Type, event)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/webhooks/deliveries?outcome=delivered&run_id="+runID, nil))
+	var response struct {
+		Data []webhook.Delivery `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %v", rec.Code, err)
+	}
+	if len(response.Data) != 2 || response.Data[0].Endpoint != "erp" || response.Data[0].StatusCode != http.StatusOK {
+		t.Errorf("deliveries = %+v", response.Data)
+	}
+}
@@ -0,0 +1,201 @@
+package reconciliation
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+const (
+	openingBalanceMarker = "opening_balance"
+	closingBalanceMarker = "closing_balance"
+)
+
+// isBalanceMarker reports whether a bank CSV row carries a statement balance instead of a line
+func isBalanceMarker(identifier string) bool {
+	identifier = strings.ToLower(strings.TrimSpace(identifier))
+	return identifier == openingBalanceMarker || identifier == closingBalanceMarker
+}
+
+// balanceRows collects the opening_balance and closing_balance rows of a bank CSV as parseCSV reads them.
+// The rows may be anywhere in the file, usually right below the header or at the bottom.
+type balanceRows struct {
+	balance                model.StatementBalance
+	hasOpening, hasClosing bool
+}
+
+// add reads a balance row, rows without an amount are ignored
+func (b *balanceRows) add(record []string) error {
+	if len(record) < 2 {
+		return nil
+	}
+
+	amount, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s in %s: %w", record[0], b.balance.Bank, err)
+	}
+
+	if strings.EqualFold(strings.TrimSpace(record[0]), openingBalanceMarker) {
+		b.balance.Opening, b.hasOpening = amount, true
+	} else {
+		b.balance.Closing, b.hasClosing = amount, true
+	}
+	return nil
+}
+
+// result returns the statement balance, nil when the file does not carry both balances
+func (b *balanceRows) result() *model.StatementBalance {
+	if !b.hasOpening || !b.hasClosing {
+		return nil
+	}
+	balance := b.balance
+	return &balance
+}
+
+// ParseBalances reads statement balances given separately from the bank files.
+// Two formats are accepted:
+//   - CSV with a header and bank,opening_balance,closing_balance rows
+//   - MT940, using the :60F:/:60M: opening and :62F:/:62M: closing balance fields;
+//     the bank name is taken from name, the same way it is for bank CSV files
+func ParseBalances(r io.Reader, name string) ([]model.StatementBalance, error) {
+	reader := bufio.NewReader(r)
+	peek, _ := reader.Peek(64)
+	trimmed := strings.TrimLeft(string(peek), " \t\r\n")
+	if strings.HasPrefix(trimmed, ":") || strings.HasPrefix(trimmed, "{") {
+		balance, err := parseMT940Balance(reader, extractBaseName(name))
+		if err != nil {
+			return nil, err
+		}
+		return []model.StatementBalance{balance}, nil
+	}
+
+	csvReader := csv.NewReader(reader)
+	if _, err := csvReader.Read(); err == io.EOF {
+		return nil, fmt.Errorf("balances file is empty")
+	} else if err != nil {
+		return nil, err
+	}
+
+	balances := make([]model.StatementBalance, 0)
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		if len(record) < 3 {
+			return nil, fmt.Errorf("balances line %d: expected bank,opening_balance,closing_balance", line)
+		}
+
+		opening, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("balances line %d: invalid opening balance: %w", line, err)
+		}
+		closing, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("balances line %d: invalid closing balance: %w", line, err)
+		}
+
+		balances = append(balances, model.StatementBalance{
+			Bank:    strings.TrimSpace(record[0]),
+			Opening: opening,
+			Closing: closing,
+		})
+	}
+
+	return balances, nil
+}
+
+// parseMT940Balance extracts the first opening and the last closing balance of an MT940 statement.
+// A balance field looks like C241201IDR1000000,00: debit/credit mark, YYMMDD date, currency and amount.
+func parseMT940Balance(r io.Reader, bank string) (model.StatementBalance, error) {
+	balance := model.StatementBalance{Bank: bank}
+	var hasOpening, hasClosing bool
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case !hasOpening && (strings.HasPrefix(line, ":60F:") || strings.HasPrefix(line, ":60M:")):
+			amount, err := parseMT940Amount(line[5:])
+			if err != nil {
+				return balance, fmt.Errorf("invalid opening balance %q: %w", line, err)
+			}
+			balance.Opening, hasOpening = amount, true
+		case strings.HasPrefix(line, ":62F:") || strings.HasPrefix(line, ":62M:"):
+			amount, err := parseMT940Amount(line[5:])
+			if err != nil {
+				return balance, fmt.Errorf("invalid closing balance %q: %w", line, err)
+			}
+			balance.Closing, hasClosing = amount, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return balance, err
+	}
+
+	if !hasOpening || !hasClosing {
+		return balance, fmt.Errorf("MT940 statement for %s has no opening or closing balance", bank)
+	}
+
+	return balance, nil
+}
+
+// parseMT940Amount parses the value of a balance field, e.g. C241201IDR1000000,00
+func parseMT940Amount(value string) (float64, error) {
+	if len(value) < 11 {
+		return 0, fmt.Errorf("balance field too short")
+	}
+
+	amount, err := strconv.ParseFloat(strings.Replace(value[10:], ",", ".", 1), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	switch value[0] {
+	case 'C':
+		return amount, nil
+	case 'D':
+		return -amount, nil
+	default:
+		return 0, fmt.Errorf("unknown debit/credit mark %q", value[0])
+	}
+}
+
+// verifyBalances checks opening + sum(lines) = closing for every bank with known balances.
+// Credits add to the balance and debits subtract from it, every line of the statement counts,
+// whatever the reconciliation timeframe is.
+func verifyBalances(statements []model.BankStatement, balances []model.StatementBalance) []model.BalanceCheck {
+	totals := make(map[string]float64)
+	for _, stmt := range statements {
+		if stmt.Type == "DEBIT" {
+			totals[stmt.Bank] -= stmt.Amount
+		} else {
+			totals[stmt.Bank] += stmt.Amount
+		}
+	}
+
+	checks := make([]model.BalanceCheck, 0, len(balances))
+	for _, balance := range balances {
+		linesTotal := totals[balance.Bank]
+		difference := balance.Closing - (balance.Opening + linesTotal)
+		checks = append(checks, model.BalanceCheck{
+			Bank:       balance.Bank,
+			Opening:    balance.Opening,
+			Closing:    balance.Closing,
+			LinesTotal: linesTotal,
+			Difference: difference,
+			Balanced:   absDiff(difference, 0) < 0.005,
+		})
+	}
+
+	return checks
+}
@@ -0,0 +1,134 @@
+package reconciliation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+const systemSource = "system"
+
+// Reasons of a duplicate
+// DuplicateExactKey: The records share their identifier
+// DuplicateLikely: The records share amount, type, date and description
+const (
+	DuplicateExactKey = "exact_key"
+	DuplicateLikely   = "likely"
+)
+
+// duplicateGroup holds every record of a source sharing the same key
+type duplicateGroup[T any] struct {
+	source, key string
+	records     []T
+}
+
+// findDuplicates splits records into the ones used for matching and groups of duplicates.
+// The first occurrence of a key per source is kept for matching, later occurrences are only
+// reported through the exact groups. Likely groups are records with distinct keys but an equal
+// fingerprint; they stay in unique since they may be legitimate, and are reported for review.
+// An empty fingerprint opts the record out of likely duplicate detection.
+func findDuplicates[T any](records []T, source, key, fingerprint func(T) string) (unique []T, exact, likely []duplicateGroup[T]) {
+	unique = make([]T, 0, len(records))
+	exact = groupBy(records, source, key, func(first T) {
+		unique = append(unique, first)
+	})
+	likely = groupBy(unique, source, fingerprint, nil)
+
+	return unique, exact, likely
+}
+
+// groupBy groups records by source and key, preserving the order of first appearance,
+// and returns only the groups with more than one record. Records with an empty key are skipped.
+// onFirst is called for the first record of every group.
+func groupBy[T any](records []T, source, key func(T) string, onFirst func(T)) []duplicateGroup[T] {
+	var groups []*duplicateGroup[T]
+	index := make(map[string]*duplicateGroup[T])
+
+	for _, record := range records {
+		k := key(record)
+		if k == "" {
+			if onFirst != nil {
+				onFirst(record)
+			}
+			continue
+		}
+
+		src := source(record)
+		id := src + "\x00" + k
+		if group, exists := index[id]; exists {
+			group.records = append(group.records, record)
+			continue
+		}
+
+		group := &duplicateGroup[T]{source: src, key: k, records: []T{record}}
+		index[id] = group
+		groups = append(groups, group)
+		if onFirst != nil {
+			onFirst(record)
+		}
+	}
+
+	repeated := make([]duplicateGroup[T], 0)
+	for _, group := range groups {
+		if len(group.records) > 1 {
+			repeated = append(repeated, *group)
+		}
+	}
+
+	return repeated
+}
+
+// fingerprint builds the likely duplicate key out of amount, type, date and description.
+// Records without a description are not fingerprinted, amount and date alone are too common to flag.
+func fingerprint(amount float64, trxType string, date time.Time, description string) string {
+	description = strings.ToLower(strings.TrimSpace(description))
+	if description == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%.2f|%s|%s|%s", amount, trxType, date.Format("2006-01-02"), description)
+}
+
+// detectSystemDuplicates returns the system transactions used for matching and the duplicates found
+func detectSystemDuplicates(transactions []model.Transaction) ([]model.Transaction, []model.Duplicate) {
+	unique, exact, likely := findDuplicates(transactions,
+		func(model.Transaction) string { return systemSource },
+		func(tx model.Transaction) string { return tx.TrxID },
+		func(tx model.Transaction) string {
+			return fingerprint(tx.Amount, tx.Type, tx.TransactionTime, tx.Description)
+		},
+	)
+
+	duplicates := make([]model.Duplicate, 0, len(exact)+len(likely))
+	for _, group := range exact {
+		duplicates = append(duplicates, model.Duplicate{Source: group.source, Reason: DuplicateExactKey, Key: group.key, Transactions: group.records})
+	}
+	for _, group := range likely {
+		duplicates = append(duplicates, model.Duplicate{Source: group.source, Reason: DuplicateLikely, Key: group.key, Transactions: group.records})
+	}
+
+	return unique, duplicates
+}
+
+// detectBankDuplicates returns the bank statements used for matching and the duplicates found per bank
+func detectBankDuplicates(statements []model.BankStatement) ([]model.BankStatement, []model.Duplicate) {
+	unique, exact, likely := findDuplicates(statements,
+		func(stmt model.BankStatement) string { return stmt.Bank },
+		func(stmt model.BankStatement) string { return stmt.UniqueIdentifier },
+		func(stmt model.BankStatement) string {
+			return fingerprint(stmt.Amount, stmt.Type, stmt.Date, stmt.Description)
+		},
+	)
+
+	duplicates := make([]model.Duplicate, 0, len(exact)+len(likely))
+	for _, group := range exact {
+		duplicates = append(duplicates, model.Duplicate{Source: group.source, Reason: DuplicateExactKey, Key: group.key, Statements: group.records})
+	}
+	for _, group := range likely {
+		duplicates = append(duplicates, model.Duplicate{Source: group.source, Reason: DuplicateLikely, Key: group.key, Statements: group.records})
+	}
+
+	return unique, duplicates
+}
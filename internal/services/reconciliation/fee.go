@@ -0,0 +1,121 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	FeeFlat       = "flat"
+	FeePercentage = "percentage"
+	FeeTiered     = "tiered"
+)
+
+// FeeRule describes the fee a bank deducts from every settlement, so the bank amount is
+// expected to be the system amount minus the fee
+// Type: flat, percentage or tiered
+// Min/Cap: Optional lower and upper bounds applied to the computed fee, zero means unbounded
+type FeeRule struct {
+	Bank    string    `json:"bank"`
+	Type    string    `json:"type"`
+	Flat    float64   `json:"flat,omitempty"`
+	Percent float64   `json:"percent,omitempty"`
+	Tiers   []FeeTier `json:"tiers,omitempty"`
+	Min     float64   `json:"min,omitempty"`
+	Cap     float64   `json:"cap,omitempty"`
+}
+
+// FeeTier is a band of a tiered fee, tiers are evaluated in order and the first one
+// whose UpTo covers the amount applies. A zero UpTo covers any amount.
+type FeeTier struct {
+	UpTo    float64 `json:"up_to"`
+	Flat    float64 `json:"flat,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+}
+
+// Fee returns the expected fee for a settlement of amount
+func (r FeeRule) Fee(amount float64) float64 {
+	var fee float64
+	switch r.Type {
+	case FeeFlat:
+		fee = r.Flat
+	case FeePercentage:
+		fee = r.Flat + amount*r.Percent/100
+	case FeeTiered:
+		for _, tier := range r.Tiers {
+			if tier.UpTo == 0 || amount <= tier.UpTo {
+				fee = tier.Flat + amount*tier.Percent/100
+				break
+			}
+		}
+	}
+
+	if fee < r.Min {
+		fee = r.Min
+	}
+	if r.Cap > 0 && fee > r.Cap {
+		fee = r.Cap
+	}
+
+	return math.Round(fee*100) / 100
+}
+
+// Validate checks the rule is complete and consistent
+func (r FeeRule) Validate() error {
+	if r.Bank == "" {
+		return fmt.Errorf("fee rule bank is required")
+	}
+
+	switch r.Type {
+	case FeeFlat, FeePercentage:
+	case FeeTiered:
+		if len(r.Tiers) == 0 {
+			return fmt.Errorf("tiered fee rule for %s has no tiers", r.Bank)
+		}
+		// Only the last tier may be unbounded, the others must grow
+		for _, tier := range r.Tiers {
+			if tier.Flat < 0 || tier.Percent < 0 {
+				return fmt.Errorf("tiers of fee rule for %s cannot have negative values", r.Bank)
+			}
+		}
+		previous := 0.0
+		for _, tier := range r.Tiers[:len(r.Tiers)-1] {
+			if tier.UpTo <= previous {
+				return fmt.Errorf("tiers of fee rule for %s must have increasing up_to values", r.Bank)
+			}
+			previous = tier.UpTo
+		}
+		if last := r.Tiers[len(r.Tiers)-1].UpTo; last != 0 && last <= previous {
+			return fmt.Errorf("tiers of fee rule for %s must have increasing up_to values", r.Bank)
+		}
+	default:
+		return fmt.Errorf("unknown fee rule type %q for %s", r.Type, r.Bank)
+	}
+
+	if r.Flat < 0 || r.Percent < 0 || r.Min < 0 || r.Cap < 0 {
+		return fmt.Errorf("fee rule for %s cannot have negative values", r.Bank)
+	}
+	if r.Cap > 0 && r.Cap < r.Min {
+		return fmt.Errorf("fee rule for %s has a cap below its minimum", r.Bank)
+	}
+
+	return nil
+}
+
+// ParseFeeRules decodes a JSON array of fee rules and validates each of them
+func ParseFeeRules(r io.Reader) ([]FeeRule, error) {
+	var rules []FeeRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid fee rules: %w", err)
+	}
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,56 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+const (
+	// MatchesAll lists every matched pair in the result
+	MatchesAll = "all"
+	// MatchesImperfect lists only the matched pairs with an unexplained amount or a date difference
+	MatchesImperfect = "imperfect"
+	// MatchesNone leaves matched pairs out of the result, only counts are reported
+	MatchesNone = "none"
+
+	matchRuleID    = "trx_id"
+	matchRuleIDFee = "trx_id+fee"
+)
+
+// newMatchedPair describes a matched system transaction and bank statement line
+func newMatchedPair(sysTx model.Transaction, bankTx model.BankStatement, fee, residual float64) model.MatchedPair {
+	// Only pairs the fee rule of the bank explained a part of carry its rule
+	rule := matchRuleID
+	if fee > 0 {
+		rule = matchRuleIDFee
+	}
+
+	return model.MatchedPair{
+		System:        sysTx,
+		Statement:     bankTx,
+		Bank:          bankTx.Bank,
+		AmountDelta:   sysTx.Amount - bankTx.Amount,
+		Fee:           fee,
+		Residual:      residual,
+		DateDeltaDays: dayOf(bankTx.Date).Sub(dayOf(sysTx.TransactionTime)).Hours() / 24,
+		Rule:          rule,
+	}
+}
+
+// includeMatch tells whether the matched pair is part of the result for the given detail mode
+func includeMatch(pair model.MatchedPair, mode string) bool {
+	switch mode {
+	case MatchesNone:
+		return false
+	case MatchesImperfect:
+		return pair.Residual != 0 || pair.DateDeltaDays != 0
+	default:
+		return true
+	}
+}
+
+// dayOf truncates t to its calendar day
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,121 @@
+package reconciliation
+
+import (
+	"io"
+	"os"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// options holds the tunable parts of the matching process
+type options struct {
+	// transferWindowDays is the maximum number of days between the two legs of an internal transfer
+	transferWindowDays int
+	// feeRules holds the expected settlement fee rule per bank name
+	feeRules map[string]FeeRule
+	// balances holds statement balances given apart from the bank files, per bank name
+	balances map[string]model.StatementBalance
+	// matches tells which matched pairs are listed in the result, see MatchesAll
+	matches string
+	// systemName and bankNames hold the names the input files were given, their paths when empty
+	systemName string
+	bankNames  []string
+	// open reads the input files, os.Open unless set with WithOpener
+	open opener
+	// progress receives the progress of the reconciliation, nil unless set with WithProgress
+	progress func(Progress)
+}
+
+// opener opens an input file for reading
+type opener func(path string) (io.ReadCloser, error)
+
+// openFile opens a file of the file system
+func openFile(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// Option configures the reconciliation Service
+type Option func(*options)
+
+func defaultOptions() options {
+	return options{
+		transferWindowDays: 1,
+		feeRules:           make(map[string]FeeRule),
+		balances:           make(map[string]model.StatementBalance),
+		matches:            MatchesAll,
+		open:               openFile,
+	}
+}
+
+// WithTransferWindow sets the maximum number of days between the debit and credit of an internal transfer
+func WithTransferWindow(days int) Option {
+	return func(o *options) {
+		if days >= 0 {
+			o.transferWindowDays = days
+		}
+	}
+}
+
+// WithFeeRules registers the fee each bank deducts from settlements, a later rule for the same bank wins
+func WithFeeRules(rules ...FeeRule) Option {
+	return func(o *options) {
+		for _, rule := range rules {
+			o.feeRules[rule.Bank] = rule
+		}
+	}
+}
+
+// WithStatementBalances sets the opening and closing balances of bank statements,
+// they take precedence over the balance rows found in the bank files
+func WithStatementBalances(balances ...model.StatementBalance) Option {
+	return func(o *options) {
+		for _, balance := range balances {
+			o.balances[balance.Bank] = balance
+		}
+	}
+}
+
+// WithMatches sets which matched pairs are listed in the result: MatchesAll, MatchesImperfect or MatchesNone
+func WithMatches(mode string) Option {
+	return func(o *options) {
+		switch mode {
+		case MatchesAll, MatchesImperfect, MatchesNone:
+			o.matches = mode
+		}
+	}
+}
+
+// WithInputNames names input files stored under another name than the one they were given,
+// e.g. uploads stored by content. Bank names follow the order of the bank files, the name of a
+// bank file being the name of its bank.
+func WithInputNames(system string, banks ...string) Option {
+	return func(o *options) {
+		o.systemName = system
+		o.bankNames = banks
+	}
+}
+
+// WithOpener reads the input files with open instead of from the file system as they are,
+// e.g. to decrypt files stored encrypted
+func WithOpener(open func(path string) (io.ReadCloser, error)) Option {
+	return func(o *options) {
+		if open != nil {
+			o.open = open
+		}
+	}
+}
+
+// WithProgress reports the progress of the reconciliation to report as it advances. report is
+// called from the reconciling goroutine and should return quickly.
+func WithProgress(report func(Progress)) Option {
+	return func(o *options) {
+		o.progress = report
+	}
+}
+
+// report sends p to the progress callback, if any
+func (o options) report(p Progress) {
+	if o.progress != nil {
+		o.progress(p)
+	}
+}
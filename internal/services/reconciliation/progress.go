@@ -0,0 +1,42 @@
+package reconciliation
+
+// Stages of a reconciliation, as reported to the progress callback
+const (
+	// StageParsing reports the rows read so far from an input file
+	StageParsing = "parsing"
+	// StageParsed reports an input file was read entirely
+	StageParsed = "parsed"
+	// StageMatching reports a matching pass started, or the records it went through so far
+	StageMatching = "matching"
+	// StageDone reports the result is ready
+	StageDone = "done"
+)
+
+const (
+	// matchingPasses counts the passes over the records: reversals, duplicates, matching by
+	// identifier and internal transfers
+	matchingPasses = 4
+	// progressInterval is the number of rows between two reports within a file or a pass
+	progressInterval = 10000
+	// parsingShare is the share of the completion estimate given to parsing the input files
+	parsingShare = 50.0
+)
+
+// Progress describes how far a reconciliation went
+// File/Rows: The input file being read and its rows read so far, or the records a matching pass went through
+// Pass/Passes: The matching pass running, out of Passes
+// Percent: Estimated completion from 0 to 100
+type Progress struct {
+	Stage   string  `json:"stage"`
+	File    string  `json:"file,omitempty"`
+	Rows    int     `json:"rows,omitempty"`
+	Pass    int     `json:"pass,omitempty"`
+	Passes  int     `json:"passes,omitempty"`
+	Percent float64 `json:"percent"`
+}
+
+// passPercent estimates the completion of the matching passes, done being the share of the
+// current pass already went through
+func passPercent(pass int, done float64) float64 {
+	return 100 * (float64(pass-1) + done) / matchingPasses
+}
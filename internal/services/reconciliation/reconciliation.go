@@ -3,9 +3,11 @@ package reconciliation
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
+	"io/fs"
 	"math"
-	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,100 +19,313 @@ import (
 type Service struct {
 	bankCSV                       []string
 	systemCSV, startDate, endDate string
+	opts                          options
 }
 
 var _ services.Reconciliation = (*Service)(nil)
 
-func New(bankCSV []string, systemCSV, startDate, endDate string) *Service {
+func New(bankCSV []string, systemCSV, startDate, endDate string, opts ...Option) *Service {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return &Service{
 		bankCSV:   bankCSV,
 		systemCSV: systemCSV,
 		startDate: startDate,
 		endDate:   endDate,
+		opts:      o,
 	}
 }
 
 func (s *Service) Reconcile() (model.ReconcileResponse, error) {
-	systemTransactions, _, err := parseCSV(s.systemCSV, true)
+	files := 1 + len(s.bankCSV)
+	systemName := inputName(s.systemCSV, s.opts.systemName)
+	system, err := parseCSV(s.opts.open, s.systemCSV, systemName, true, s.parsing(systemName, 0, files))
 	if err != nil {
-		fmt.Println("Error parsing system transactions:", err)
 		return model.ReconcileResponse{}, err
 	}
+	parseErrors := system.parseErrors
 
 	var allBankStatements []model.BankStatement
-	for _, bankCSV := range s.bankCSV {
-		_, bankStatements, err := parseCSV(bankCSV, false)
+	var balances []model.StatementBalance
+	for i, bankCSV := range s.bankCSV {
+		name := bankCSV
+		if i < len(s.opts.bankNames) {
+			name = inputName(bankCSV, s.opts.bankNames[i])
+		}
+		bank, err := parseCSV(s.opts.open, bankCSV, name, false, s.parsing(name, i+1, files))
 		if err != nil {
-			fmt.Println("Error parsing bank statement:", err)
 			return model.ReconcileResponse{}, err
 		}
-		allBankStatements = append(allBankStatements, bankStatements...)
+		allBankStatements = append(allBankStatements, bank.statements...)
+		parseErrors = append(parseErrors, bank.parseErrors...)
+
+		if bank.balance != nil {
+			if _, given := s.opts.balances[bank.balance.Bank]; !given {
+				balances = append(balances, *bank.balance)
+			}
+		}
 	}
 
+	result := s.reconcile(system.transactions, allBankStatements, balances, parsingShare)
+	result.ParseErrors = parseErrors
+
+	return result, nil
+}
+
+// ReconcileRecords reconciles records read elsewhere, e.g. received over gRPC, instead of the files
+// of the service. Statement balances are only those set with WithStatementBalances.
+func (s *Service) ReconcileRecords(systemTransactions []model.Transaction, bankStatements []model.BankStatement) model.ReconcileResponse {
+	return s.reconcile(systemTransactions, bankStatements, nil, 0)
+}
+
+// parsing returns the progress callback of parseCSV reporting the index-th input file out of
+// files, nil when no progress callback is set
+func (s *Service) parsing(name string, index, files int) func(rows int, done float64, parsed bool) {
+	if s.opts.progress == nil {
+		return nil
+	}
+	file := filepath.Base(name)
+	return func(rows int, done float64, parsed bool) {
+		stage := StageParsing
+		if parsed {
+			stage = StageParsed
+		}
+		s.opts.report(Progress{Stage: stage, File: file, Rows: rows, Percent: parsingShare * (float64(index) + done) / float64(files)})
+	}
+}
+
+// reconcile matches the records within the timeframe of the service, balances are checked
+// against every bank statement along with the balances set with WithStatementBalances.
+// The matching passes are reported from start percent, what came before them took the rest.
+func (s *Service) reconcile(systemTransactions []model.Transaction, bankStatements []model.BankStatement, balances []model.StatementBalance, start float64) model.ReconcileResponse {
+	for _, balance := range s.opts.balances {
+		balances = append(balances, balance)
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Bank < balances[j].Bank })
+
 	// Filter transactions within the specified date range
 	filteredSystemTransactions := filterTransactions(systemTransactions, s.startDate, s.endDate, func(tx model.Transaction) time.Time {
 		return tx.TransactionTime
 	})
-	filteredBankStatements := filterTransactions(allBankStatements, s.startDate, s.endDate, func(tx model.BankStatement) time.Time {
+	filteredBankStatements := filterTransactions(bankStatements, s.startDate, s.endDate, func(tx model.BankStatement) time.Time {
 		return tx.Date
 	})
 
-	// Perform reconciliation
-	return reconcileTransactions(filteredSystemTransactions, filteredBankStatements), nil
+	// Perform reconciliation, the passes report their own completion which is scaled from start
+	opts := s.opts
+	if report := opts.progress; report != nil {
+		opts.progress = func(p Progress) {
+			p.Percent = start + p.Percent*(100-start)/100
+			report(p)
+		}
+	}
+	result := reconcileTransactions(filteredSystemTransactions, filteredBankStatements, opts)
+	result.BalanceChecks = verifyBalances(bankStatements, balances)
+	result.RecordsParsed = len(systemTransactions) + len(bankStatements)
+	s.opts.report(Progress{Stage: StageDone, Percent: 100})
+
+	return result
+}
+
+// inputName returns the name an input file was given, which is its path unless set with WithInputNames
+func inputName(path, name string) string {
+	if name == "" {
+		return path
+	}
+	return name
+}
+
+// ParseSystemFile parses a system transactions CSV file, invalid rows are skipped and reported as parse errors
+func ParseSystemFile(filePath string) ([]model.Transaction, []model.ParseError, error) {
+	parsed, err := parseCSV(openFile, filePath, filePath, true, nil)
+	return parsed.transactions, parsed.parseErrors, err
+}
+
+// ParseBankFile parses a bank statement CSV file, invalid rows are skipped and reported as parse errors
+func ParseBankFile(filePath string) ([]model.BankStatement, []model.ParseError, error) {
+	parsed, err := parseCSV(openFile, filePath, filePath, false, nil)
+	return parsed.statements, parsed.parseErrors, err
+}
+
+// parsedFile is what parseCSV read from an input file
+type parsedFile struct {
+	// transactions: The rows of a system transactions file
+	transactions []model.Transaction
+	// statements: The rows of a bank statement file, without its balance rows
+	statements []model.BankStatement
+	// balance: The opening and closing balance rows of a bank statement file, nil unless it carries both
+	balance *model.StatementBalance
+	// parseErrors: The rows that could not be parsed
+	parseErrors []model.ParseError
 }
 
 // parseCSV parses a CSV file into either system transactions or bank statements based on the isSystem flag
-func parseCSV(filePath string, isSystem bool) ([]model.Transaction, []model.BankStatement, error) {
-	file, err := os.Open(filePath)
+// name is the name the file was given, it names the bank and the file in parse errors
+// Rows that cannot be parsed are left out and described in the returned parse errors,
+// the error is only set when the file itself cannot be read
+// Rows are parsed as they are read. progress, when set, is called with the rows read so far and
+// the share of the file they make every progressInterval rows, and once the file is read entirely.
+// The opening_balance and closing_balance rows of a bank file are collected as its balance.
+func parseCSV(open opener, filePath, name string, isSystem bool, progress func(rows int, done float64, parsed bool)) (parsedFile, error) {
+	file, err := open(filePath)
 	if err != nil {
-		return nil, nil, err
+		return parsedFile{}, err
 	}
 	defer file.Close()
 
-	fileName := extractBaseName(filePath)
+	fileName := extractBaseName(name)
+	size := sizeOf(file)
 
 	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, nil, err
+	// Rows with a wrong number of columns are reported as parse errors instead of failing the file
+	reader.FieldsPerRecord = -1
+	if _, err := reader.Read(); err == io.EOF {
+		return parsedFile{}, fmt.Errorf("%s is empty", filepath.Base(name))
+	} else if err != nil {
+		return parsedFile{}, err
 	}
 
-	if isSystem {
-		transactions := make([]model.Transaction, 0, len(records)-1)
-		for _, record := range records[1:] {
-			amount, _ := strconv.ParseFloat(record[1], 64)
-			trxTime, _ := time.Parse("2006-01-02 15:04:05", record[3])
-			transactions = append(transactions, model.Transaction{
-				TrxID:           record[0],
-				Amount:          amount,
-				Type:            record[2],
-				TransactionTime: trxTime,
-			})
+	transactions := make([]model.Transaction, 0)
+	bankStatements := make([]model.BankStatement, 0)
+	parseErrors := make([]model.ParseError, 0)
+	balance := balanceRows{balance: model.StatementBalance{Bank: fileName}}
+	rows := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return parsedFile{}, err
+		}
+		// The line a record starts on, which is not its position in the file once quoted fields
+		// span several lines
+		line, _ := reader.FieldPos(0)
+		rows++
+		if progress != nil && rows%progressInterval == 0 {
+			done := 0.0
+			if size > 0 {
+				done = min(float64(reader.InputOffset())/float64(size), 1)
+			}
+			progress(rows, done, false)
+		}
+
+		if isSystem {
+			transaction, err := parseSystemRecord(record)
+			if err != nil {
+				parseErrors = append(parseErrors, model.ParseError{File: filepath.Base(name), Line: line, Message: err.Error()})
+				continue
+			}
+			transactions = append(transactions, transaction)
+			continue
+		}
+
+		if len(record) > 0 && isBalanceMarker(record[0]) {
+			if err := balance.add(record); err != nil {
+				return parsedFile{}, err
+			}
+			continue
+		}
+		statement, err := parseBankRecord(record, fileName)
+		if err != nil {
+			parseErrors = append(parseErrors, model.ParseError{File: filepath.Base(name), Line: line, Message: err.Error()})
+			continue
 		}
+		bankStatements = append(bankStatements, statement)
+	}
+	if progress != nil {
+		progress(rows, 1, true)
+	}
 
-		return transactions, nil, nil
+	if isSystem {
+		return parsedFile{transactions: transactions, parseErrors: parseErrors}, nil
 	}
+	return parsedFile{statements: bankStatements, balance: balance.result(), parseErrors: parseErrors}, nil
+}
 
-	bankStatements := make([]model.BankStatement, 0, len(records)-1)
-	for _, record := range records[1:] {
-		amount, _ := strconv.ParseFloat(record[1], 64)
-		date, _ := time.Parse("2006-01-02", record[2])
+// parseSystemRecord parses a row of a system transactions file, the error describes an invalid row
+func parseSystemRecord(record []string) (model.Transaction, error) {
+	if len(record) < 4 {
+		return model.Transaction{}, fmt.Errorf("expected at least 4 columns, got %d", len(record))
+	}
+	if record[0] == "" {
+		return model.Transaction{}, fmt.Errorf("missing transaction ID")
+	}
+	amount, err := strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return model.Transaction{}, fmt.Errorf("invalid amount %q", record[1])
+	}
+	if record[2] != "DEBIT" && record[2] != "CREDIT" {
+		return model.Transaction{}, fmt.Errorf("invalid type %q, expected DEBIT or CREDIT", record[2])
+	}
+	trxTime, err := time.Parse("2006-01-02 15:04:05", record[3])
+	if err != nil {
+		return model.Transaction{}, fmt.Errorf("invalid transaction time %q, expected YYYY-MM-DD HH:MM:SS", record[3])
+	}
 
-		trxType := "CREDIT"
-		if amount < 0 {
-			trxType = "DEBIT"
-		}
+	return model.Transaction{
+		TrxID:           record[0],
+		Amount:          amount,
+		Type:            record[2],
+		TransactionTime: trxTime,
+		Description:     optionalField(record, 4),
+	}, nil
+}
+
+// parseBankRecord parses a row of the statement of bank, the error describes an invalid row
+func parseBankRecord(record []string, bank string) (model.BankStatement, error) {
+	if len(record) < 3 {
+		return model.BankStatement{}, fmt.Errorf("expected at least 3 columns, got %d", len(record))
+	}
+	if record[0] == "" {
+		return model.BankStatement{}, fmt.Errorf("missing unique identifier")
+	}
+	amount, err := strconv.ParseFloat(record[1], 64)
+	if err != nil {
+		return model.BankStatement{}, fmt.Errorf("invalid amount %q", record[1])
+	}
+	date, err := time.Parse("2006-01-02", record[2])
+	if err != nil {
+		return model.BankStatement{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", record[2])
+	}
 
-		bankStatements = append(bankStatements, model.BankStatement{
-			UniqueIdentifier: record[0],
-			Amount:           math.Abs(amount),
-			Type:             trxType,
-			Date:             date,
-			Bank:             fileName,
-		})
+	trxType := "CREDIT"
+	if amount < 0 {
+		trxType = "DEBIT"
 	}
 
-	return nil, bankStatements, nil
+	return model.BankStatement{
+		UniqueIdentifier: record[0],
+		Amount:           math.Abs(amount),
+		Type:             trxType,
+		Date:             date,
+		Bank:             bank,
+		Description:      optionalField(record, 3),
+	}, nil
+}
+
+// sizeOf returns the size of an opened input file, 0 when it cannot tell
+func sizeOf(file io.Reader) int64 {
+	switch f := file.(type) {
+	case interface{ Stat() (fs.FileInfo, error) }:
+		if info, err := f.Stat(); err == nil {
+			return info.Size()
+		}
+	case interface{ Size() int64 }:
+		return f.Size()
+	}
+	return 0
+}
+
+// optionalField returns the trimmed value at index, or an empty string when the column is absent
+func optionalField(record []string, index int) string {
+	if index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
 }
 
 // filterTransactions filters transactions within a specified date range
@@ -141,60 +356,138 @@ func absDiff(a, b float64) float64 {
 	return b - a
 }
 
+// splitDifference splits the difference of a matched pair into the fee of the bank and the
+// unexplained residual. The fee only explains a bank amount below the system amount, up to the
+// expected fee of the rule, so an exact match books no fee. Without a fee rule the whole
+// difference is residual.
+func splitDifference(sysTx model.Transaction, bankTx model.BankStatement, opts options) (fee, residual float64) {
+	delta := sysTx.Amount - bankTx.Amount
+	rule, ok := opts.feeRules[bankTx.Bank]
+	if !ok || delta <= 0 {
+		return 0, math.Abs(delta)
+	}
+
+	fee = math.Round(math.Min(delta, rule.Fee(sysTx.Amount))*100) / 100
+	residual = delta - fee
+	if residual < 0.005 {
+		residual = 0
+	}
+
+	return fee, residual
+}
+
 // reconcileTransactions matches system transactions with bank statements
 // Returns counts of processed, matched, and unmatched transactions, along with discrepancies and unmatched records
-func reconcileTransactions(systemTransactions []model.Transaction, bankStatements []model.BankStatement) model.ReconcileResponse {
+// Duplicates within a source are reported separately, only the first occurrence of a key takes part in matching
+// Unmatched bank lines offsetting each other across banks are reported as internal transfers
+// Reversed entries are netted out of each source before anything else
+// Differences explained by the fee rule of the bank are reported as fees instead of discrepancies
+func reconcileTransactions(systemTransactions []model.Transaction, bankStatements []model.BankStatement, opts options) model.ReconcileResponse {
 	matched := 0
 	discrepancies := 0.0
+	fees := 0.0
 	totalProcessed := 0
 	unmatchedSystem := make([]model.Transaction, 0, len(systemTransactions))
 	unmatchedByBank := make(map[string][]model.BankStatement)
-	bankMap := make(map[string]model.BankStatement)
+	matches := make([]model.MatchedPair, 0)
+	var discrepantMatches []model.MatchedPair
+	summary := newBreakdown()
+
+	opts.report(Progress{Stage: StageMatching, Pass: 1, Passes: matchingPasses, Percent: passPercent(1, 0)})
+	systemReversals, systemTransactions := detectSystemReversals(systemTransactions)
+	bankReversals, bankStatements := detectBankReversals(bankStatements)
 
-	// Create a map of bank transactions for O(1) lookup
-	for _, bankTx := range bankStatements {
+	opts.report(Progress{Stage: StageMatching, Pass: 2, Passes: matchingPasses, Percent: passPercent(2, 0)})
+	systemTransactions, systemDuplicates := detectSystemDuplicates(systemTransactions)
+	bankStatements, bankDuplicates := detectBankDuplicates(bankStatements)
+
+	// Index bank transactions by identifier for O(1) lookup, the same identifier may exist in several banks
+	bankIndex := make(map[string][]int)
+	for i, bankTx := range bankStatements {
 		key := bankTx.UniqueIdentifier
-		bankMap[key] = bankTx
+		bankIndex[key] = append(bankIndex[key], i)
 	}
+	bankMatched := make([]bool, len(bankStatements))
 
 	// Match system transactions with bank transactions
+	opts.report(Progress{Stage: StageMatching, Pass: 3, Passes: matchingPasses, Percent: passPercent(3, 0)})
 	for _, sysTx := range systemTransactions {
 		key := sysTx.TrxID
 		totalProcessed++
-		if bankEntries, exists := bankMap[key]; exists {
+		if totalProcessed%progressInterval == 0 {
+			opts.report(Progress{
+				Stage:   StageMatching,
+				Rows:    totalProcessed,
+				Pass:    3,
+				Passes:  matchingPasses,
+				Percent: passPercent(3, float64(totalProcessed)/float64(len(systemTransactions))),
+			})
+		}
+		if candidates := bankIndex[key]; len(candidates) > 0 {
+			bankEntry := bankStatements[candidates[0]]
+			bankMatched[candidates[0]] = true
+			bankIndex[key] = candidates[1:]
 			matched++
-			discrepancies += absDiff(sysTx.Amount, bankEntries.Amount)
-			delete(bankMap, key)
+			fee, residual := splitDifference(sysTx, bankEntry, opts)
+			fees += fee
+			discrepancies += residual
+			summary.addMatch(sysTx, bankEntry, fee, residual)
+			pair := newMatchedPair(sysTx, bankEntry, fee, residual)
+			if includeMatch(pair, opts.matches) {
+				matches = append(matches, pair)
+			}
+			if residual != 0 {
+				discrepantMatches = append(discrepantMatches, pair)
+			}
 		} else {
 			unmatchedSystem = append(unmatchedSystem, sysTx)
+			summary.addUnmatchedSystem(sysTx)
 		}
 	}
 
-	// Collect unmatched bank transactions
-	for _, bankEntries := range bankMap {
-		_, ok := unmatchedByBank[bankEntries.Bank]
-		if ok {
-			unmatchedByBank[bankEntries.Bank] = append(unmatchedByBank[bankEntries.Bank], bankEntries)
+	// Collect unmatched bank transactions, leaving out the legs of internal transfers
+	unmatchedBank := make([]model.BankStatement, 0, len(bankStatements)-matched)
+	for i, bankEntry := range bankStatements {
+		if !bankMatched[i] {
+			unmatchedBank = append(unmatchedBank, bankEntry)
 		}
-
-		unmatchedByBank[bankEntries.Bank] = []model.BankStatement{bankEntries}
 	}
+	opts.report(Progress{Stage: StageMatching, Pass: 4, Passes: matchingPasses, Percent: passPercent(4, 0)})
+	internalTransfers, unmatchedBank := detectInternalTransfers(unmatchedBank, opts.transferWindowDays)
+	for _, bankEntry := range unmatchedBank {
+		unmatchedByBank[bankEntry.Bank] = append(unmatchedByBank[bankEntry.Bank], bankEntry)
+		summary.addUnmatchedBank(bankEntry)
+	}
+	byBank, daily := summary.summaries()
 
 	return model.ReconcileResponse{
-		UnmatchedSystem: unmatchedSystem,
-		Discrepancies:   discrepancies,
-		TotalProcessed:  totalProcessed,
-		Matched:         matched,
-		UnmatchedByBank: unmatchedByBank,
-		Unmatched:       len(bankMap) + len(unmatchedSystem),
+		UnmatchedSystem:   unmatchedSystem,
+		Discrepancies:     discrepancies,
+		Fees:              fees,
+		TotalProcessed:    totalProcessed,
+		Matched:           matched,
+		UnmatchedByBank:   unmatchedByBank,
+		Matches:           matches,
+		DiscrepantMatches: discrepantMatches,
+		ByBank:            byBank,
+		Daily:             daily,
+		Duplicates:        append(systemDuplicates, bankDuplicates...),
+		InternalTransfers: internalTransfers,
+		Reversals:         append(systemReversals, bankReversals...),
+		Unmatched:         len(unmatchedBank) + len(unmatchedSystem),
 	}
 }
 
+// BankName returns the name of the bank a statement file belongs to, as used in results and fee rules
+func BankName(filename string) string {
+	return extractBaseName(filename)
+}
+
 // extractBaseName extracts the base name from a file path or file name.
-// It removes the directory path, trims the ".csv" extension, and returns the last
+// It removes the directory path, trims the file extension (e.g. ".csv" or ".sta"), and returns the last
 func extractBaseName(filename string) string {
 	base := filepath.Base(filename)
-	withoutExt := strings.TrimSuffix(base, ".csv")
+	withoutExt := strings.TrimSuffix(base, filepath.Ext(base))
 	parts := strings.Split(withoutExt, "_")
 
 	if len(parts) > 1 {
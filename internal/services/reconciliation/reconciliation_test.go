@@ -1,9 +1,12 @@
 package reconciliation
 
 import (
+	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -103,6 +106,9 @@ func TestReconcileTransactions(t *testing.T) {
 		wantUnmatchedSys  int
 		wantUnmatchedBank int
 		wantDiscrepancies float64
+		wantDuplicates    int
+		wantTransfers     int
+		wantReversals     int
 	}{
 		{
 			name: "discrepancy",
@@ -153,11 +159,168 @@ func TestReconcileTransactions(t *testing.T) {
 			wantUnmatchedBank: 0,
 			wantDiscrepancies: 0.0,
 		},
+		{
+			name: "duplicate bank identifier",
+			systemTrx: []model.Transaction{
+				{TrxID: "T1", Amount: 100.0, Type: "DEBIT", TransactionTime: parseDate("2024-01-01")},
+			},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "T1", Amount: 100.0, Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "T1", Amount: 100.0, Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "B2", Amount: 150.0, Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "B2", Amount: 150.0, Date: parseDate("2024-01-01"), Bank: "bank-a"},
+			},
+			wantTotal:         1,
+			wantMatched:       1,
+			wantUnmatched:     1,
+			wantUnmatchedBank: 1,
+			wantDiscrepancies: 0.0,
+			wantDuplicates:    2,
+		},
+		{
+			name: "same identifier in different banks",
+			systemTrx: []model.Transaction{
+				{TrxID: "T1", Amount: 100.0, Type: "DEBIT", TransactionTime: parseDate("2024-01-01")},
+			},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "T1", Amount: 100.0, Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "T1", Amount: 100.0, Date: parseDate("2024-01-01"), Bank: "bank-b"},
+			},
+			wantTotal:         1,
+			wantMatched:       1,
+			wantUnmatched:     1,
+			wantUnmatchedBank: 1,
+			wantDiscrepancies: 0.0,
+		},
+		{
+			name: "likely duplicate system transactions",
+			systemTrx: []model.Transaction{
+				{TrxID: "T1", Amount: 100.0, Type: "DEBIT", Description: "Invoice 42", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "T2", Amount: 100.0, Type: "DEBIT", Description: "invoice 42 ", TransactionTime: parseDate("2024-01-01").Add(time.Hour)},
+				{TrxID: "T1", Amount: 100.0, Type: "DEBIT", Description: "Invoice 42", TransactionTime: parseDate("2024-01-01")},
+			},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "T1", Amount: 100.0, Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "T2", Amount: 100.0, Date: parseDate("2024-01-01"), Bank: "bank-a"},
+			},
+			wantTotal:         2,
+			wantMatched:       2,
+			wantUnmatched:     0,
+			wantDiscrepancies: 0.0,
+			wantDuplicates:    2,
+		},
+		{
+			name: "internal transfer between banks",
+			systemTrx: []model.Transaction{
+				{TrxID: "T1", Amount: 100.0, Type: "DEBIT", TransactionTime: parseDate("2024-01-01")},
+			},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "T1", Amount: 100.0, Type: "DEBIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "A9", Amount: 500.0, Type: "DEBIT", Date: parseDate("2024-01-02"), Bank: "bank-a"},
+				{UniqueIdentifier: "B9", Amount: 500.0, Type: "CREDIT", Date: parseDate("2024-01-03"), Bank: "bank-b"},
+				{UniqueIdentifier: "B10", Amount: 700.0, Type: "CREDIT", Date: parseDate("2024-01-03"), Bank: "bank-b"},
+			},
+			wantTotal:         1,
+			wantMatched:       1,
+			wantUnmatched:     1,
+			wantUnmatchedBank: 1,
+			wantDiscrepancies: 0.0,
+			wantTransfers:     1,
+		},
+		{
+			name:      "transfer legs rounding to neighbouring cents",
+			systemTrx: []model.Transaction{},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "A9", Amount: 500.004, Type: "DEBIT", Date: parseDate("2024-01-02"), Bank: "bank-a"},
+				{UniqueIdentifier: "B9", Amount: 500.006, Type: "CREDIT", Date: parseDate("2024-01-02"), Bank: "bank-b"},
+			},
+			wantTotal:         0,
+			wantMatched:       0,
+			wantUnmatched:     0,
+			wantUnmatchedBank: 0,
+			wantDiscrepancies: 0.0,
+			wantTransfers:     1,
+		},
+		{
+			name:      "offsetting lines in the same bank are not transfers",
+			systemTrx: []model.Transaction{},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "A9", Amount: 500.0, Type: "DEBIT", Date: parseDate("2024-01-02"), Bank: "bank-a"},
+				{UniqueIdentifier: "A10", Amount: 500.0, Type: "CREDIT", Date: parseDate("2024-01-02"), Bank: "bank-a"},
+				{UniqueIdentifier: "B9", Amount: 500.0, Type: "CREDIT", Date: parseDate("2024-01-09"), Bank: "bank-b"},
+			},
+			wantTotal:         0,
+			wantMatched:       0,
+			wantUnmatched:     3,
+			wantUnmatchedBank: 2,
+			wantDiscrepancies: 0.0,
+		},
+		{
+			name: "reversed payments are netted",
+			systemTrx: []model.Transaction{
+				{TrxID: "T1", Amount: 100.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "T2", Amount: 200.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "T2-REV", Amount: 200.0, Type: "DEBIT", TransactionTime: parseDate("2024-01-02")},
+			},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "T1", Amount: 100.0, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "B7", Amount: 300.0, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "C1", Amount: 300.0, Type: "DEBIT", Description: "Chargeback B7", Date: parseDate("2024-01-03"), Bank: "bank-a"},
+				{UniqueIdentifier: "B8", Amount: 400.0, Type: "CREDIT", Date: parseDate("2024-01-04"), Bank: "bank-a"},
+				{UniqueIdentifier: "B8", Amount: 400.0, Type: "DEBIT", Date: parseDate("2024-01-04"), Bank: "bank-a"},
+			},
+			wantTotal:         1,
+			wantMatched:       1,
+			wantUnmatched:     0,
+			wantDiscrepancies: 0.0,
+			wantReversals:     3,
+		},
+		{
+			name: "references are not matched by prefix",
+			systemTrx: []model.Transaction{
+				{TrxID: "T-1", Amount: 100.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "T-10", Amount: 100.0, Type: "DEBIT", TransactionTime: parseDate("2024-01-02")},
+				{TrxID: "T-2", Amount: 50.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "X9", Amount: 50.0, Type: "DEBIT", Description: "Refund of T-20", TransactionTime: parseDate("2024-01-02")},
+				{TrxID: "T-3", Amount: 70.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "X10", Amount: 70.0, Type: "DEBIT", Description: "Refund REV-T-30", TransactionTime: parseDate("2024-01-02")},
+			},
+			bankStmt:          []model.BankStatement{},
+			wantTotal:         6,
+			wantUnmatched:     6,
+			wantUnmatchedSys:  6,
+			wantDiscrepancies: 0.0,
+		},
+		{
+			name: "references are matched whole with their affixes",
+			systemTrx: []model.Transaction{
+				{TrxID: "T-1", Amount: 100.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "T-10", Amount: 100.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+				{TrxID: "REV-T-1", Amount: 100.0, Type: "DEBIT", TransactionTime: parseDate("2024-01-02")},
+				{TrxID: "X9", Amount: 100.0, Type: "DEBIT", Description: "Chargeback of T-10, see ticket", TransactionTime: parseDate("2024-01-02")},
+			},
+			bankStmt:          []model.BankStatement{},
+			wantDiscrepancies: 0.0,
+			wantReversals:     2,
+		},
+		{
+			name:      "reversal must come after the original",
+			systemTrx: []model.Transaction{},
+			bankStmt: []model.BankStatement{
+				{UniqueIdentifier: "REV-B7", Amount: 300.0, Type: "DEBIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+				{UniqueIdentifier: "B7", Amount: 300.0, Type: "CREDIT", Date: parseDate("2024-01-02"), Bank: "bank-a"},
+			},
+			wantTotal:         0,
+			wantMatched:       0,
+			wantUnmatched:     2,
+			wantUnmatchedBank: 1,
+			wantDiscrepancies: 0.0,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := reconcileTransactions(tt.systemTrx, tt.bankStmt)
+			result := reconcileTransactions(tt.systemTrx, tt.bankStmt, defaultOptions())
 
 			if result.TotalProcessed != tt.wantTotal {
 				t.Errorf("totalProcessed = %d, want %d", result.TotalProcessed, tt.wantTotal)
@@ -180,6 +343,281 @@ func TestReconcileTransactions(t *testing.T) {
 				t.Errorf("discrepancies = %.2f, want %.2f",
 					result.Discrepancies, tt.wantDiscrepancies)
 			}
+			if len(result.Duplicates) != tt.wantDuplicates {
+				t.Errorf("duplicates = %d, want %d", len(result.Duplicates), tt.wantDuplicates)
+			}
+			if len(result.InternalTransfers) != tt.wantTransfers {
+				t.Errorf("internal transfers = %d, want %d", len(result.InternalTransfers), tt.wantTransfers)
+			}
+			if len(result.Reversals) != tt.wantReversals {
+				t.Errorf("reversals = %d, want %d", len(result.Reversals), tt.wantReversals)
+			}
+		})
+	}
+}
+
+func TestReconcileTransactionsWithFees(t *testing.T) {
+	systemTrx := []model.Transaction{
+		{TrxID: "T1", Amount: 1000.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+		{TrxID: "T2", Amount: 2000.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+		{TrxID: "T3", Amount: 500.0, Type: "CREDIT", TransactionTime: parseDate("2024-01-01")},
+	}
+	bankStmt := []model.BankStatement{
+		{UniqueIdentifier: "T1", Amount: 985.0, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+		{UniqueIdentifier: "T2", Amount: 1960.0, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+		{UniqueIdentifier: "T3", Amount: 490.0, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-b"},
+	}
+
+	opts := defaultOptions()
+	WithFeeRules(FeeRule{Bank: "bank-a", Type: FeePercentage, Percent: 1.5})(&opts)
+
+	result := reconcileTransactions(systemTrx, bankStmt, opts)
+
+	// bank-a: 15 + 30 expected, T2 is 10 short of its fee; bank-b has no rule
+	if math.Abs(result.Fees-45.0) > 0.01 {
+		t.Errorf("fees = %.2f, want 45.00", result.Fees)
+	}
+	if math.Abs(result.Discrepancies-20.0) > 0.01 {
+		t.Errorf("discrepancies = %.2f, want 20.00", result.Discrepancies)
+	}
+}
+
+func TestSplitDifference(t *testing.T) {
+	opts := defaultOptions()
+	WithFeeRules(FeeRule{Bank: "bank-a", Type: FeeFlat, Flat: 2})(&opts)
+
+	tests := []struct {
+		name         string
+		bank         string
+		system, paid float64
+		wantFee      float64
+		wantResidual float64
+		wantRule     string
+	}{
+		{"exact match books no fee", "bank-a", 100, 100, 0, 0, matchRuleID},
+		{"fee deducted", "bank-a", 100, 98, 2, 0, matchRuleIDFee},
+		{"part of the fee deducted", "bank-a", 100, 99, 1, 0, matchRuleIDFee},
+		{"more than the fee deducted", "bank-a", 100, 95, 2, 3, matchRuleIDFee},
+		{"bank above the system", "bank-a", 100, 101, 0, 1, matchRuleID},
+		{"no fee rule", "bank-b", 100, 98, 0, 2, matchRuleID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sysTx := model.Transaction{TrxID: "T1", Amount: tt.system}
+			bankTx := model.BankStatement{UniqueIdentifier: "T1", Amount: tt.paid, Bank: tt.bank}
+			fee, residual := splitDifference(sysTx, bankTx, opts)
+			if math.Abs(fee-tt.wantFee) > 0.001 || math.Abs(residual-tt.wantResidual) > 0.001 {
+				t.Errorf("fee, residual = %.2f, %.2f, want %.2f, %.2f", fee, residual, tt.wantFee, tt.wantResidual)
+			}
+			if pair := newMatchedPair(sysTx, bankTx, fee, residual); pair.Rule != tt.wantRule {
+				t.Errorf("rule = %s, want %s", pair.Rule, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestReconcileTransactionsMatches(t *testing.T) {
+	systemTrx := []model.Transaction{
+		{TrxID: "T1", Amount: 100.0, Type: "CREDIT", TransactionTime: parseDateWithTime("2024-01-01 10:00:00")},
+		{TrxID: "T2", Amount: 200.0, Type: "CREDIT", TransactionTime: parseDateWithTime("2024-01-01 11:00:00")},
+		{TrxID: "T3", Amount: 300.0, Type: "CREDIT", TransactionTime: parseDateWithTime("2024-01-01 12:00:00")},
+	}
+	bankStmt := []model.BankStatement{
+		{UniqueIdentifier: "T1", Amount: 100.0, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+		{UniqueIdentifier: "T2", Amount: 190.0, Type: "CREDIT", Date: parseDate("2024-01-01"), Bank: "bank-a"},
+		{UniqueIdentifier: "T3", Amount: 300.0, Type: "CREDIT", Date: parseDate("2024-01-03"), Bank: "bank-b"},
+	}
+
+	tests := []struct {
+		mode    string
+		wantIDs []string
+	}{
+		{mode: MatchesAll, wantIDs: []string{"T1", "T2", "T3"}},
+		{mode: MatchesImperfect, wantIDs: []string{"T2", "T3"}},
+		{mode: MatchesNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			opts := defaultOptions()
+			WithMatches(tt.mode)(&opts)
+
+			result := reconcileTransactions(systemTrx, bankStmt, opts)
+			if result.Matched != 3 {
+				t.Errorf("matched = %d, want 3", result.Matched)
+			}
+			if len(result.Matches) != len(tt.wantIDs) {
+				t.Fatalf("matches = %d, want %d", len(result.Matches), len(tt.wantIDs))
+			}
+			for i, pair := range result.Matches {
+				if pair.System.TrxID != tt.wantIDs[i] || pair.Statement.UniqueIdentifier != tt.wantIDs[i] {
+					t.Errorf("match %d = %s/%s, want %s", i, pair.System.TrxID, pair.Statement.UniqueIdentifier, tt.wantIDs[i])
+				}
+			}
+			// Discrepancies are exceptions, they are kept whatever pairs are listed
+			if len(result.DiscrepantMatches) != 1 || result.DiscrepantMatches[0].System.TrxID != "T2" {
+				t.Errorf("discrepant matches = %+v, want T2", result.DiscrepantMatches)
+			}
+		})
+	}
+
+	result := reconcileTransactions(systemTrx, bankStmt, defaultOptions())
+	if pair := result.Matches[1]; pair.Bank != "bank-a" || pair.AmountDelta != 10.0 || pair.Residual != 10.0 || pair.Rule != matchRuleID {
+		t.Errorf("T2 match = %+v, want bank-a with a 10.00 delta", pair)
+	}
+	if pair := result.Matches[2]; pair.Bank != "bank-b" || pair.DateDeltaDays != 2 {
+		t.Errorf("T3 match = %+v, want bank-b two days later", pair)
+	}
+
+	wantBanks := []model.BankSummary{
+		{Bank: "bank-a", Matched: 2, Discrepancies: 10.0},
+		{Bank: "bank-b", Matched: 1},
+	}
+	if len(result.ByBank) != len(wantBanks) || result.ByBank[0] != wantBanks[0] || result.ByBank[1] != wantBanks[1] {
+		t.Errorf("by bank = %+v, want %+v", result.ByBank, wantBanks)
+	}
+	if len(result.Daily) != 1 || result.Daily[0] != (model.DailySummary{Date: "2024-01-01", Matched: 3}) {
+		t.Errorf("daily = %+v, want 3 matched on 2024-01-01", result.Daily)
+	}
+}
+
+func TestFeeRule(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   FeeRule
+		amount float64
+		want   float64
+	}{
+		{name: "flat", rule: FeeRule{Type: FeeFlat, Flat: 2500}, amount: 150000, want: 2500},
+		{name: "percentage", rule: FeeRule{Type: FeePercentage, Percent: 1.5}, amount: 150000, want: 2250},
+		{name: "percentage with fixed part", rule: FeeRule{Type: FeePercentage, Flat: 1000, Percent: 1}, amount: 150000, want: 2500},
+		{name: "percentage capped", rule: FeeRule{Type: FeePercentage, Percent: 1.5, Cap: 2000}, amount: 150000, want: 2000},
+		{name: "percentage minimum", rule: FeeRule{Type: FeePercentage, Percent: 1.5, Min: 500}, amount: 1000, want: 500},
+		{
+			name:   "tiered lower band",
+			rule:   FeeRule{Type: FeeTiered, Tiers: []FeeTier{{UpTo: 100000, Flat: 1000}, {Percent: 1}}},
+			amount: 100000,
+			want:   1000,
+		},
+		{
+			name:   "tiered open band",
+			rule:   FeeRule{Type: FeeTiered, Tiers: []FeeTier{{UpTo: 100000, Flat: 1000}, {Percent: 1}}},
+			amount: 150000,
+			want:   1500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Fee(tt.amount); math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("Fee(%.2f) = %.2f, want %.2f", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFeeRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "valid", input: `[{"bank":"bank-a","type":"percentage","percent":1.5,"cap":5000}]`},
+		{name: "unknown type", input: `[{"bank":"bank-a","type":"weird"}]`, wantErr: true},
+		{name: "missing bank", input: `[{"type":"flat","flat":10}]`, wantErr: true},
+		{name: "negative tier flat", input: `[{"bank":"bank-a","type":"tiered","tiers":[{"up_to":500,"flat":-10},{"percent":1}]}]`, wantErr: true},
+		{name: "negative tier percent", input: `[{"bank":"bank-a","type":"tiered","tiers":[{"up_to":500,"flat":10},{"percent":-1}]}]`, wantErr: true},
+		{name: "unordered tiers", input: `[{"bank":"bank-a","type":"tiered","tiers":[{"up_to":500},{"up_to":100},{}]}]`, wantErr: true},
+		{name: "malformed", input: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFeeRules(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFeeRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyBalances(t *testing.T) {
+	statements := []model.BankStatement{
+		{UniqueIdentifier: "A1", Amount: 500.0, Type: "CREDIT", Bank: "bank-a"},
+		{UniqueIdentifier: "A2", Amount: 200.0, Type: "DEBIT", Bank: "bank-a"},
+		{UniqueIdentifier: "B1", Amount: 100.0, Type: "CREDIT", Bank: "bank-b"},
+	}
+	balances := []model.StatementBalance{
+		{Bank: "bank-a", Opening: 1000.0, Closing: 1300.0},
+		{Bank: "bank-b", Opening: 1000.0, Closing: 1250.0},
+	}
+
+	checks := verifyBalances(statements, balances)
+	if len(checks) != 2 {
+		t.Fatalf("checks = %d, want 2", len(checks))
+	}
+	if !checks[0].Balanced || checks[0].LinesTotal != 300.0 {
+		t.Errorf("bank-a check = %+v, want balanced with lines total 300", checks[0])
+	}
+	if checks[1].Balanced || checks[1].Difference != 150.0 {
+		t.Errorf("bank-b check = %+v, want unbalanced by 150", checks[1])
+	}
+}
+
+func TestParseBalances(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		input   string
+		want    []model.StatementBalance
+		wantErr bool
+	}{
+		{
+			name:  "CSV",
+			file:  "balances.csv",
+			input: "bank,opening_balance,closing_balance\nbank-a,1000,1300\nbank-b,50.5,0\n",
+			want: []model.StatementBalance{
+				{Bank: "bank-a", Opening: 1000, Closing: 1300},
+				{Bank: "bank-b", Opening: 50.5, Closing: 0},
+			},
+		},
+		{
+			name:  "MT940",
+			file:  "uploads/balance_20241231_bank-a.sta",
+			input: ":20:STMT\n:25:123456\n:60F:C241201IDR1000,00\n:61:2412121212C500,00NTRFA1\n:62F:D241231IDR250,50\n",
+			want: []model.StatementBalance{
+				{Bank: "bank-a", Opening: 1000, Closing: -250.5},
+			},
+		},
+		{
+			name:    "MT940 without closing balance",
+			file:    "bank-a.sta",
+			input:   ":20:STMT\n:60F:C241201IDR1000,00\n",
+			wantErr: true,
+		},
+		{
+			name:    "CSV with invalid amount",
+			file:    "balances.csv",
+			input:   "bank,opening_balance,closing_balance\nbank-a,abc,1300\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBalances(strings.NewReader(tt.input), tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBalances() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseBalances() got %d balances, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("balance %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
 		})
 	}
 }
@@ -213,15 +651,56 @@ T3,300.00,DEBIT,2024-01-03 16:15:00`
 
 	// Test case 2: Bank statements CSV
 	bankCSVContent := `UniqueIdentifier,Amount,Date
+opening_balance,1000.00,2024-01-01
 T1,100.00,2024-01-01
 T2,-250.00,2024-01-02
-T3,300.00,2024-01-03`
+T3,300.00,2024-01-03
+closing_balance,1150.00,2024-01-03`
 
 	bankFilePath := filepath.Join(tmpDir, "bank.csv")
 	if err := os.WriteFile(bankFilePath, []byte(bankCSVContent), 0644); err != nil {
 		t.Fatalf("Failed to create bank test file: %v", err)
 	}
 
+	bank, err := parseCSV(openFile, bankFilePath, bankFilePath, false, nil)
+	if err != nil || bank.balance == nil {
+		t.Fatalf("parseCSV() balance = %v, %v, want balance rows", bank.balance, err)
+	}
+	if balance := *bank.balance; balance.Bank != "bank" || balance.Opening != 1000.00 || balance.Closing != 1150.00 {
+		t.Errorf("parseCSV() balance = %+v", balance)
+	}
+	if len(bank.statements) != 3 {
+		t.Errorf("parseCSV() got %d statements, want the 3 lines without the balance rows", len(bank.statements))
+	}
+
+	// Rows with invalid values are skipped and reported
+	invalidCSVContent := `TrxID,Amount,Type,TransactionTime
+T1,100.00,DEBIT,2024-01-01 10:30:00
+T2,abc,CREDIT,2024-01-02 14:45:00
+T3,300.00,REFUND,2024-01-03 16:15:00
+T4,400.00,DEBIT,2024-01-04
+T5,500.00
+,600.00,DEBIT,2024-01-06 09:00:00`
+
+	invalidFilePath := filepath.Join(tmpDir, "invalid.csv")
+	if err := os.WriteFile(invalidFilePath, []byte(invalidCSVContent), 0644); err != nil {
+		t.Fatalf("Failed to create invalid test file: %v", err)
+	}
+
+	// Quoted descriptions spanning several lines move the following rows down
+	multilineCSVContent := `TrxID,Amount,Type,TransactionTime,Description
+T1,100.00,DEBIT,2024-01-01 10:30:00,"Invoice 42
+paid in two parts
+by transfer"
+T2,abc,CREDIT,2024-01-02 14:45:00,"Refund
+of invoice 41"
+T3,300.00,REFUND,2024-01-03 16:15:00,`
+
+	multilineFilePath := filepath.Join(tmpDir, "multiline.csv")
+	if err := os.WriteFile(multilineFilePath, []byte(multilineCSVContent), 0644); err != nil {
+		t.Fatalf("Failed to create multiline test file: %v", err)
+	}
+
 	// Test case 3: Invalid file path
 	invalidPath := filepath.Join(tmpDir, "nonexistent.csv")
 
@@ -232,6 +711,7 @@ T3,300.00,2024-01-03`
 		wantErr      bool
 		wantSysTrx   []model.Transaction
 		wantBankStmt []model.BankStatement
+		wantErrLines []int
 	}{
 		{
 			name:     "Valid system transactions",
@@ -285,6 +765,36 @@ T3,300.00,2024-01-03`
 				},
 			},
 		},
+		{
+			name:     "Invalid system rows",
+			filePath: invalidFilePath,
+			isSystem: true,
+			wantErr:  false,
+			wantSysTrx: []model.Transaction{
+				{
+					TrxID:           "T1",
+					Amount:          100.00,
+					Type:            "DEBIT",
+					TransactionTime: parseDateWithTime("2024-01-01 10:30:00"),
+				},
+			},
+			wantErrLines: []int{3, 4, 5, 6, 7},
+		},
+		{
+			name:     "Invalid rows after multiline fields",
+			filePath: multilineFilePath,
+			isSystem: true,
+			wantErr:  false,
+			wantSysTrx: []model.Transaction{
+				{
+					TrxID:           "T1",
+					Amount:          100.00,
+					Type:            "DEBIT",
+					TransactionTime: parseDateWithTime("2024-01-01 10:30:00"),
+				},
+			},
+			wantErrLines: []int{5, 7},
+		},
 		{
 			name:     "Invalid file path",
 			filePath: invalidPath,
@@ -295,7 +805,8 @@ T3,300.00,2024-01-03`
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sysTrx, bankStmt, err := parseCSV(tt.filePath, tt.isSystem)
+			parsed, err := parseCSV(openFile, tt.filePath, tt.filePath, tt.isSystem, nil)
+			sysTrx, bankStmt, parseErrors := parsed.transactions, parsed.statements, parsed.parseErrors
 
 			// Check error condition
 			if (err != nil) != tt.wantErr {
@@ -307,6 +818,15 @@ T3,300.00,2024-01-03`
 				return
 			}
 
+			if len(parseErrors) != len(tt.wantErrLines) {
+				t.Fatalf("parseCSV() got %d parse errors, want %d: %+v", len(parseErrors), len(tt.wantErrLines), parseErrors)
+			}
+			for i, parseErr := range parseErrors {
+				if parseErr.Line != tt.wantErrLines[i] {
+					t.Errorf("parse error %d on line %d, want line %d", i, parseErr.Line, tt.wantErrLines[i])
+				}
+			}
+
 			// Check system transactions
 			if tt.isSystem {
 				if len(sysTrx) != len(tt.wantSysTrx) {
@@ -337,3 +857,158 @@ T3,300.00,2024-01-03`
 		})
 	}
 }
+
+func TestReconcileWithInputNames(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Files stored by content, their names only come from the option
+	systemPath := filepath.Join(tmpDir, "3f2a")
+	bankPath := filepath.Join(tmpDir, "9c1b")
+	if err := os.WriteFile(systemPath, []byte("trxID,amount,type,transactionTime\nT1,100,CREDIT,2024-01-02 10:00:00\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bankPath, []byte("unique_identifier,amount,date\nB1,50,2024-01-02\nB2,oops,2024-01-02\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := New([]string{bankPath}, systemPath, "2024-01-01", "2024-01-31",
+		WithInputNames("system.csv", "bank-x.csv"))
+	result, err := svc.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := result.UnmatchedByBank["bank-x"]; len(got) != 1 || got[0].Bank != "bank-x" {
+		t.Errorf("UnmatchedByBank = %+v, want one bank-x statement", result.UnmatchedByBank)
+	}
+	if len(result.ParseErrors) != 1 || result.ParseErrors[0].File != "bank-x.csv" {
+		t.Errorf("ParseErrors = %+v, want one error of bank-x.csv", result.ParseErrors)
+	}
+}
+
+func TestReconcileRecords(t *testing.T) {
+	day := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	system := []model.Transaction{
+		{TrxID: "T1", Amount: 100, Type: "CREDIT", TransactionTime: day},
+		{TrxID: "T2", Amount: 40, Type: "DEBIT", TransactionTime: day},
+		{TrxID: "T3", Amount: 10, Type: "CREDIT", TransactionTime: day.AddDate(0, 1, 0)},
+	}
+	bank := []model.BankStatement{
+		{UniqueIdentifier: "T1", Amount: 99, Type: "CREDIT", Date: day, Bank: "bank-x"},
+	}
+
+	svc := New(nil, "", "2024-01-01", "2024-01-31",
+		WithFeeRules(FeeRule{Bank: "bank-x", Type: FeeFlat, Flat: 1}),
+		WithStatementBalances(model.StatementBalance{Bank: "bank-x", Opening: 0, Closing: 99}))
+	result := svc.ReconcileRecords(system, bank)
+
+	if result.Matched != 1 || result.Unmatched != 1 || result.Fees != 1 || result.Discrepancies != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if result.RecordsParsed != 4 || result.TotalProcessed != 2 {
+		t.Errorf("RecordsParsed = %d, TotalProcessed = %d, want 4 and 2", result.RecordsParsed, result.TotalProcessed)
+	}
+	if len(result.BalanceChecks) != 1 || !result.BalanceChecks[0].Balanced {
+		t.Errorf("BalanceChecks = %+v", result.BalanceChecks)
+	}
+}
+
+func TestReconcileProgress(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// The system file spans two progress intervals, the bank file less than one
+	var system strings.Builder
+	system.WriteString("trxID,amount,type,transactionTime\n")
+	for i := 0; i < progressInterval+5; i++ {
+		fmt.Fprintf(&system, "T%05d,10,CREDIT,2024-01-02 10:00:00\n", i)
+	}
+	systemPath := filepath.Join(tmpDir, "system.csv")
+	bankPath := filepath.Join(tmpDir, "bank-x.csv")
+	if err := os.WriteFile(systemPath, []byte(system.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bankPath, []byte("unique_identifier,amount,date\nB1,50,2024-01-02\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []Progress
+	svc := New([]string{bankPath}, systemPath, "2024-01-01", "2024-01-31",
+		WithProgress(func(p Progress) { events = append(events, p) }))
+	if _, err := svc.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	var stages []string
+	passes := 0
+	for i, p := range events {
+		stages = append(stages, p.Stage)
+		if i > 0 && p.Percent < events[i-1].Percent {
+			t.Errorf("event %d went back from %v to %v percent", i, events[i-1].Percent, p.Percent)
+		}
+		if p.Stage == StageMatching && p.Rows == 0 {
+			passes++
+		}
+	}
+	want := []string{StageParsing, StageParsed, StageParsed}
+	if len(events) < 4 || strings.Join(stages[:3], ",") != strings.Join(want, ",") {
+		t.Fatalf("stages = %v, want parsing then parsed files first", stages)
+	}
+	if events[0].File != "system.csv" || events[0].Rows != progressInterval || events[1].Rows != progressInterval+5 || events[2].File != "bank-x.csv" || events[2].Percent != parsingShare {
+		t.Errorf("parsing events = %+v", events[:3])
+	}
+	if passes != matchingPasses {
+		t.Errorf("%d matching passes reported, want %d", passes, matchingPasses)
+	}
+	if last := events[len(events)-1]; last.Stage != StageDone || last.Percent != 100 {
+		t.Errorf("last event = %+v, want done at 100 percent", last)
+	}
+}
+
+// countingReader counts the bytes read from it
+type countingReader struct {
+	*strings.Reader
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += n
+	return n, err
+}
+
+func (r *countingReader) Close() error {
+	return nil
+}
+
+func TestParseCSVProgressWhileReading(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("trxID,amount,type,transactionTime\n")
+	for i := 0; i < 3*progressInterval; i++ {
+		fmt.Fprintf(&content, "T%05d,10,CREDIT,2024-01-02 10:00:00\n", i)
+	}
+	file := &countingReader{Reader: strings.NewReader(content.String())}
+	open := func(string) (io.ReadCloser, error) { return file, nil }
+
+	type report struct {
+		rows   int
+		done   float64
+		read   int
+		parsed bool
+	}
+	var reports []report
+	_, err := parseCSV(open, "system.csv", "system.csv", true, func(rows int, done float64, parsed bool) {
+		reports = append(reports, report{rows, done, file.read, parsed})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows are reported as they are read, not once the whole file is
+	if len(reports) != 4 || !reports[3].parsed || reports[3].rows != 3*progressInterval || reports[3].done != 1 {
+		t.Fatalf("reports = %+v", reports)
+	}
+	first := reports[0]
+	if first.rows != progressInterval || first.parsed || first.read >= content.Len() || first.done <= 0.3 || first.done >= 0.4 {
+		t.Errorf("first report = %+v, want a third of the file read", first)
+	}
+}
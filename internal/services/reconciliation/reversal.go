@@ -0,0 +1,214 @@
+package reconciliation
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+const (
+	reversalSameReference = "same_reference"
+	reversalReferenced    = "referenced"
+)
+
+// reversalView is the part of a record the reversal pass looks at
+type reversalView struct {
+	source, reference, direction, description string
+	amount                                    float64
+	date                                      time.Time
+}
+
+// reversalPair points at an original and its reversal in the input slice
+type reversalPair struct {
+	original, reversal int
+	rule               string
+}
+
+// reversalAffixes are the markers a reversal adds to the reference of its original
+var reversalAffixes = []struct{ prefix, suffix string }{
+	{"REV-", ""},
+	{"", "-REV"},
+	{"CB-", ""},
+}
+
+// reversalKey groups the records the reversal pass compares, amounts being kept in cents
+type reversalKey struct {
+	source, direction string
+	cents             int64
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// oppositeDirection returns the direction a reversal of a record of direction takes
+func oppositeDirection(direction string) string {
+	if direction == "DEBIT" {
+		return "CREDIT"
+	}
+	return "DEBIT"
+}
+
+// pairReversals pairs every record with an earlier record of the same source it reverses:
+// equal amount, opposite direction and a reference link. The reversal either reuses the
+// original reference, references it with a recognised affix (REV-, -REV, CB-) or mentions
+// it as a whole word of its description. Pairs sharing the reference are preferred, then the
+// closest original in time.
+// Returns the pairs found and the records left unpaired, in their original order.
+func pairReversals[T any](records []T, view func(T) reversalView) ([]reversalPair, []T) {
+	views := make([]reversalView, len(records))
+	candidates := make(map[reversalKey][]int)
+	for i, record := range records {
+		views[i] = view(record)
+		if views[i].reference != "" {
+			key := reversalKey{views[i].source, views[i].direction, cents(views[i].amount)}
+			candidates[key] = append(candidates[key], i)
+		}
+	}
+
+	paired := make([]bool, len(records))
+	pairs := make([]reversalPair, 0)
+	for j, rev := range views {
+		if paired[j] {
+			continue
+		}
+
+		// Amounts within half a cent of each other may round to neighbouring cents
+		best, bestRule := -1, ""
+		for delta := int64(-1); delta <= 1; delta++ {
+			key := reversalKey{rev.source, oppositeDirection(rev.direction), cents(rev.amount) + delta}
+			for _, i := range candidates[key] {
+				orig := views[i]
+				if i == j || paired[i] || math.Abs(orig.amount-rev.amount) > 0.005 {
+					continue
+				}
+				// The original never comes after its reversal
+				if orig.date.After(rev.date) || (orig.date.Equal(rev.date) && i > j) {
+					continue
+				}
+
+				rule := reversalRule(orig, rev)
+				if rule == "" {
+					continue
+				}
+				if best == -1 || betterReversal(rule, i, orig, bestRule, best, views[best]) {
+					best, bestRule = i, rule
+				}
+			}
+		}
+
+		if best == -1 {
+			continue
+		}
+		paired[best], paired[j] = true, true
+		pairs = append(pairs, reversalPair{original: best, reversal: j, rule: bestRule})
+	}
+
+	remaining := make([]T, 0, len(records)-2*len(pairs))
+	for i, record := range records {
+		if !paired[i] {
+			remaining = append(remaining, record)
+		}
+	}
+
+	return pairs, remaining
+}
+
+// reversalRule tells how rev references orig, or returns an empty string when it does not.
+// References are compared whole, so T-1 is never taken for a part of T-10.
+func reversalRule(orig, rev reversalView) string {
+	if rev.reference == orig.reference {
+		return reversalSameReference
+	}
+	if refersTo(rev.reference, orig.reference) {
+		return reversalReferenced
+	}
+	words := strings.FieldsFunc(rev.description, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	})
+	for _, word := range words {
+		if word == orig.reference || refersTo(word, orig.reference) {
+			return reversalReferenced
+		}
+	}
+	return ""
+}
+
+// refersTo reports whether reference is original marked with one of the reversal affixes
+func refersTo(reference, original string) bool {
+	for _, affix := range reversalAffixes {
+		if reference == affix.prefix+original+affix.suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// betterReversal reports whether candidate is a better original than current, the records
+// being at the given indexes of the input
+func betterReversal(rule string, i int, candidate reversalView, currentRule string, current int, currentView reversalView) bool {
+	if rule != currentRule {
+		return rule == reversalSameReference
+	}
+	if !candidate.date.Equal(currentView.date) {
+		return candidate.date.After(currentView.date)
+	}
+	return i < current
+}
+
+// detectSystemReversals nets out reversed system transactions
+func detectSystemReversals(transactions []model.Transaction) ([]model.Reversal, []model.Transaction) {
+	pairs, remaining := pairReversals(transactions, func(tx model.Transaction) reversalView {
+		return reversalView{
+			source:      systemSource,
+			reference:   tx.TrxID,
+			direction:   tx.Type,
+			description: tx.Description,
+			amount:      tx.Amount,
+			date:        tx.TransactionTime,
+		}
+	})
+
+	reversals := make([]model.Reversal, 0, len(pairs))
+	for _, pair := range pairs {
+		original := transactions[pair.original]
+		reversals = append(reversals, model.Reversal{
+			Source:       systemSource,
+			Rule:         pair.rule,
+			Amount:       original.Amount,
+			Transactions: []model.Transaction{original, transactions[pair.reversal]},
+		})
+	}
+
+	return reversals, remaining
+}
+
+// detectBankReversals nets out reversed bank statement lines, per bank
+func detectBankReversals(statements []model.BankStatement) ([]model.Reversal, []model.BankStatement) {
+	pairs, remaining := pairReversals(statements, func(stmt model.BankStatement) reversalView {
+		return reversalView{
+			source:      stmt.Bank,
+			reference:   stmt.UniqueIdentifier,
+			direction:   stmt.Type,
+			description: stmt.Description,
+			amount:      stmt.Amount,
+			date:        stmt.Date,
+		}
+	})
+
+	reversals := make([]model.Reversal, 0, len(pairs))
+	for _, pair := range pairs {
+		original := statements[pair.original]
+		reversals = append(reversals, model.Reversal{
+			Source:     original.Bank,
+			Rule:       pair.rule,
+			Amount:     original.Amount,
+			Statements: []model.BankStatement{original, statements[pair.reversal]},
+		})
+	}
+
+	return reversals, remaining
+}
@@ -0,0 +1,75 @@
+package reconciliation
+
+import (
+	"sort"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// breakdown accumulates the per-bank and per-day counts of a reconciliation
+type breakdown struct {
+	banks map[string]*model.BankSummary
+	days  map[string]*model.DailySummary
+}
+
+func newBreakdown() *breakdown {
+	return &breakdown{
+		banks: make(map[string]*model.BankSummary),
+		days:  make(map[string]*model.DailySummary),
+	}
+}
+
+func (b *breakdown) bank(name string) *model.BankSummary {
+	summary, ok := b.banks[name]
+	if !ok {
+		summary = &model.BankSummary{Bank: name}
+		b.banks[name] = summary
+	}
+	return summary
+}
+
+// day returns the summary of the calendar day of t, matched pairs count on the system transaction day
+func (b *breakdown) day(t time.Time) *model.DailySummary {
+	date := t.Format("2006-01-02")
+	summary, ok := b.days[date]
+	if !ok {
+		summary = &model.DailySummary{Date: date}
+		b.days[date] = summary
+	}
+	return summary
+}
+
+func (b *breakdown) addMatch(sysTx model.Transaction, bankTx model.BankStatement, fee, residual float64) {
+	bank := b.bank(bankTx.Bank)
+	bank.Matched++
+	bank.Fees += fee
+	bank.Discrepancies += residual
+	b.day(sysTx.TransactionTime).Matched++
+}
+
+func (b *breakdown) addUnmatchedSystem(sysTx model.Transaction) {
+	b.day(sysTx.TransactionTime).Unmatched++
+}
+
+func (b *breakdown) addUnmatchedBank(bankTx model.BankStatement) {
+	b.bank(bankTx.Bank).Unmatched++
+	b.day(bankTx.Date).Unmatched++
+}
+
+// summaries returns the bank summaries sorted by name and the daily summaries sorted by date
+func (b *breakdown) summaries() ([]model.BankSummary, []model.DailySummary) {
+	banks := make([]model.BankSummary, 0, len(b.banks))
+	for _, summary := range b.banks {
+		banks = append(banks, *summary)
+	}
+	sort.Slice(banks, func(i, j int) bool { return banks[i].Bank < banks[j].Bank })
+
+	days := make([]model.DailySummary, 0, len(b.days))
+	for _, summary := range b.days {
+		days = append(days, *summary)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	return banks, days
+}
@@ -0,0 +1,74 @@
+package reconciliation
+
+import (
+	"math"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// detectInternalTransfers pairs unmatched debits and credits of different banks that offset each other.
+// Both legs must have the same amount and be at most windowDays apart, the closest credit in date wins.
+// Returns the transfers found and the bank statements left unpaired, in their original order.
+func detectInternalTransfers(statements []model.BankStatement, windowDays int) ([]model.InternalTransfer, []model.BankStatement) {
+	window := time.Duration(windowDays) * 24 * time.Hour
+	paired := make([]bool, len(statements))
+	transfers := make([]model.InternalTransfer, 0)
+
+	// Credits are indexed by amount in cents, so each debit only looks at the credits it may offset
+	credits := make(map[int64][]int)
+	for j, credit := range statements {
+		if credit.Type == "CREDIT" {
+			key := cents(math.Abs(credit.Amount))
+			credits[key] = append(credits[key], j)
+		}
+	}
+
+	for i, debit := range statements {
+		if paired[i] || debit.Type != "DEBIT" {
+			continue
+		}
+
+		// Amounts within half a cent of each other may round to neighbouring cents
+		best := -1
+		var bestGap time.Duration
+		for delta := int64(-1); delta <= 1; delta++ {
+			for _, j := range credits[cents(math.Abs(debit.Amount))+delta] {
+				credit := statements[j]
+				if paired[j] || credit.Bank == debit.Bank {
+					continue
+				}
+				if math.Abs(credit.Amount-debit.Amount) > 0.005 {
+					continue
+				}
+
+				gap := credit.Date.Sub(debit.Date).Abs()
+				if gap > window {
+					continue
+				}
+				if best == -1 || gap < bestGap || (gap == bestGap && j < best) {
+					best, bestGap = j, gap
+				}
+			}
+		}
+
+		if best == -1 {
+			continue
+		}
+
+		paired[i], paired[best] = true, true
+		transfers = append(transfers, model.InternalTransfer{
+			From: debit,
+			To:   statements[best],
+		})
+	}
+
+	remaining := make([]model.BankStatement, 0, len(statements)-2*len(transfers))
+	for i, stmt := range statements {
+		if !paired[i] {
+			remaining = append(remaining, stmt)
+		}
+	}
+
+	return transfers, remaining
+}
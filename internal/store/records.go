@@ -0,0 +1,83 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// Exceptions lists the unmatched records and the discrepancies of result. Discrepancies are read
+// from the discrepant pairs of the result, and from its matched pairs for runs stored before them.
+func Exceptions(result model.ReconcileResponse) []model.Exception {
+	list := make([]model.Exception, 0)
+	for i, tx := range result.UnmatchedSystem {
+		list = append(list, model.Exception{
+			ID:          fmt.Sprintf("%s-%d", model.ExceptionUnmatchedSystem, i+1),
+			Status:      model.ExceptionUnmatchedSystem,
+			Identifier:  tx.TrxID,
+			Date:        tx.TransactionTime,
+			Amount:      tx.Amount,
+			Description: tx.Description,
+		})
+	}
+
+	banks := make([]string, 0, len(result.UnmatchedByBank))
+	for bank := range result.UnmatchedByBank {
+		banks = append(banks, bank)
+	}
+	slices.Sort(banks)
+	for _, bank := range banks {
+		for i, stmt := range result.UnmatchedByBank[bank] {
+			list = append(list, model.Exception{
+				ID:          fmt.Sprintf("%s-%s-%d", model.ExceptionUnmatchedBank, bank, i+1),
+				Status:      model.ExceptionUnmatchedBank,
+				Bank:        bank,
+				Identifier:  stmt.UniqueIdentifier,
+				Date:        stmt.Date,
+				Amount:      stmt.Amount,
+				Description: stmt.Description,
+			})
+		}
+	}
+
+	discrepant := result.DiscrepantMatches
+	if discrepant == nil {
+		for _, pair := range result.Matches {
+			if pair.Residual != 0 {
+				discrepant = append(discrepant, pair)
+			}
+		}
+	}
+	for i, pair := range discrepant {
+		list = append(list, model.Exception{
+			ID:          fmt.Sprintf("%s-%d", model.ExceptionDiscrepancy, i+1),
+			Status:      model.ExceptionDiscrepancy,
+			Bank:        pair.Bank,
+			Identifier:  pair.System.TrxID,
+			Date:        pair.System.TransactionTime,
+			Amount:      pair.System.Amount,
+			Description: pair.System.Description,
+			Residual:    pair.Residual,
+			Match:       &pair,
+		})
+	}
+
+	return list
+}
+
+// Matches lists the matched pairs of result with their status
+func Matches(result model.ReconcileResponse) []model.Match {
+	list := make([]model.Match, 0, len(result.Matches))
+	for i, pair := range result.Matches {
+		status := model.MatchExact
+		switch {
+		case pair.Residual != 0:
+			status = model.MatchDiscrepancy
+		case pair.AmountDelta != 0 || pair.DateDeltaDays != 0:
+			status = model.MatchExplained
+		}
+		list = append(list, model.Match{ID: fmt.Sprintf("match-%d", i+1), Status: status, MatchedPair: pair})
+	}
+	return list
+}
@@ -0,0 +1,434 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/envelope"
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// ErrNotFound is returned when a run does not exist
+var ErrNotFound = errors.New("run not found")
+
+var (
+	runIDPattern      = regexp.MustCompile(`^[0-9a-f]{32}$`)
+	attachmentPattern = regexp.MustCompile(`^[a-z0-9-]+\.[a-z0-9]+$`)
+)
+
+// Store persists reconciliation runs as one JSON file per run in a directory, next to index files
+// per run holding its overview, exceptions and matches, so runs are listed and their records
+// paged without reading their results
+type Store struct {
+	dir string
+	mu  sync.RWMutex
+	// keys encrypts the runs and their attachments, they are written in plain text when nil
+	keys *envelope.Keyring
+	// overviews caches the index entries of the runs once listed, nil before; Save and Delete keep
+	// it up to date under overviewsMu
+	overviews   map[string]model.RunOverview
+	overviewsMu sync.Mutex
+}
+
+// Index files of a run, next to its run file
+const (
+	indexOverview   = "index"
+	indexExceptions = "exceptions"
+	indexMatches    = "matches"
+)
+
+// New returns a store writing to dir, creating the directory when needed.
+// Runs and attachments are encrypted with keys, when not nil.
+func New(dir string, keys *envelope.Keyring) (*Store, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &Store{dir: dir, keys: keys}, nil
+}
+
+// Save assigns an ID and a creation time to the run and persists it
+func (s *Store) Save(run *model.Run) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	run.ID = hex.EncodeToString(id)
+	run.CreatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	if data, err = s.keys.Seal(data); err != nil {
+		return err
+	}
+
+	overview := Overview(*run)
+	indexes := []struct {
+		name string
+		v    any
+	}{
+		{indexOverview, overview},
+		{indexExceptions, Exceptions(run.Result)},
+		{indexMatches, Matches(run.Result)},
+	}
+
+	s.mu.Lock()
+	if err := s.write(s.path(run.ID), data); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	// A run without its index entries would not be listed, or its records not paged
+	for _, index := range indexes {
+		if err := s.writeIndex(run.ID, index.name, index.v); err != nil {
+			s.remove(run.ID)
+			s.mu.Unlock()
+			return err
+		}
+	}
+	s.mu.Unlock()
+
+	s.cache(overview)
+	return nil
+}
+
+// Delete removes a run with its index entry, or returns ErrNotFound. It rolls back a run whose
+// storage could not be completed, e.g. audited.
+func (s *Store) Delete(id string) error {
+	if !runIDPattern.MatchString(id) {
+		return ErrNotFound
+	}
+
+	s.mu.Lock()
+	err := s.remove(id)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.overviewsMu.Lock()
+	defer s.overviewsMu.Unlock()
+	if s.overviews != nil {
+		delete(s.overviews, id)
+	}
+	return nil
+}
+
+// remove removes the files of a run, the caller holds the write lock
+func (s *Store) remove(id string) error {
+	if err := os.Remove(s.path(id)); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	for _, name := range []string{indexOverview, indexExceptions, indexMatches} {
+		if err := os.Remove(s.indexPath(id, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the run with the given ID, or ErrNotFound
+func (s *Store) Get(id string) (model.Run, error) {
+	if !runIDPattern.MatchString(id) {
+		return model.Run{}, ErrNotFound
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := s.keys.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return model.Run{}, ErrNotFound
+	}
+	if err != nil {
+		return model.Run{}, err
+	}
+
+	var run model.Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return model.Run{}, fmt.Errorf("corrupted run %s: %w", id, err)
+	}
+
+	return run, nil
+}
+
+// List returns the overview of every stored run, the most recent first. Overviews are read from
+// the index once then kept in memory, entries missing from it are rebuilt from their run. Runs that
+// cannot be read are logged and left out, so one corrupted run does not hide the others.
+func (s *Store) List() ([]model.RunOverview, error) {
+	s.overviewsMu.Lock()
+	defer s.overviewsMu.Unlock()
+
+	if s.overviews == nil {
+		overviews, err := s.loadOverviews()
+		if err != nil {
+			return nil, err
+		}
+		s.overviews = overviews
+	}
+
+	list := make([]model.RunOverview, 0, len(s.overviews))
+	for _, overview := range s.overviews {
+		list = append(list, overview)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+
+	return list, nil
+}
+
+// loadOverviews reads the index entry of every stored run, the caller holds overviewsMu
+func (s *Store) loadOverviews() (map[string]model.RunOverview, error) {
+	s.mu.RLock()
+	entries, err := os.ReadDir(s.dir)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	overviews := make(map[string]model.RunOverview, len(entries))
+	for _, entry := range entries {
+		id, ok := runIDFromFile(entry.Name())
+		if !ok {
+			continue
+		}
+
+		overview, err := s.GetOverview(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Listing runs of %s: skipping run %s: %v", s.dir, id, err)
+			continue
+		}
+		overviews[id] = overview
+	}
+	return overviews, nil
+}
+
+// cache adds the overview of a stored run to the cached overviews, once they are loaded
+func (s *Store) cache(overview model.RunOverview) {
+	s.overviewsMu.Lock()
+	defer s.overviewsMu.Unlock()
+	if s.overviews != nil {
+		s.overviews[overview.ID] = overview
+	}
+}
+
+// Overview describes run with its totals, its status is open
+func Overview(run model.Run) model.RunOverview {
+	overview := model.RunOverview{
+		RunSummary:    model.RunSummary{ID: run.ID, CreatedAt: run.CreatedAt, Tenant: run.Tenant, CreatedBy: run.CreatedBy, Params: run.Params},
+		Status:        model.RunStatusOpen,
+		Banks:         make([]string, 0, len(run.Result.ByBank)),
+		Matched:       run.Result.Matched,
+		Unmatched:     run.Result.Unmatched,
+		Fees:          run.Result.Fees,
+		Discrepancies: run.Result.Discrepancies,
+	}
+	for _, summary := range run.Result.ByBank {
+		overview.Banks = append(overview.Banks, summary.Bank)
+	}
+	return overview
+}
+
+// GetOverview returns the overview of the run with the given ID from the index, or ErrNotFound.
+// Its status is open.
+func (s *Store) GetOverview(id string) (model.RunOverview, error) {
+	return readIndex(s, id, indexOverview, Overview)
+}
+
+// Exceptions returns the exceptions of the run with the given ID from the index, or ErrNotFound
+func (s *Store) Exceptions(id string) ([]model.Exception, error) {
+	return readIndex(s, id, indexExceptions, func(run model.Run) []model.Exception { return Exceptions(run.Result) })
+}
+
+// Matches returns the matched pairs of the run with the given ID from the index, or ErrNotFound
+func (s *Store) Matches(id string) ([]model.Match, error) {
+	return readIndex(s, id, indexMatches, func(run model.Run) []model.Match { return Matches(run.Result) })
+}
+
+// readIndex returns the index entry name of the run with the given ID, rebuilding it with build
+// from the run when it is missing, as for runs stored before the index
+func readIndex[T any](s *Store, id, name string, build func(model.Run) T) (T, error) {
+	var entry T
+	if !runIDPattern.MatchString(id) {
+		return entry, ErrNotFound
+	}
+
+	s.mu.RLock()
+	data, err := s.keys.ReadFile(s.indexPath(id, name))
+	s.mu.RUnlock()
+	if err == nil {
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return entry, fmt.Errorf("corrupted %s index of run %s: %w", name, id, err)
+		}
+		return entry, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return entry, err
+	}
+
+	run, err := s.Get(id)
+	if err != nil {
+		return entry, err
+	}
+	entry = build(run)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writeIndex(id, name, entry); err != nil {
+		log.Printf("Indexing %s of run %s: %v", name, id, err)
+	}
+	return entry, nil
+}
+
+// writeIndex stores the index entry name of a run, the caller holds the write lock
+func (s *Store) writeIndex(id, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if data, err = s.keys.Seal(data); err != nil {
+		return err
+	}
+	return s.write(s.indexPath(id, name), data)
+}
+
+// write writes then renames a file so a reader never sees it partially written, the caller
+// holds the write lock
+func (s *Store) write(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SaveAttachment stores a document attached to an existing run, replacing any previous one with the same name
+func (s *Store) SaveAttachment(id, name string, data []byte) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	if !attachmentPattern.MatchString(name) {
+		return fmt.Errorf("invalid attachment name %q", name)
+	}
+
+	data, err := s.keys.Seal(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.write(s.attachmentPath(id, name), data)
+}
+
+// GetAttachment returns a document attached to a run, or ErrNotFound
+func (s *Store) GetAttachment(id, name string) ([]byte, error) {
+	if !runIDPattern.MatchString(id) || !attachmentPattern.MatchString(name) {
+		return nil, ErrNotFound
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := s.keys.ReadFile(s.attachmentPath(id, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return data, err
+}
+
+// DeleteAttachment removes a document attached to a run, or returns ErrNotFound
+func (s *Store) DeleteAttachment(id, name string) error {
+	if !runIDPattern.MatchString(id) || !attachmentPattern.MatchString(name) {
+		return ErrNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.attachmentPath(id, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// HasAttachment tells whether a document is attached to a run
+func (s *Store) HasAttachment(id, name string) bool {
+	if !runIDPattern.MatchString(id) || !attachmentPattern.MatchString(name) {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := os.Stat(s.attachmentPath(id, name))
+	return err == nil
+}
+
+// overridesAttachment holds the overrides of a run, the run itself is never rewritten
+const overridesAttachment = "overrides.json"
+
+// Overrides returns the overrides of a run, oldest first, or ErrNotFound
+func (s *Store) Overrides(id string) ([]model.Override, error) {
+	overrides := make([]model.Override, 0)
+	data, err := s.GetAttachment(id, overridesAttachment)
+	if errors.Is(err, ErrNotFound) {
+		if _, err := os.Stat(s.path(id)); err != nil {
+			return nil, ErrNotFound
+		}
+		return overrides, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid overrides of run %s: %w", id, err)
+	}
+	return overrides, nil
+}
+
+// SaveOverrides replaces the overrides of an existing run
+func (s *Store) SaveOverrides(id string, overrides []model.Override) error {
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	return s.SaveAttachment(id, overridesAttachment, data)
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// indexPath is the path of an index entry of a run, it cannot clash with an attachment whose
+// name always has an extension
+func (s *Store) indexPath(id, name string) string {
+	return filepath.Join(s.dir, id+"."+name)
+}
+
+func (s *Store) attachmentPath(id, name string) string {
+	return filepath.Join(s.dir, id+"."+name)
+}
+
+func runIDFromFile(name string) (string, bool) {
+	id := name[:len(name)-len(filepath.Ext(name))]
+	return id, filepath.Ext(name) == ".json" && runIDPattern.MatchString(id)
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"os"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+func saveRun(t *testing.T, s *Store, banks ...string) model.Run {
+	t.Helper()
+
+	run := model.Run{Params: model.RunParams{StartDate: "2024-01-01", EndDate: "2024-01-31"}, Result: model.ReconcileResponse{Matched: 1, Unmatched: 2}}
+	for _, bank := range banks {
+		run.Result.ByBank = append(run.Result.ByBank, model.BankSummary{Bank: bank})
+	}
+	if err := s.Save(&run); err != nil {
+		t.Fatal(err)
+	}
+	return run
+}
+
+func TestList(t *testing.T) {
+	s, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := saveRun(t, s, "bank-a")
+	second := saveRun(t, s, "bank-a", "bank-b")
+	corrupted := saveRun(t, s)
+	unindexed := saveRun(t, s, "bank-c")
+
+	// Listing reads the index only, runs stored before the index are indexed when listed
+	if err := os.WriteFile(s.path(second.ID), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(s.indexPath(unindexed.ID, indexOverview)); err != nil {
+		t.Fatal(err)
+	}
+	// A run that cannot be read is left out
+	if err := os.Remove(s.indexPath(corrupted.ID, indexOverview)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(s.path(corrupted.ID), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].ID != unindexed.ID || list[1].ID != second.ID || list[2].ID != first.ID {
+		t.Fatalf("List() = %+v", list)
+	}
+	if got := list[1]; len(got.Banks) != 2 || got.Matched != 1 || got.Unmatched != 2 || got.Status != model.RunStatusOpen || got.Params.StartDate != "2024-01-01" {
+		t.Errorf("overview = %+v", got)
+	}
+	if _, err := os.Stat(s.indexPath(unindexed.ID, indexOverview)); err != nil {
+		t.Errorf("index entry of %s was not rebuilt: %v", unindexed.ID, err)
+	}
+}
+
+func TestListCache(t *testing.T) {
+	s, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := saveRun(t, s, "bank-a")
+	if list, err := s.List(); err != nil || len(list) != 1 {
+		t.Fatalf("List() = %+v, %v", list, err)
+	}
+
+	// Once listed, runs are listed from memory, kept up to date by Save and Delete
+	second := saveRun(t, s, "bank-b")
+	if err := s.Delete(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.List()
+	if err != nil || len(list) != 1 || list[0].ID != second.ID {
+		t.Errorf("List() = %+v, %v, want %s only", list, err, second.ID)
+	}
+	for _, name := range []string{indexOverview, indexExceptions, indexMatches} {
+		if _, err := os.Stat(s.indexPath(first.ID, name)); !os.IsNotExist(err) {
+			t.Errorf("%s index of the deleted run: %v", name, err)
+		}
+	}
+}
+
+func TestRecordIndexes(t *testing.T) {
+	s, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := model.Run{Result: model.ReconcileResponse{
+		UnmatchedSystem:   []model.Transaction{{TrxID: "S1"}},
+		Matches:           []model.MatchedPair{{Bank: "bank-a"}, {Bank: "bank-a", Residual: 5}},
+		DiscrepantMatches: []model.MatchedPair{{Bank: "bank-a", Residual: 5}},
+	}}
+	if err := s.Save(&run); err != nil {
+		t.Fatal(err)
+	}
+	// Records are read from their index, not from the run
+	if err := os.WriteFile(s.path(run.ID), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	exceptions, err := s.Exceptions(run.ID)
+	if err != nil || len(exceptions) != 2 || exceptions[1].Status != model.ExceptionDiscrepancy {
+		t.Errorf("Exceptions() = %+v, %v", exceptions, err)
+	}
+	matches, err := s.Matches(run.ID)
+	if err != nil || len(matches) != 2 || matches[1].Status != model.MatchDiscrepancy {
+		t.Errorf("Matches() = %+v, %v", matches, err)
+	}
+	if _, err := s.Exceptions("0123456789abcdef0123456789abcdef"); err != ErrNotFound {
+		t.Errorf("Exceptions() of a missing run = %v, want ErrNotFound", err)
+	}
+}
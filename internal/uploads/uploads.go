@@ -0,0 +1,232 @@
+package uploads
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/envelope"
+)
+
+// blobsDir is the directory of a store holding the blobs, named after their hash
+const blobsDir = "sha256"
+
+var sumPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Blob is a file of the store
+// Deduplicated: The same content was already stored, the existing blob was reused
+type Blob struct {
+	SHA256       string
+	Path         string
+	Size         int64
+	Deduplicated bool
+}
+
+// Policy tells which blobs the janitor removes, zero values disable a rule
+// MaxAge: Blobs not uploaded again for longer are removed
+// MaxTotalSize: Beyond this size, the least recently uploaded blobs are removed
+type Policy struct {
+	MaxAge       time.Duration
+	MaxTotalSize int64
+}
+
+// Store keeps uploaded files under the SHA-256 of their content, so identical uploads share a
+// single file and two uploads never overwrite each other
+type Store struct {
+	dir string
+	mu  sync.Mutex
+	// inUse counts the holders of every blob, held blobs are never removed
+	inUse map[string]int
+	// keys encrypts the blobs, they are written in plain text when nil
+	keys *envelope.Keyring
+}
+
+// New returns a store writing to dir, creating the directory when needed.
+// Blobs are encrypted with keys, when not nil, and still named after their plain content.
+func New(dir string, keys *envelope.Keyring) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, blobsDir), os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, inUse: make(map[string]int), keys: keys}, nil
+}
+
+// Save stores the content of r and holds the blob until Release is called with its hash.
+// Storing content already present reuses its blob and marks it as uploaded now.
+func (s *Store) Save(r io.Reader) (Blob, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, blobsDir), ".upload-*")
+	if err != nil {
+		return Blob{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	var dst io.Writer = tmp
+	// Sealing needs the whole content, uploads are bounded by the maximum request size
+	var plain bytes.Buffer
+	if s.keys != nil {
+		dst = &plain
+	}
+	size, err := io.Copy(io.MultiWriter(dst, hash), r)
+	if err == nil && s.keys != nil {
+		var sealed []byte
+		if sealed, err = s.keys.Seal(plain.Bytes()); err == nil {
+			_, err = tmp.Write(sealed)
+		}
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Blob{}, err
+	}
+
+	blob := Blob{SHA256: hex.EncodeToString(hash.Sum(nil)), Size: size}
+	blob.Path = s.path(blob.SHA256)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = os.Stat(blob.Path)
+	blob.Deduplicated = err == nil
+	// A blob stored before encryption was enabled is replaced by the sealed copy rather than kept
+	// in plain text until the next rekey
+	replace := !blob.Deduplicated
+	if blob.Deduplicated && s.keys != nil {
+		sealed, err := envelope.IsSealedFile(blob.Path)
+		if err != nil {
+			return Blob{}, err
+		}
+		replace = !sealed
+	}
+	if replace {
+		if err := os.Rename(tmp.Name(), blob.Path); err != nil {
+			return Blob{}, err
+		}
+	} else {
+		now := time.Now()
+		if err := os.Chtimes(blob.Path, now, now); err != nil {
+			return Blob{}, err
+		}
+	}
+	s.inUse[blob.SHA256]++
+
+	return blob, nil
+}
+
+// SaveFile stores an uploaded file, see Save
+func (s *Store) SaveFile(fileHeader *multipart.FileHeader) (Blob, error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return Blob{}, err
+	}
+	defer src.Close()
+
+	return s.Save(src)
+}
+
+// Release lets the janitor remove a blob again once nobody else holds it
+func (s *Store) Release(sum string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inUse[sum] <= 1 {
+		delete(s.inUse, sum)
+		return
+	}
+	s.inUse[sum]--
+}
+
+// Open opens a blob for reading, decrypting it when encrypted. The reader tells its Size, so
+// readers can report how far they went.
+func (s *Store) Open(path string) (io.ReadCloser, error) {
+	data, err := s.keys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return blobReader{bytes.NewReader(data)}, nil
+}
+
+// blobReader reads a decrypted blob, there is nothing to close
+type blobReader struct {
+	*bytes.Reader
+}
+
+func (blobReader) Close() error {
+	return nil
+}
+
+// Path returns the path of the blob with the given hash, it fails for an invalid hash
+func (s *Store) Path(sum string) (string, error) {
+	if !sumPattern.MatchString(sum) {
+		return "", fmt.Errorf("invalid SHA-256 %q", sum)
+	}
+	return s.path(sum), nil
+}
+
+func (s *Store) path(sum string) string {
+	return filepath.Join(s.dir, blobsDir, sum)
+}
+
+// Sweep removes the blobs policy no longer keeps, blobs held by a request are skipped.
+// It returns the number of blobs removed.
+func (s *Store) Sweep(policy Policy, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, blobsDir))
+	if err != nil {
+		return 0, err
+	}
+
+	type stored struct {
+		sum     string
+		size    int64
+		modTime time.Time
+	}
+	blobs := make([]stored, 0, len(entries))
+	var total int64
+	for _, entry := range entries {
+		if !sumPattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		blobs = append(blobs, stored{sum: entry.Name(), size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	// Oldest first, they go first when the store is too large
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].modTime.Before(blobs[j].modTime) })
+
+	removed := 0
+	for _, blob := range blobs {
+		expired := policy.MaxAge > 0 && now.Sub(blob.modTime) > policy.MaxAge
+		oversized := policy.MaxTotalSize > 0 && total > policy.MaxTotalSize
+		if (!expired && !oversized) || s.inUse[blob.sum] > 0 {
+			continue
+		}
+
+		if err := os.Remove(s.path(blob.sum)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		total -= blob.size
+		removed++
+	}
+
+	return removed, nil
+}
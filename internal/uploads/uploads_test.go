@@ -0,0 +1,154 @@
+package uploads
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/envelope"
+)
+
+func TestSave(t *testing.T) {
+	store, err := New(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "unique_identifier,amount,date\nB1,100,2024-12-02\n"
+	sum := sha256.Sum256([]byte(content))
+
+	first, err := store.Save(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.SHA256 != hex.EncodeToString(sum[:]) || first.Size != int64(len(content)) || first.Deduplicated {
+		t.Errorf("Save() = %+v, want a new blob named after its checksum", first)
+	}
+	if data, err := os.ReadFile(first.Path); err != nil || string(data) != content {
+		t.Errorf("stored content = %q, %v", data, err)
+	}
+
+	second, err := store.Save(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Deduplicated || second.Path != first.Path {
+		t.Errorf("Save() of the same content = %+v, want the first blob reused", second)
+	}
+
+	other, err := store.Save(strings.NewReader(content + "B2,50,2024-12-02\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Path == first.Path {
+		t.Error("different contents share a blob")
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	save := func(t *testing.T, store *Store, content string, age time.Duration) Blob {
+		t.Helper()
+		blob, err := store.Save(strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Release(blob.SHA256)
+		if err := os.Chtimes(blob.Path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+		return blob
+	}
+	exists := func(blob Blob) bool {
+		_, err := os.Stat(blob.Path)
+		return err == nil
+	}
+
+	t.Run("max age", func(t *testing.T) {
+		store, _ := New(t.TempDir(), nil)
+		old := save(t, store, "old", 48*time.Hour)
+		recent := save(t, store, "recent", time.Hour)
+
+		removed, err := store.Sweep(Policy{MaxAge: 24 * time.Hour}, now)
+		if err != nil || removed != 1 {
+			t.Fatalf("Sweep() = %d, %v, want 1 removed", removed, err)
+		}
+		if exists(old) || !exists(recent) {
+			t.Error("Sweep() should remove only the expired blob")
+		}
+	})
+
+	t.Run("max total size", func(t *testing.T) {
+		store, _ := New(t.TempDir(), nil)
+		oldest := save(t, store, "aaaa", 3*time.Hour)
+		older := save(t, store, "bbbb", 2*time.Hour)
+		newest := save(t, store, "cccc", time.Hour)
+
+		if _, err := store.Sweep(Policy{MaxTotalSize: 8}, now); err != nil {
+			t.Fatal(err)
+		}
+		if exists(oldest) || !exists(older) || !exists(newest) {
+			t.Error("Sweep() should remove the oldest blobs until the store fits")
+		}
+	})
+
+	t.Run("in use", func(t *testing.T) {
+		store, _ := New(t.TempDir(), nil)
+		blob := save(t, store, "held", 48*time.Hour)
+		if _, err := store.Save(strings.NewReader("held")); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(blob.Path, now.Add(-48*time.Hour), now.Add(-48*time.Hour))
+
+		if removed, _ := store.Sweep(Policy{MaxAge: time.Hour}, now); removed != 0 || !exists(blob) {
+			t.Error("Sweep() removed a blob in use")
+		}
+		store.Release(blob.SHA256)
+		if removed, _ := store.Sweep(Policy{MaxAge: time.Hour}, now); removed != 1 {
+			t.Error("Sweep() kept a released blob")
+		}
+	})
+}
+
+func TestSaveSealsPlainBlob(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := New(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "unique_identifier,amount,date\nB1,100,2024-12-02\n"
+	stored, err := plain.Save(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := envelope.NewKey("k1", bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := envelope.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := New(dir, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := sealed.Save(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blob.Deduplicated || blob.Path != stored.Path {
+		t.Errorf("Save() = %+v, want the plain blob reused", blob)
+	}
+	if ok, err := envelope.IsSealedFile(blob.Path); err != nil || !ok {
+		t.Errorf("blob sealed = %v, %v, want the plain blob sealed again", ok, err)
+	}
+	if data, err := keys.ReadFile(blob.Path); err != nil || string(data) != content {
+		t.Errorf("stored content = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,201 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Outcomes of a delivery attempt
+const (
+	OutcomeDelivered = "delivered"
+	OutcomeRetrying  = "retrying"
+	OutcomeFailed    = "failed"
+)
+
+// Delivery is a record of the delivery log, one per attempt
+// Attempt: 1-based attempt of the event to the endpoint
+// StatusCode: Status answered by the endpoint, zero when it could not be reached
+// NextAttempt: Set when the delivery is retried, when the next attempt is made
+type Delivery struct {
+	Time        time.Time  `json:"time"`
+	EventID     string     `json:"event_id"`
+	Event       string     `json:"event"`
+	Tenant      string     `json:"tenant,omitempty"`
+	RunID       string     `json:"run_id"`
+	Endpoint    string     `json:"endpoint"`
+	Attempt     int        `json:"attempt"`
+	Outcome     string     `json:"outcome"`
+	StatusCode  int        `json:"status_code,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	DurationMS  int64      `json:"duration_ms"`
+	NextAttempt *time.Time `json:"next_attempt,omitempty"`
+}
+
+// Filter selects deliveries of the log, empty fields match any delivery
+type Filter struct {
+	Tenant   string
+	RunID    string
+	Endpoint string
+	Event    string
+	Outcome  string
+	Since    time.Time
+	Until    time.Time
+}
+
+func (f Filter) match(d Delivery) bool {
+	return d.Tenant == f.Tenant &&
+		(f.RunID == "" || d.RunID == f.RunID) &&
+		(f.Endpoint == "" || d.Endpoint == f.Endpoint) &&
+		(f.Event == "" || d.Event == f.Event) &&
+		(f.Outcome == "" || d.Outcome == f.Outcome) &&
+		(f.Since.IsZero() || !d.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || d.Time.Before(f.Until))
+}
+
+// record is a line of the delivery log. Retried attempts carry the body of the event, so the
+// delivery can be resumed once the server restarts.
+type record struct {
+	Delivery
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// pendingDelivery is a delivery whose last attempt was retrying
+type pendingDelivery struct {
+	last Delivery
+	body []byte
+}
+
+// Log is an append-only log of the delivery attempts stored as one JSON record per line
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// OpenLog opens the delivery log at path, creating its directory when needed
+func OpenLog(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &Log{path: path}, nil
+}
+
+// Append writes a delivery to the log, filling its time
+func (l *Log) Append(delivery Delivery) error {
+	return l.append(record{Delivery: delivery})
+}
+
+func (l *Log) append(r record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r.Time = time.Now().UTC()
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Query returns the deliveries matching filter, oldest first
+func (l *Log) Query(filter Filter) ([]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+	_, err := l.scan(func(r record) {
+		if filter.match(r.Delivery) {
+			deliveries = append(deliveries, r.Delivery)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// pending returns the deliveries whose last attempt was retrying, oldest first: the server
+// stopped before they were delivered or given up. A record left half written by a crash at the
+// end of the log is dropped.
+func (l *Log) pending() ([]pendingDelivery, error) {
+	type key struct{ eventID, endpoint string }
+	var order []key
+	last := make(map[key]record)
+	torn, err := l.scan(func(r record) {
+		k := key{r.EventID, r.Endpoint}
+		if _, ok := last[k]; !ok {
+			order = append(order, k)
+		}
+		last[k] = r
+	})
+	if err != nil {
+		return nil, err
+	}
+	if torn >= 0 {
+		log.Printf("Delivery log %s ends with an interrupted write, dropping it", l.path)
+		l.mu.Lock()
+		err := os.Truncate(l.path, torn)
+		l.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pending []pendingDelivery
+	for _, k := range order {
+		if r := last[k]; r.Outcome == OutcomeRetrying {
+			pending = append(pending, pendingDelivery{last: r.Delivery, body: r.Body})
+		}
+	}
+	return pending, nil
+}
+
+// scan calls fn with every record of the log, oldest first. A last line without its newline is a
+// record whose write was interrupted, not passed to fn: scan returns its offset, -1 when the log
+// ends with a complete record.
+func (l *Log) scan(fn func(record)) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	line, offset := 0, int64(0)
+	for {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				return offset, nil
+			}
+			return -1, nil
+		}
+		if err != nil {
+			return -1, err
+		}
+		line++
+		offset += int64(len(data))
+		var r record
+		if err := json.Unmarshal(data, &r); err != nil {
+			return -1, fmt.Errorf("delivery log line %d is not valid JSON", line)
+		}
+		fn(r)
+	}
+}
@@ -0,0 +1,395 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+// Events sent to the endpoints
+const (
+	// EventRunCompleted is sent for every stored run
+	EventRunCompleted = "run.completed"
+	// EventThresholdExceeded is sent for the runs with more exceptions than the endpoint tolerates
+	EventThresholdExceeded = "run.threshold_exceeded"
+)
+
+// Headers of the deliveries
+const (
+	// SignatureHeader holds the time of the delivery and the signature of its body, see Sign
+	SignatureHeader = "X-Webhook-Signature"
+	// EventHeader holds the type of the event
+	EventHeader = "X-Webhook-Event"
+	// IDHeader holds the ID of the event, the same on every attempt so receivers can ignore repeats
+	IDHeader = "X-Webhook-ID"
+)
+
+// maxBackoff bounds the wait between two attempts
+const maxBackoff = 10 * time.Minute
+
+// Endpoint receives the events of the stored runs of a tenant
+// Name: Identifies the endpoint in the delivery log
+// Secret: Keys the HMAC-SHA256 signature of the deliveries, shared with the receiver
+// Tenant: Tenant whose runs are sent, the default tenant when empty
+// Events: Events sent to the endpoint, every event when empty
+// MaxUnmatched/MaxDiscrepancy: Exceptions tolerated before run.threshold_exceeded is sent
+type Endpoint struct {
+	Name           string   `json:"name"`
+	URL            string   `json:"url"`
+	Secret         string   `json:"secret"`
+	Tenant         string   `json:"tenant,omitempty"`
+	Events         []string `json:"events,omitempty"`
+	MaxUnmatched   int      `json:"max_unmatched"`
+	MaxDiscrepancy float64  `json:"max_discrepancy"`
+}
+
+// Validate reports whether the endpoint can receive deliveries
+func (e Endpoint) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return fmt.Errorf("webhook name is required")
+	}
+	if u, err := url.Parse(e.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook %s needs an http or https url", e.Name)
+	}
+	if len(e.Secret) < 16 {
+		return fmt.Errorf("webhook %s needs a secret of at least 16 characters", e.Name)
+	}
+	for _, event := range e.Events {
+		if event != EventRunCompleted && event != EventThresholdExceeded {
+			return fmt.Errorf("webhook %s has unknown event %q, expected %s or %s", e.Name, event, EventRunCompleted, EventThresholdExceeded)
+		}
+	}
+	if e.MaxUnmatched < 0 || e.MaxDiscrepancy < 0 {
+		return fmt.Errorf("webhook %s thresholds must be non-negative", e.Name)
+	}
+	return nil
+}
+
+// wants reports whether event is sent to the endpoint
+func (e Endpoint) wants(event string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, event)
+}
+
+// Thresholds are the exceptions tolerated by an endpoint, sent along run.threshold_exceeded
+type Thresholds struct {
+	MaxUnmatched   int     `json:"max_unmatched"`
+	MaxDiscrepancy float64 `json:"max_discrepancy"`
+}
+
+// Event is the body of a delivery
+// ID: Identifies the event, deliveries of the same event to several endpoints have different IDs
+// Thresholds: Set on run.threshold_exceeded, the exceptions tolerated by the endpoint
+type Event struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Tenant     string            `json:"tenant,omitempty"`
+	Run        model.RunOverview `json:"run"`
+	Thresholds *Thresholds       `json:"thresholds,omitempty"`
+}
+
+// Sign returns the signature header of a body sent at t: "t=<unix seconds>,v1=<hex HMAC-SHA256>",
+// the HMAC being computed with secret over the unix seconds, a dot and the body
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + signature(secret, timestamp, body)
+}
+
+func signature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks header is a signature of body made with secret at most tolerance before now,
+// so receivers reject forged and replayed deliveries
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return errors.New("malformed signature header")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return errors.New("signature timestamp outside the tolerance")
+	}
+
+	expected := signature(secret, timestamp, body)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return errors.New("signature does not match")
+}
+
+// Retry sets how a failed delivery is retried
+// Attempts: Attempts of a delivery before it is given up, the first one included
+// Backoff: Wait before the second attempt, doubled after each failed attempt up to 10 minutes
+// Timeout: How long an endpoint is waited for on each attempt
+type Retry struct {
+	Attempts int
+	Backoff  time.Duration
+	Timeout  time.Duration
+}
+
+// Dispatcher delivers the events of the stored runs to the endpoints in the background, retrying
+// failed deliveries and recording every attempt in the delivery log. A nil Dispatcher sends nothing.
+type Dispatcher struct {
+	endpoints []Endpoint
+	log       *Log
+	retry     Retry
+	client    *http.Client
+	// ctx is canceled by Close, cutting the pending deliveries
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pending sync.WaitGroup
+}
+
+// New returns a dispatcher of endpoints recording the deliveries in deliveries
+func New(endpoints []Endpoint, deliveries *Log, retry Retry) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		endpoints: endpoints,
+		log:       deliveries,
+		retry:     retry,
+		client:    &http.Client{Timeout: retry.Timeout},
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Notify sends the events of a stored run of tenant to the endpoints of the tenant, it returns
+// without waiting for the deliveries
+func (d *Dispatcher) Notify(tenant string, run model.RunOverview) {
+	if d == nil {
+		return
+	}
+
+	for _, endpoint := range d.endpoints {
+		if endpoint.Tenant != tenant {
+			continue
+		}
+		if endpoint.wants(EventRunCompleted) {
+			d.send(endpoint, Event{Type: EventRunCompleted, Tenant: tenant, Run: run})
+		}
+		if endpoint.wants(EventThresholdExceeded) && (run.Unmatched > endpoint.MaxUnmatched || run.Discrepancies > endpoint.MaxDiscrepancy) {
+			thresholds := Thresholds{MaxUnmatched: endpoint.MaxUnmatched, MaxDiscrepancy: endpoint.MaxDiscrepancy}
+			d.send(endpoint, Event{Type: EventThresholdExceeded, Tenant: tenant, Run: run, Thresholds: &thresholds})
+		}
+	}
+}
+
+// Close waits for the pending deliveries until ctx is done, then stops the remaining ones: they are
+// left retrying in the delivery log, for Resume to deliver them once the server starts again
+func (d *Dispatcher) Close(ctx context.Context) {
+	if d == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+}
+
+// Resume delivers in the background the deliveries the server left retrying when it stopped or
+// crashed, at the time of their next attempt. Deliveries to endpoints no longer configured, or recorded without
+// their event, are given up.
+func (d *Dispatcher) Resume() error {
+	if d == nil {
+		return nil
+	}
+
+	pending, err := d.log.pending()
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		var event Event
+		next := p.last
+		next.Attempt++
+		endpoint, found := d.endpoint(p.last.Endpoint)
+		switch {
+		case !found:
+			d.giveUp(next, "delivery given up, the endpoint is no longer configured")
+		case len(p.body) == 0 || json.Unmarshal(p.body, &event) != nil:
+			d.giveUp(next, "delivery given up, its event was not recorded")
+		default:
+			var wait time.Duration
+			if p.last.NextAttempt != nil {
+				wait = max(time.Until(*p.last.NextAttempt), 0)
+			}
+			d.pending.Add(1)
+			go func() {
+				defer d.pending.Done()
+				d.deliver(endpoint, event, p.body, next.Attempt, wait)
+			}()
+		}
+	}
+	return nil
+}
+
+// endpoint returns the endpoint named name
+func (d *Dispatcher) endpoint(name string) (Endpoint, bool) {
+	for _, endpoint := range d.endpoints {
+		if endpoint.Name == name {
+			return endpoint, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// send delivers event to endpoint in the background
+func (d *Dispatcher) send(endpoint Endpoint, event Event) {
+	id := make([]byte, 16)
+	rand.Read(id)
+	event.ID = hex.EncodeToString(id)
+	event.CreatedAt = time.Now().UTC()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Webhook %s: encoding event %s: %v", endpoint.Name, event.ID, err)
+		return
+	}
+
+	d.pending.Add(1)
+	go func() {
+		defer d.pending.Done()
+		d.deliver(endpoint, event, body, 1, 0)
+	}()
+}
+
+// deliver posts event to endpoint from attempt on, after waiting wait, until it is accepted,
+// refused or the attempts run out. Retried attempts are recorded with body, so they survive a
+// restart of the server.
+func (d *Dispatcher) deliver(endpoint Endpoint, event Event, body []byte, attempt int, wait time.Duration) {
+	for ; ; attempt++ {
+		delivery := Delivery{
+			EventID:  event.ID,
+			Event:    event.Type,
+			Tenant:   event.Tenant,
+			RunID:    event.Run.ID,
+			Endpoint: endpoint.Name,
+			Attempt:  attempt,
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-d.ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+
+		start := time.Now()
+		retryable, err := d.post(endpoint, event, body, &delivery)
+		delivery.DurationMS = time.Since(start).Milliseconds()
+
+		r := record{Delivery: delivery}
+		switch {
+		case err == nil:
+			r.Outcome = OutcomeDelivered
+		case !retryable || attempt >= d.retry.Attempts:
+			r.Outcome = OutcomeFailed
+			r.Error = err.Error()
+		default:
+			wait = d.backoff(attempt)
+			next := time.Now().Add(wait).UTC()
+			r.Outcome, r.Error, r.NextAttempt, r.Body = OutcomeRetrying, err.Error(), &next, body
+		}
+		if err := d.log.append(r); err != nil {
+			log.Printf("Webhook %s: recording delivery of event %s: %v", endpoint.Name, event.ID, err)
+		}
+		if r.Outcome != OutcomeRetrying {
+			return
+		}
+	}
+}
+
+// backoff returns the wait after the failed attempt: the backoff of the retry settings, doubled
+// after each failed attempt up to maxBackoff
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.retry.Backoff
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// giveUp records the attempt of delivery as failed with reason, without making it
+func (d *Dispatcher) giveUp(delivery Delivery, reason string) {
+	delivery.Outcome, delivery.Error, delivery.NextAttempt = OutcomeFailed, reason, nil
+	delivery.StatusCode, delivery.DurationMS = 0, 0
+	if err := d.log.Append(delivery); err != nil {
+		log.Printf("Webhook %s: recording delivery of event %s: %v", delivery.Endpoint, delivery.EventID, err)
+	}
+}
+
+// post sends a signed delivery of event, filling the status code of the response. Network errors,
+// timeouts, server errors and rate limits are worth retrying, other refusals are not.
+func (d *Dispatcher) post(endpoint Endpoint, event Event, body []byte, delivery *Delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(IDHeader, event.ID)
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	// Draining the body lets the connection be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	delivery.StatusCode = resp.StatusCode
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("endpoint answered %s", resp.Status)
+	default:
+		return false, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+}
@@ -0,0 +1,272 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+)
+
+const testSecret = "0123456789abcdef"
+
+// receiver records the events it accepts, answering the statuses of answers to the first requests
+type receiver struct {
+	t       *testing.T
+	mu      sync.Mutex
+	answers []int
+	events  []Event
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := Verify(testSecret, r.Header.Get(SignatureHeader), body, time.Now(), time.Minute); err != nil {
+		rc.t.Errorf("delivery signature: %v", err)
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.answers) > 0 {
+		status := rc.answers[0]
+		rc.answers = rc.answers[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil || event.Type != r.Header.Get(EventHeader) || event.ID != r.Header.Get(IDHeader) {
+		rc.t.Errorf("event %s, headers %v: %v", body, r.Header, err)
+	}
+	rc.events = append(rc.events, event)
+}
+
+func newDispatcher(t *testing.T, endpoints ...Endpoint) (*Dispatcher, *Log) {
+	t.Helper()
+
+	deliveries, err := OpenLog(filepath.Join(t.TempDir(), "webhooks", "deliveries.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(endpoints, deliveries, Retry{Attempts: 3, Backoff: 10 * time.Millisecond, Timeout: time.Second}), deliveries
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	now := time.Unix(1733140800, 0)
+	header := Sign(testSecret, now, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    string
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", testSecret, header, string(body), now.Add(time.Second), false},
+		{"rotated secret", testSecret, Sign("previous-secret-0", now, body) + "," + header[len("t=1733140800,"):], string(body), now, false},
+		{"other secret", "fedcba9876543210", header, string(body), now, true},
+		{"changed body", testSecret, header, `{"id":"2"}`, now, true},
+		{"replayed", testSecret, header, string(body), now.Add(10 * time.Minute), true},
+		{"malformed", testSecret, "v1=abc", string(body), now, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, []byte(tt.body), tt.now, 5*time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	rc := &receiver{t: t, answers: []int{http.StatusServiceUnavailable, http.StatusOK}}
+	ts := httptest.NewServer(rc)
+	defer ts.Close()
+
+	d, deliveries := newDispatcher(t,
+		Endpoint{Name: "erp", URL: ts.URL, Secret: testSecret, Events: []string{EventRunCompleted}},
+		Endpoint{Name: "alerts", URL: ts.URL, Secret: testSecret, Events: []string{EventThresholdExceeded}, MaxUnmatched: 5},
+		Endpoint{Name: "other-tenant", URL: ts.URL, Secret: testSecret, Tenant: "emea"},
+	)
+	d.Notify("", model.RunOverview{RunSummary: model.RunSummary{ID: "run-1"}, Unmatched: 5})
+	d.Notify("", model.RunOverview{RunSummary: model.RunSummary{ID: "run-2"}, Unmatched: 6})
+	d.Close(context.Background())
+
+	// run-1 and run-2 to erp, run-2 exceeding the threshold of alerts
+	if len(rc.events) != 3 {
+		t.Fatalf("received %d events, want 3: %+v", len(rc.events), rc.events)
+	}
+	for _, event := range rc.events {
+		if event.Type == EventThresholdExceeded && (event.Run.ID != "run-2" || event.Thresholds == nil || event.Thresholds.MaxUnmatched != 5) {
+			t.Errorf("threshold event = %+v", event)
+		}
+	}
+
+	// The first attempt was answered 503 and retried
+	records, err := deliveries.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcomes := map[string]int{}
+	for _, record := range records {
+		outcomes[record.Outcome]++
+		if record.Outcome == OutcomeRetrying && (record.StatusCode != http.StatusServiceUnavailable || record.NextAttempt == nil) {
+			t.Errorf("retried delivery = %+v", record)
+		}
+	}
+	if len(records) != 4 || outcomes[OutcomeDelivered] != 3 || outcomes[OutcomeRetrying] != 1 {
+		t.Errorf("deliveries = %+v", records)
+	}
+	if alerts, _ := deliveries.Query(Filter{Endpoint: "alerts", RunID: "run-2", Outcome: OutcomeDelivered}); len(alerts) != 1 {
+		t.Errorf("deliveries of alerts = %+v", alerts)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) }))
+	defer failing.Close()
+	gone := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusGone) }))
+	defer gone.Close()
+
+	d, deliveries := newDispatcher(t,
+		Endpoint{Name: "failing", URL: failing.URL, Secret: testSecret},
+		Endpoint{Name: "gone", URL: gone.URL, Secret: testSecret},
+	)
+	d.Notify("", model.RunOverview{RunSummary: model.RunSummary{ID: "run-1"}})
+	d.Close(context.Background())
+
+	// Server errors are retried until the attempts run out, refusals are not retried
+	for endpoint, wantAttempts := range map[string]int{"failing": 3, "gone": 1} {
+		records, err := deliveries.Query(Filter{Endpoint: endpoint, Event: EventRunCompleted})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last := records[len(records)-1]; len(records) != wantAttempts || last.Outcome != OutcomeFailed || last.Attempt != wantAttempts {
+			t.Errorf("deliveries to %s = %+v, want %d attempts", endpoint, records, wantAttempts)
+		}
+	}
+}
+
+func TestDispatcherClose(t *testing.T) {
+	rc := &receiver{t: t, answers: []int{http.StatusServiceUnavailable}}
+	ts := httptest.NewServer(rc)
+	defer ts.Close()
+
+	d, deliveries := newDispatcher(t, Endpoint{Name: "erp", URL: ts.URL, Secret: testSecret})
+	d.retry.Backoff = time.Hour
+	d.Notify("", model.RunOverview{RunSummary: model.RunSummary{ID: "run-1"}})
+
+	// The delivery waiting for its next attempt is stopped once the deadline passes, left retrying
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	d.Close(ctx)
+
+	records, _ := deliveries.Query(Filter{})
+	if len(records) != 1 || records[0].Outcome != OutcomeRetrying {
+		t.Fatalf("deliveries = %+v", records)
+	}
+
+}
+
+func TestDispatcherResume(t *testing.T) {
+	rc := &receiver{t: t}
+	ts := httptest.NewServer(rc)
+	defer ts.Close()
+
+	// The server crashed after the first attempt of the event
+	d, deliveries := newDispatcher(t, Endpoint{Name: "erp", URL: ts.URL, Secret: testSecret})
+	event := Event{ID: "1", Type: EventRunCompleted, Run: model.RunOverview{RunSummary: model.RunSummary{ID: "run-1"}}}
+	body, _ := json.Marshal(event)
+	next := time.Now().Add(-time.Minute)
+	retrying := Delivery{EventID: "1", Event: EventRunCompleted, RunID: "run-1", Endpoint: "erp", Attempt: 1, Outcome: OutcomeRetrying, NextAttempt: &next}
+	if err := deliveries.append(record{Delivery: retrying, Body: body}); err != nil {
+		t.Fatal(err)
+	}
+	// and while writing the record of another one
+	file, err := os.OpenFile(deliveries.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"event_id":"2","event":"run.comp`)
+	file.Close()
+
+	if err := d.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	d.Close(context.Background())
+
+	records, _ := deliveries.Query(Filter{})
+	if len(records) != 2 || records[1].Outcome != OutcomeDelivered || records[1].Attempt != 2 {
+		t.Errorf("deliveries = %+v", records)
+	}
+	if len(rc.events) != 1 || rc.events[0].ID != "1" || rc.events[0].Run.ID != "run-1" {
+		t.Errorf("received events %+v", rc.events)
+	}
+
+	// Delivered events are not resumed again
+	if err := d.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := deliveries.Query(Filter{}); len(again) != 2 {
+		t.Errorf("deliveries after a second resume = %+v", again)
+	}
+}
+
+func TestDispatcherResumeGivesUp(t *testing.T) {
+	_, deliveries := newDispatcher(t)
+	next := time.Now().Add(time.Hour)
+	for _, delivery := range []Delivery{
+		{EventID: "1", Event: EventRunCompleted, RunID: "run-1", Endpoint: "removed", Attempt: 1, Outcome: OutcomeRetrying, NextAttempt: &next},
+		{EventID: "2", Event: EventRunCompleted, RunID: "run-2", Endpoint: "erp", Attempt: 2, Outcome: OutcomeRetrying, NextAttempt: &next},
+	} {
+		if err := deliveries.Append(delivery); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Neither the endpoint of the first delivery nor the event of the second are known anymore
+	d := New([]Endpoint{{Name: "erp", URL: "http://127.0.0.1:1", Secret: testSecret}}, deliveries, Retry{Attempts: 3, Backoff: time.Hour, Timeout: time.Second})
+	if err := d.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	d.Close(context.Background())
+
+	failed, _ := deliveries.Query(Filter{Outcome: OutcomeFailed})
+	if len(failed) != 2 || failed[0].EventID != "1" || failed[0].Attempt != 2 || failed[1].EventID != "2" || failed[1].Attempt != 3 {
+		t.Errorf("failed deliveries = %+v", failed)
+	}
+}
+
+func TestEndpointValidate(t *testing.T) {
+	valid := Endpoint{Name: "erp", URL: "https://erp.example.com/hooks", Secret: testSecret}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	invalid := map[string]func(*Endpoint){
+		"no name":        func(e *Endpoint) { e.Name = "" },
+		"relative url":   func(e *Endpoint) { e.URL = "/hooks" },
+		"ftp url":        func(e *Endpoint) { e.URL = "ftp://erp.example.com" },
+		"short secret":   func(e *Endpoint) { e.Secret = "secret" },
+		"unknown event":  func(e *Endpoint) { e.Events = []string{"run.deleted"} },
+		"negative limit": func(e *Endpoint) { e.MaxDiscrepancy = -1 },
+	}
+	for name, change := range invalid {
+		endpoint := valid
+		change(&endpoint)
+		if err := endpoint.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+	}
+}
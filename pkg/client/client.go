@@ -0,0 +1,606 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arham-abiyan/reconciliation/internal/model"
+	"github.com/arham-abiyan/reconciliation/internal/services/reconciliation"
+)
+
+// Types of the API, see the OpenAPI document served at /api/openapi.json. Every type reachable
+// from the client is named here, so callers never need the internal packages.
+type (
+	ReconcileResponse = model.ReconcileResponse
+	Transaction       = model.Transaction
+	BankStatement     = model.BankStatement
+	MatchedPair       = model.MatchedPair
+	BankSummary       = model.BankSummary
+	DailySummary      = model.DailySummary
+	Duplicate         = model.Duplicate
+	InternalTransfer  = model.InternalTransfer
+	Reversal          = model.Reversal
+	BalanceCheck      = model.BalanceCheck
+	ParseError        = model.ParseError
+	Run               = model.Run
+	RunParams         = model.RunParams
+	InputFile         = model.InputFile
+	RunSummary        = model.RunSummary
+	FeeRule           = reconciliation.FeeRule
+	FeeTier           = reconciliation.FeeTier
+	Progress          = reconciliation.Progress
+	RunOverview       = model.RunOverview
+	Exception         = model.Exception
+	Match             = model.Match
+	Bank              = model.Bank
+	Override          = model.Override
+	RunOverviewPage   = model.Page[model.RunOverview]
+	ExceptionPage     = model.Page[model.Exception]
+	MatchPage         = model.Page[model.Match]
+	BankPage          = model.Page[model.Bank]
+)
+
+// Values of ReconcileRequest.Matches, see the matches option of the server
+const (
+	MatchesAll       = reconciliation.MatchesAll
+	MatchesImperfect = reconciliation.MatchesImperfect
+	MatchesNone      = reconciliation.MatchesNone
+)
+
+// Statuses of runs, exceptions and matches
+const (
+	RunStatusOpen            = model.RunStatusOpen
+	RunStatusSignedOff       = model.RunStatusSignedOff
+	ExceptionUnmatchedSystem = model.ExceptionUnmatchedSystem
+	ExceptionUnmatchedBank   = model.ExceptionUnmatchedBank
+	ExceptionDiscrepancy     = model.ExceptionDiscrepancy
+	MatchExact               = model.MatchExact
+	MatchExplained           = model.MatchExplained
+	MatchDiscrepancy         = model.MatchDiscrepancy
+)
+
+// Kinds of overrides
+const (
+	OverrideManualMatch = model.OverrideManualMatch
+	OverrideWriteOff    = model.OverrideWriteOff
+)
+
+// Stages of Progress
+const (
+	StageParsing  = reconciliation.StageParsing
+	StageParsed   = reconciliation.StageParsed
+	StageMatching = reconciliation.StageMatching
+	StageDone     = reconciliation.StageDone
+)
+
+// Events of a job stream
+const (
+	EventProgress = "progress"
+	EventDone     = "done"
+	EventFailed   = "failed"
+)
+
+const (
+	// apiKeyHeader carries the API key of the caller
+	apiKeyHeader = "X-API-Key"
+	// jobIDHeader names the job of a reconciliation request
+	jobIDHeader = "X-Job-ID"
+)
+
+// Error is an error answered by the server
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("reconciliation API: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a not found error of the server, e.g. an unknown run
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client calls the reconciliation server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	token      string
+}
+
+// Option configures the Client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with httpClient instead of http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey authenticates the requests with an API key
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithBearerToken authenticates the requests with a JWT
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// RequestOption configures a single request
+type RequestOption func(*http.Request)
+
+// WithJobID runs a reconciliation as the job id, so its progress can be followed with Events while
+// the request is running. id is 1 to 64 letters, digits, dashes or underscores.
+func WithJobID(id string) RequestOption {
+	return func(req *http.Request) {
+		req.Header.Set(jobIDHeader, id)
+	}
+}
+
+// New returns a client of the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// File is an uploaded file, Name names the bank of a bank file
+type File struct {
+	Name    string
+	Content io.Reader
+}
+
+// ReconcileRequest holds the files and options of a reconciliation, options left empty use the
+// settings of the server
+type ReconcileRequest struct {
+	SystemFile         File
+	BankFiles          []File
+	StartDate          string
+	EndDate            string
+	TransferWindowDays *int
+	Matches            string
+	FeeRules           []FeeRule
+	BalancesFile       *File
+}
+
+// formFile is a file of a multipart form
+type formFile struct {
+	field string
+	file  File
+}
+
+// ReconcileResult is the result of a reconciliation and the ID of the run storing it
+type ReconcileResult struct {
+	RunID  string
+	Result ReconcileResponse
+}
+
+// Reconcile uploads the files of req, reconciles them and returns the stored run ID with the result
+func (c *Client) Reconcile(ctx context.Context, req ReconcileRequest, opts ...RequestOption) (*ReconcileResult, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	files := []formFile{{"system_file", req.SystemFile}}
+	for _, bank := range req.BankFiles {
+		files = append(files, formFile{"bank_files", bank})
+	}
+	if req.BalancesFile != nil {
+		files = append(files, formFile{"balances_file", *req.BalancesFile})
+	}
+	for _, f := range files {
+		part, err := form.CreateFormFile(f.field, f.file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(part, f.file.Content); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.file.Name, err)
+		}
+	}
+
+	fields := map[string]string{
+		"start_date": req.StartDate,
+		"end_date":   req.EndDate,
+		"matches":    req.Matches,
+	}
+	if req.TransferWindowDays != nil {
+		fields["transfer_window_days"] = strconv.Itoa(*req.TransferWindowDays)
+	}
+	if len(req.FeeRules) > 0 {
+		rules, err := json.Marshal(req.FeeRules)
+		if err != nil {
+			return nil, err
+		}
+		fields["fee_rules"] = string(rules)
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := form.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	var result ReconcileResult
+	runID, err := c.doJSON(ctx, http.MethodPost, "/api/reconcile", form.FormDataContentType(), &body, &result.Result, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result.RunID = runID
+	return &result, nil
+}
+
+// ListRuns returns the stored runs, most recent first, without their results
+func (c *Client) ListRuns(ctx context.Context) ([]RunSummary, error) {
+	var runs []RunSummary
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/runs", "", nil, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// GetRun returns a stored run with its parameters and result
+func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
+	var run Run
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/runs/"+url.PathEscape(id), "", nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Report downloads the report of a stored run in format: text, csv, xlsx, html or pdf
+func (c *Client) Report(ctx context.Context, id, format string) ([]byte, error) {
+	return c.getDocument(ctx, http.MethodGet, "/api/runs/"+url.PathEscape(id)+"/report?format="+url.QueryEscape(format), nil)
+}
+
+// SignOff holds the names printed on a sign-off report
+// Approver: Left empty, or the name of the caller, once the server requires authentication: it
+// signs off under the name of the caller
+type SignOff struct {
+	PreparedBy string
+	Reviewer   string
+	Approver   string
+}
+
+// SignOff renders the PDF sign-off report of a stored run, attaches it to the run and returns it
+func (c *Client) SignOff(ctx context.Context, id string, signOff SignOff) ([]byte, error) {
+	form := url.Values{}
+	for name, value := range map[string]string{
+		"prepared_by": signOff.PreparedBy,
+		"reviewer":    signOff.Reviewer,
+		"approver":    signOff.Approver,
+	} {
+		if value != "" {
+			form.Set(name, value)
+		}
+	}
+	return c.getDocument(ctx, http.MethodPost, "/api/runs/"+url.PathEscape(id)+"/signoff", form)
+}
+
+// SignOffReport downloads the sign-off report attached to a stored run
+func (c *Client) SignOffReport(ctx context.Context, id string) ([]byte, error) {
+	return c.getDocument(ctx, http.MethodGet, "/api/runs/"+url.PathEscape(id)+"/signoff", nil)
+}
+
+// OverrideRequest resolves exceptions of a stored run by hand
+// Kind: OverrideManualMatch or OverrideWriteOff
+// Exceptions: IDs of the exceptions resolved, see Exception.ID
+type OverrideRequest struct {
+	Kind       string
+	Exceptions []string
+	Reason     string
+}
+
+// CreateOverride resolves exceptions of a stored run by hand and returns the stored override
+func (c *Client) CreateOverride(ctx context.Context, id string, req OverrideRequest) (*Override, error) {
+	form := url.Values{"kind": {req.Kind}, "exception": req.Exceptions, "reason": {req.Reason}}
+	var override Override
+	if _, err := c.doJSON(ctx, http.MethodPost, "/api/runs/"+url.PathEscape(id)+"/overrides", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), &override); err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// Overrides returns the overrides of a stored run, oldest first
+func (c *Client) Overrides(ctx context.Context, id string) ([]Override, error) {
+	var overrides []Override
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/runs/"+url.PathEscape(id)+"/overrides", "", nil, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// ListOptions pages, filters and sorts a listing of the v1 resources, options left empty use
+// the defaults of the server. Filters a resource does not support are rejected by the server.
+// Sort: Field the items are sorted by, prefixed with "-" for descending order
+// Banks/Statuses: Accepted values, any value when empty
+// Since/Until: Items dated since, inclusive, and until, exclusive
+type ListOptions struct {
+	Limit     int
+	Offset    int
+	Sort      string
+	Banks     []string
+	Statuses  []string
+	MinAmount *float64
+	MaxAmount *float64
+	Since     time.Time
+	Until     time.Time
+}
+
+func (o ListOptions) query() string {
+	query := url.Values{}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	if len(o.Banks) > 0 {
+		query.Set("bank", strings.Join(o.Banks, ","))
+	}
+	if len(o.Statuses) > 0 {
+		query.Set("status", strings.Join(o.Statuses, ","))
+	}
+	if o.MinAmount != nil {
+		query.Set("min_amount", strconv.FormatFloat(*o.MinAmount, 'f', -1, 64))
+	}
+	if o.MaxAmount != nil {
+		query.Set("max_amount", strconv.FormatFloat(*o.MaxAmount, 'f', -1, 64))
+	}
+	if !o.Since.IsZero() {
+		query.Set("since", o.Since.Format(time.RFC3339))
+	}
+	if !o.Until.IsZero() {
+		query.Set("until", o.Until.Format(time.RFC3339))
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
+
+// RunOverviews returns a page of the stored runs with their totals, most recent first by default
+func (c *Client) RunOverviews(ctx context.Context, opts ListOptions) (*RunOverviewPage, error) {
+	var page RunOverviewPage
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/v1/runs"+opts.query(), "", nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// RunOverview describes a stored run with its totals, without its records
+func (c *Client) RunOverview(ctx context.Context, id string) (*RunOverview, error) {
+	var overview RunOverview
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/v1/runs/"+url.PathEscape(id), "", nil, &overview); err != nil {
+		return nil, err
+	}
+	return &overview, nil
+}
+
+// Exceptions returns a page of the unmatched records and discrepancies of a stored run
+func (c *Client) Exceptions(ctx context.Context, id string, opts ListOptions) (*ExceptionPage, error) {
+	var page ExceptionPage
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/v1/runs/"+url.PathEscape(id)+"/exceptions"+opts.query(), "", nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Matches returns a page of the matched pairs kept by a stored run
+func (c *Client) Matches(ctx context.Context, id string, opts ListOptions) (*MatchPage, error) {
+	var page MatchPage
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/v1/runs/"+url.PathEscape(id)+"/matches"+opts.query(), "", nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Banks returns a page of the banks known to the caller: configured, or reconciled in a stored run
+func (c *Client) Banks(ctx context.Context, opts ListOptions) (*BankPage, error) {
+	var page BankPage
+	if _, err := c.doJSON(ctx, http.MethodGet, "/api/v1/banks"+opts.query(), "", nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// JobEvent is an event of a reconciliation job
+// ID: Position of the event in the events of the job, from 1
+// Name: EventProgress, EventDone or EventFailed
+// Progress: Progress of the reconciliation, set by progress events
+// RunID: ID of the stored run, set by done events
+// Status/Error: HTTP status and error of the request, set by failed events
+type JobEvent struct {
+	ID       int
+	Name     string
+	Progress *Progress
+	RunID    string
+	Status   int
+	Error    string
+}
+
+// Events follows the job id, calling fn with each of its events from the first one until the done
+// or failed event. Jobs are known to the server once their request was received, earlier calls fail
+// with a not found error.
+func (c *Client) Events(ctx context.Context, id string, fn func(JobEvent)) error {
+	resp, err := c.do(ctx, http.MethodGet, "/api/jobs/"+url.PathEscape(id)+"/events", "", nil, "text/event-stream")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var event JobEvent
+	var data []byte
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch {
+		case line == "":
+			if event.Name == "" {
+				continue
+			}
+			if err := event.decode(data); err != nil {
+				return err
+			}
+			fn(event)
+			if event.Name == EventDone || event.Name == EventFailed {
+				return nil
+			}
+			event, data = JobEvent{}, nil
+		case field == "id":
+			event.ID, _ = strconv.Atoi(value)
+		case field == "event":
+			event.Name = value
+		case field == "data":
+			data = append(data, value...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("reconciliation API: events of job %s ended before the job finished", id)
+}
+
+// decode sets the fields of the event from its JSON data
+func (e *JobEvent) decode(data []byte) error {
+	var err error
+	switch e.Name {
+	case EventProgress:
+		e.Progress = &Progress{}
+		err = json.Unmarshal(data, e.Progress)
+	case EventDone:
+		var done struct {
+			RunID string `json:"run_id"`
+		}
+		err = json.Unmarshal(data, &done)
+		e.RunID = done.RunID
+	case EventFailed:
+		var failed struct {
+			Status int    `json:"status"`
+			Error  string `json:"error"`
+		}
+		err = json.Unmarshal(data, &failed)
+		e.Status, e.Error = failed.Status, failed.Error
+	}
+	if err != nil {
+		return fmt.Errorf("reconciliation API: invalid %s event: %w", e.Name, err)
+	}
+	return nil
+}
+
+// apiResponse is the envelope of every JSON response
+type apiResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	RunID   string          `json:"run_id"`
+	Error   string          `json:"error"`
+}
+
+// doJSON sends a request and decodes the data of the JSON response into data, it returns the run ID of the response
+func (c *Client) doJSON(ctx context.Context, method, path, contentType string, body io.Reader, data any, opts ...RequestOption) (string, error) {
+	resp, err := c.do(ctx, method, path, contentType, body, "application/json", opts...)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("reconciliation API: invalid response: %w", err)
+	}
+	if data != nil {
+		if err := json.Unmarshal(response.Data, data); err != nil {
+			return "", fmt.Errorf("reconciliation API: invalid response data: %w", err)
+		}
+	}
+	return response.RunID, nil
+}
+
+// getDocument sends a request, with form as its body when not nil, and returns the document answered
+func (c *Client) getDocument(ctx context.Context, method, path string, form url.Values) ([]byte, error) {
+	var body io.Reader
+	contentType := ""
+	if form != nil {
+		body, contentType = strings.NewReader(form.Encode()), "application/x-www-form-urlencoded"
+	}
+
+	resp, err := c.do(ctx, method, path, contentType, body, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// do sends an authenticated request configured by opts, answers other than 2xx are returned as an *Error
+func (c *Client) do(ctx context.Context, method, path, contentType string, body io.Reader, accept string, opts ...RequestOption) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	var response apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err == nil && response.Error != "" {
+		apiErr.Message = response.Error
+	}
+	return nil, apiErr
+}
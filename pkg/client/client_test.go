@@ -0,0 +1,188 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arham-abiyan/reconciliation/internal/auth"
+	"github.com/arham-abiyan/reconciliation/internal/config"
+	"github.com/arham-abiyan/reconciliation/internal/server"
+)
+
+const (
+	systemCSV = "trxID,amount,type,transactionTime\n" +
+		"T1,100000,CREDIT,2024-12-02 10:00:00\n" +
+		"T2,50000,DEBIT,2024-12-02 11:00:00\n"
+	bankCSV = "unique_identifier,amount,date\n" +
+		"T1,100000,2024-12-02\n"
+)
+
+// newTestServer serves the reconciliation API with a single admin API key
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cfg := config.Default()
+	dir := t.TempDir()
+	cfg.Server.UploadsDir = filepath.Join(dir, "uploads")
+	cfg.Server.RunsDir = filepath.Join(dir, "runs")
+	cfg.Server.AuditLog = filepath.Join(dir, "audit", "audit.log")
+	cfg.Auth.APIKeys = []auth.APIKey{{Name: "client", SHA256: auth.HashKey("secret"), Roles: []string{auth.RoleAdmin}}}
+
+	srv, err := server.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestClient(t *testing.T) {
+	ts := newTestServer(t)
+	c := New(ts.URL, WithAPIKey("secret"), WithHTTPClient(ts.Client()))
+	ctx := context.Background()
+
+	window := 2
+	result, err := c.Reconcile(ctx, ReconcileRequest{
+		SystemFile:         File{Name: "system.csv", Content: strings.NewReader(systemCSV)},
+		BankFiles:          []File{{Name: "bank-a.csv", Content: strings.NewReader(bankCSV)}},
+		StartDate:          "2024-12-01",
+		EndDate:            "2024-12-31",
+		TransferWindowDays: &window,
+		FeeRules:           []FeeRule{{Bank: "bank-a", Type: "flat", Flat: 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RunID == "" || result.Result.Matched != 1 || result.Result.Unmatched != 1 {
+		t.Fatalf("Reconcile() = %+v", result)
+	}
+
+	runs, err := c.ListRuns(ctx)
+	if err != nil || len(runs) != 1 || runs[0].ID != result.RunID {
+		t.Fatalf("ListRuns() = %+v, %v", runs, err)
+	}
+
+	run, err := c.GetRun(ctx, result.RunID)
+	if err != nil || run.Params.SystemFile != "system.csv" || run.Result.Matched != 1 {
+		t.Fatalf("GetRun() = %+v, %v", run, err)
+	}
+
+	report, err := c.Report(ctx, result.RunID, "csv")
+	if err != nil || !bytes.Contains(report, []byte("Total matched transactions,1")) {
+		t.Fatalf("Report() = %q, %v", report, err)
+	}
+
+	override, err := c.CreateOverride(ctx, result.RunID, OverrideRequest{Kind: OverrideWriteOff, Exceptions: []string{"unmatched_system-1"}, Reason: "Booked twice"})
+	if err != nil || override.ID != "override-1" || override.Amount != 50000 {
+		t.Fatalf("CreateOverride() = %+v, %v", override, err)
+	}
+	overrides, err := c.Overrides(ctx, result.RunID)
+	if err != nil || len(overrides) != 1 || overrides[0].Exceptions[0] != "unmatched_system-1" {
+		t.Fatalf("Overrides() = %+v, %v", overrides, err)
+	}
+
+	signOff, err := c.SignOff(ctx, result.RunID, SignOff{Reviewer: "Jane Doe"})
+	if err != nil || !bytes.HasPrefix(signOff, []byte("%PDF")) {
+		t.Fatalf("SignOff() = %d bytes, %v", len(signOff), err)
+	}
+	attached, err := c.SignOffReport(ctx, result.RunID)
+	if err != nil || !bytes.Equal(attached, signOff) {
+		t.Fatalf("SignOffReport() = %d bytes, %v", len(attached), err)
+	}
+
+	overviews, err := c.RunOverviews(ctx, ListOptions{Statuses: []string{"signed_off"}})
+	if err != nil || overviews.Total != 1 || overviews.Items[0].ID != result.RunID {
+		t.Fatalf("RunOverviews() = %+v, %v", overviews, err)
+	}
+	overview, err := c.RunOverview(ctx, result.RunID)
+	if err != nil || overview.Status != "signed_off" || overview.Unmatched != 1 {
+		t.Fatalf("RunOverview() = %+v, %v", overview, err)
+	}
+	minAmount := 10000.0
+	exceptions, err := c.Exceptions(ctx, result.RunID, ListOptions{Statuses: []string{"unmatched_system"}, MinAmount: &minAmount, Limit: 10})
+	if err != nil || exceptions.Total != 1 || exceptions.Items[0].Identifier != "T2" || exceptions.Limit != 10 {
+		t.Fatalf("Exceptions() = %+v, %v", exceptions, err)
+	}
+	matches, err := c.Matches(ctx, result.RunID, ListOptions{Banks: []string{"bank-a"}})
+	if err != nil || matches.Total != 1 || matches.Items[0].System.TrxID != "T1" {
+		t.Fatalf("Matches() = %+v, %v", matches, err)
+	}
+	banks, err := c.Banks(ctx, ListOptions{})
+	if err != nil || banks.Total != 1 || banks.Items[0].Name != "bank-a" || banks.Items[0].Runs != 1 {
+		t.Fatalf("Banks() = %+v, %v", banks, err)
+	}
+	if _, err := c.Exceptions(ctx, result.RunID, ListOptions{Sort: "unknown"}); err == nil {
+		t.Error("Exceptions() accepted an unknown sort")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := New(ts.URL).ListRuns(ctx)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Authentication required" {
+		t.Errorf("ListRuns() without credentials error = %v", err)
+	}
+
+	c := New(ts.URL, WithAPIKey("secret"))
+	if _, err := c.GetRun(ctx, strings.Repeat("0", 32)); !IsNotFound(err) {
+		t.Errorf("GetRun() of an unknown run error = %v, want not found", err)
+	}
+
+	_, err = c.Reconcile(ctx, ReconcileRequest{
+		SystemFile: File{Name: "system.csv", Content: strings.NewReader(systemCSV)},
+		BankFiles:  []File{{Name: "bank-a.csv", Content: strings.NewReader(bankCSV)}},
+		StartDate:  "2024-12-31",
+		EndDate:    "2024-12-01",
+	})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Reconcile() with inverted dates error = %v, want 400", err)
+	}
+}
+
+func TestClientEvents(t *testing.T) {
+	ts := newTestServer(t)
+	c := New(ts.URL, WithAPIKey("secret"))
+	ctx := context.Background()
+
+	if err := c.Events(ctx, "close-2024-12", func(JobEvent) {}); !IsNotFound(err) {
+		t.Errorf("Events() of an unknown job error = %v, want not found", err)
+	}
+
+	result, err := c.Reconcile(ctx, ReconcileRequest{
+		SystemFile: File{Name: "system.csv", Content: strings.NewReader(systemCSV)},
+		BankFiles:  []File{{Name: "bank-a.csv", Content: strings.NewReader(bankCSV)}},
+		StartDate:  "2024-12-01",
+		EndDate:    "2024-12-31",
+	}, WithJobID("close-2024-12"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []JobEvent
+	if err := c.Events(ctx, "close-2024-12", func(event JobEvent) { events = append(events, event) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) < 2 || events[0].ID != 1 || events[0].Name != EventProgress || events[0].Progress == nil {
+		t.Fatalf("Events() = %+v", events)
+	}
+	var parsed bool
+	for _, event := range events[:len(events)-1] {
+		parsed = parsed || event.Progress.Stage == StageParsed && event.Progress.File == "system.csv" && event.Progress.Rows == 2
+	}
+	if !parsed {
+		t.Errorf("Events() = %+v, want the rows of system.csv", events)
+	}
+	if last := events[len(events)-1]; last.Name != EventDone || last.RunID != result.RunID {
+		t.Errorf("last event = %+v, want done with run %s", last, result.RunID)
+	}
+}
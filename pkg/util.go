@@ -1,11 +1,12 @@
 package pkg
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 )
@@ -32,33 +33,34 @@ func ValidateDates(startDate, endDate string) error {
 	return nil
 }
 
-func ValidateFile(fileHeader *multipart.FileHeader) error {
-	if !strings.HasSuffix(strings.ToLower(fileHeader.Filename), ".csv") {
-		return fmt.Errorf("invalid file type")
+// ValidateFile checks the file has one of the allowed extensions, ".csv" when none is given
+func ValidateFile(fileHeader *multipart.FileHeader, extensions ...string) error {
+	if len(extensions) == 0 {
+		extensions = []string{".csv"}
 	}
 
-	return nil
-}
-
-func SaveFile(fileHeader *multipart.FileHeader, uploadsDir, prefix string) (string, error) {
-	src, err := fileHeader.Open()
-	if err != nil {
-		return "", err
+	name := strings.ToLower(fileHeader.Filename)
+	for _, ext := range extensions {
+		if strings.HasSuffix(name, ext) {
+			return nil
+		}
 	}
-	defer src.Close()
 
-	// Create unique filename with timestamp
-	timestamp := time.Now().Format("20060102150405")
-	filename := fmt.Sprintf("%s_%s_%s", prefix, timestamp, fileHeader.Filename)
-	dst, err := os.Create(filepath.Join(uploadsDir, filename))
+	return fmt.Errorf("invalid file type")
+}
+
+// FileChecksum returns the hex encoded SHA-256 checksum of the file content
+func FileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
-	defer dst.Close()
+	defer file.Close()
 
-	if _, err := io.Copy(dst, src); err != nil {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%s/%s", uploadsDir, filename), nil
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
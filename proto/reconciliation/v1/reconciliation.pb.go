@@ -0,0 +1,2343 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: reconciliation/v1/reconciliation.proto
+
+package reconciliationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ReconcileRequest is a message of the client stream, the first one holds the options
+type ReconcileRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*ReconcileRequest_Options
+	//	*ReconcileRequest_System
+	//	*ReconcileRequest_Bank
+	Payload isReconcileRequest_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *ReconcileRequest) Reset() {
+	*x = ReconcileRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReconcileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileRequest) ProtoMessage() {}
+
+func (x *ReconcileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileRequest) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *ReconcileRequest) GetPayload() isReconcileRequest_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *ReconcileRequest) GetOptions() *ReconcileOptions {
+	if x, ok := x.GetPayload().(*ReconcileRequest_Options); ok {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ReconcileRequest) GetSystem() *SystemBatch {
+	if x, ok := x.GetPayload().(*ReconcileRequest_System); ok {
+		return x.System
+	}
+	return nil
+}
+
+func (x *ReconcileRequest) GetBank() *BankBatch {
+	if x, ok := x.GetPayload().(*ReconcileRequest_Bank); ok {
+		return x.Bank
+	}
+	return nil
+}
+
+type isReconcileRequest_Payload interface {
+	isReconcileRequest_Payload()
+}
+
+type ReconcileRequest_Options struct {
+	Options *ReconcileOptions `protobuf:"bytes,1,opt,name=options,proto3,oneof"`
+}
+
+type ReconcileRequest_System struct {
+	System *SystemBatch `protobuf:"bytes,2,opt,name=system,proto3,oneof"`
+}
+
+type ReconcileRequest_Bank struct {
+	Bank *BankBatch `protobuf:"bytes,3,opt,name=bank,proto3,oneof"`
+}
+
+func (*ReconcileRequest_Options) isReconcileRequest_Payload() {}
+
+func (*ReconcileRequest_System) isReconcileRequest_Payload() {}
+
+func (*ReconcileRequest_Bank) isReconcileRequest_Payload() {}
+
+// ReconcileOptions holds the timeframe and the options of a reconciliation, options left unset
+// use the settings of the server
+type ReconcileOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Timeframe in YYYY-MM-DD format, both days included
+	StartDate          string `protobuf:"bytes,1,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate            string `protobuf:"bytes,2,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	TransferWindowDays *int32 `protobuf:"varint,3,opt,name=transfer_window_days,json=transferWindowDays,proto3,oneof" json:"transfer_window_days,omitempty"`
+	// Matched pairs listed in the results: all, imperfect or none
+	Matches  string              `protobuf:"bytes,4,opt,name=matches,proto3" json:"matches,omitempty"`
+	FeeRules []*FeeRule          `protobuf:"bytes,5,rep,name=fee_rules,json=feeRules,proto3" json:"fee_rules,omitempty"`
+	Balances []*StatementBalance `protobuf:"bytes,6,rep,name=balances,proto3" json:"balances,omitempty"`
+}
+
+func (x *ReconcileOptions) Reset() {
+	*x = ReconcileOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReconcileOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileOptions) ProtoMessage() {}
+
+func (x *ReconcileOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileOptions.ProtoReflect.Descriptor instead.
+func (*ReconcileOptions) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ReconcileOptions) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *ReconcileOptions) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *ReconcileOptions) GetTransferWindowDays() int32 {
+	if x != nil && x.TransferWindowDays != nil {
+		return *x.TransferWindowDays
+	}
+	return 0
+}
+
+func (x *ReconcileOptions) GetMatches() string {
+	if x != nil {
+		return x.Matches
+	}
+	return ""
+}
+
+func (x *ReconcileOptions) GetFeeRules() []*FeeRule {
+	if x != nil {
+		return x.FeeRules
+	}
+	return nil
+}
+
+func (x *ReconcileOptions) GetBalances() []*StatementBalance {
+	if x != nil {
+		return x.Balances
+	}
+	return nil
+}
+
+// SystemBatch is a batch of system transactions
+type SystemBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Transactions []*Transaction `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+}
+
+func (x *SystemBatch) Reset() {
+	*x = SystemBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SystemBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemBatch) ProtoMessage() {}
+
+func (x *SystemBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemBatch.ProtoReflect.Descriptor instead.
+func (*SystemBatch) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SystemBatch) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+// BankBatch is a batch of statement lines of a bank, a bank may be sent in several batches
+type BankBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bank       string           `protobuf:"bytes,1,opt,name=bank,proto3" json:"bank,omitempty"`
+	Statements []*BankStatement `protobuf:"bytes,2,rep,name=statements,proto3" json:"statements,omitempty"`
+}
+
+func (x *BankBatch) Reset() {
+	*x = BankBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BankBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BankBatch) ProtoMessage() {}
+
+func (x *BankBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BankBatch.ProtoReflect.Descriptor instead.
+func (*BankBatch) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BankBatch) GetBank() string {
+	if x != nil {
+		return x.Bank
+	}
+	return ""
+}
+
+func (x *BankBatch) GetStatements() []*BankStatement {
+	if x != nil {
+		return x.Statements
+	}
+	return nil
+}
+
+// ReconcileResponse is a message of the server stream
+type ReconcileResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Payload:
+	//	*ReconcileResponse_UnmatchedSystem
+	//	*ReconcileResponse_UnmatchedBank
+	//	*ReconcileResponse_Matches
+	//	*ReconcileResponse_Summary
+	//	*ReconcileResponse_Duplicates
+	//	*ReconcileResponse_Reversals
+	//	*ReconcileResponse_InternalTransfers
+	//	*ReconcileResponse_DiscrepantMatches
+	Payload isReconcileResponse_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *ReconcileResponse) Reset() {
+	*x = ReconcileResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReconcileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileResponse) ProtoMessage() {}
+
+func (x *ReconcileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileResponse.ProtoReflect.Descriptor instead.
+func (*ReconcileResponse) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{4}
+}
+
+func (m *ReconcileResponse) GetPayload() isReconcileResponse_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetUnmatchedSystem() *SystemBatch {
+	if x, ok := x.GetPayload().(*ReconcileResponse_UnmatchedSystem); ok {
+		return x.UnmatchedSystem
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetUnmatchedBank() *BankBatch {
+	if x, ok := x.GetPayload().(*ReconcileResponse_UnmatchedBank); ok {
+		return x.UnmatchedBank
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetMatches() *MatchBatch {
+	if x, ok := x.GetPayload().(*ReconcileResponse_Matches); ok {
+		return x.Matches
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetSummary() *Summary {
+	if x, ok := x.GetPayload().(*ReconcileResponse_Summary); ok {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetDuplicates() *DuplicateBatch {
+	if x, ok := x.GetPayload().(*ReconcileResponse_Duplicates); ok {
+		return x.Duplicates
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetReversals() *ReversalBatch {
+	if x, ok := x.GetPayload().(*ReconcileResponse_Reversals); ok {
+		return x.Reversals
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetInternalTransfers() *TransferBatch {
+	if x, ok := x.GetPayload().(*ReconcileResponse_InternalTransfers); ok {
+		return x.InternalTransfers
+	}
+	return nil
+}
+
+func (x *ReconcileResponse) GetDiscrepantMatches() *MatchBatch {
+	if x, ok := x.GetPayload().(*ReconcileResponse_DiscrepantMatches); ok {
+		return x.DiscrepantMatches
+	}
+	return nil
+}
+
+type isReconcileResponse_Payload interface {
+	isReconcileResponse_Payload()
+}
+
+type ReconcileResponse_UnmatchedSystem struct {
+	UnmatchedSystem *SystemBatch `protobuf:"bytes,1,opt,name=unmatched_system,json=unmatchedSystem,proto3,oneof"`
+}
+
+type ReconcileResponse_UnmatchedBank struct {
+	UnmatchedBank *BankBatch `protobuf:"bytes,2,opt,name=unmatched_bank,json=unmatchedBank,proto3,oneof"`
+}
+
+type ReconcileResponse_Matches struct {
+	Matches *MatchBatch `protobuf:"bytes,3,opt,name=matches,proto3,oneof"`
+}
+
+type ReconcileResponse_Summary struct {
+	Summary *Summary `protobuf:"bytes,4,opt,name=summary,proto3,oneof"`
+}
+
+type ReconcileResponse_Duplicates struct {
+	Duplicates *DuplicateBatch `protobuf:"bytes,5,opt,name=duplicates,proto3,oneof"`
+}
+
+type ReconcileResponse_Reversals struct {
+	Reversals *ReversalBatch `protobuf:"bytes,6,opt,name=reversals,proto3,oneof"`
+}
+
+type ReconcileResponse_InternalTransfers struct {
+	InternalTransfers *TransferBatch `protobuf:"bytes,7,opt,name=internal_transfers,json=internalTransfers,proto3,oneof"`
+}
+
+type ReconcileResponse_DiscrepantMatches struct {
+	// Matched pairs with a residual, sent whatever matches lists
+	DiscrepantMatches *MatchBatch `protobuf:"bytes,8,opt,name=discrepant_matches,json=discrepantMatches,proto3,oneof"`
+}
+
+func (*ReconcileResponse_UnmatchedSystem) isReconcileResponse_Payload() {}
+
+func (*ReconcileResponse_UnmatchedBank) isReconcileResponse_Payload() {}
+
+func (*ReconcileResponse_Matches) isReconcileResponse_Payload() {}
+
+func (*ReconcileResponse_Summary) isReconcileResponse_Payload() {}
+
+func (*ReconcileResponse_Duplicates) isReconcileResponse_Payload() {}
+
+func (*ReconcileResponse_Reversals) isReconcileResponse_Payload() {}
+
+func (*ReconcileResponse_InternalTransfers) isReconcileResponse_Payload() {}
+
+func (*ReconcileResponse_DiscrepantMatches) isReconcileResponse_Payload() {}
+
+// MatchBatch is a batch of matched pairs
+type MatchBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Matches []*MatchedPair `protobuf:"bytes,1,rep,name=matches,proto3" json:"matches,omitempty"`
+}
+
+func (x *MatchBatch) Reset() {
+	*x = MatchBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchBatch) ProtoMessage() {}
+
+func (x *MatchBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchBatch.ProtoReflect.Descriptor instead.
+func (*MatchBatch) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MatchBatch) GetMatches() []*MatchedPair {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+// DuplicateBatch is a batch of duplicate groups
+type DuplicateBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Duplicates []*Duplicate `protobuf:"bytes,1,rep,name=duplicates,proto3" json:"duplicates,omitempty"`
+}
+
+func (x *DuplicateBatch) Reset() {
+	*x = DuplicateBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DuplicateBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DuplicateBatch) ProtoMessage() {}
+
+func (x *DuplicateBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DuplicateBatch.ProtoReflect.Descriptor instead.
+func (*DuplicateBatch) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DuplicateBatch) GetDuplicates() []*Duplicate {
+	if x != nil {
+		return x.Duplicates
+	}
+	return nil
+}
+
+// ReversalBatch is a batch of reversed entries
+type ReversalBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reversals []*Reversal `protobuf:"bytes,1,rep,name=reversals,proto3" json:"reversals,omitempty"`
+}
+
+func (x *ReversalBatch) Reset() {
+	*x = ReversalBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReversalBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReversalBatch) ProtoMessage() {}
+
+func (x *ReversalBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReversalBatch.ProtoReflect.Descriptor instead.
+func (*ReversalBatch) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReversalBatch) GetReversals() []*Reversal {
+	if x != nil {
+		return x.Reversals
+	}
+	return nil
+}
+
+// TransferBatch is a batch of internal transfers
+type TransferBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Transfers []*InternalTransfer `protobuf:"bytes,1,rep,name=transfers,proto3" json:"transfers,omitempty"`
+}
+
+func (x *TransferBatch) Reset() {
+	*x = TransferBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransferBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferBatch) ProtoMessage() {}
+
+func (x *TransferBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferBatch.ProtoReflect.Descriptor instead.
+func (*TransferBatch) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TransferBatch) GetTransfers() []*InternalTransfer {
+	if x != nil {
+		return x.Transfers
+	}
+	return nil
+}
+
+// Summary holds the totals of a reconciliation, the duplicates, reversals and internal
+// transfers being counted among the records streamed before it
+type Summary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RunId             string          `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	RecordsParsed     int32           `protobuf:"varint,2,opt,name=records_parsed,json=recordsParsed,proto3" json:"records_parsed,omitempty"`
+	TotalProcessed    int32           `protobuf:"varint,3,opt,name=total_processed,json=totalProcessed,proto3" json:"total_processed,omitempty"`
+	Matched           int32           `protobuf:"varint,4,opt,name=matched,proto3" json:"matched,omitempty"`
+	Unmatched         int32           `protobuf:"varint,5,opt,name=unmatched,proto3" json:"unmatched,omitempty"`
+	Fees              float64         `protobuf:"fixed64,6,opt,name=fees,proto3" json:"fees,omitempty"`
+	Discrepancies     float64         `protobuf:"fixed64,7,opt,name=discrepancies,proto3" json:"discrepancies,omitempty"`
+	ByBank            []*BankSummary  `protobuf:"bytes,8,rep,name=by_bank,json=byBank,proto3" json:"by_bank,omitempty"`
+	BalanceChecks     []*BalanceCheck `protobuf:"bytes,9,rep,name=balance_checks,json=balanceChecks,proto3" json:"balance_checks,omitempty"`
+	Duplicates        int32           `protobuf:"varint,10,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	Reversals         int32           `protobuf:"varint,11,opt,name=reversals,proto3" json:"reversals,omitempty"`
+	InternalTransfers int32           `protobuf:"varint,12,opt,name=internal_transfers,json=internalTransfers,proto3" json:"internal_transfers,omitempty"`
+}
+
+func (x *Summary) Reset() {
+	*x = Summary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Summary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Summary) ProtoMessage() {}
+
+func (x *Summary) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Summary.ProtoReflect.Descriptor instead.
+func (*Summary) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Summary) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *Summary) GetRecordsParsed() int32 {
+	if x != nil {
+		return x.RecordsParsed
+	}
+	return 0
+}
+
+func (x *Summary) GetTotalProcessed() int32 {
+	if x != nil {
+		return x.TotalProcessed
+	}
+	return 0
+}
+
+func (x *Summary) GetMatched() int32 {
+	if x != nil {
+		return x.Matched
+	}
+	return 0
+}
+
+func (x *Summary) GetUnmatched() int32 {
+	if x != nil {
+		return x.Unmatched
+	}
+	return 0
+}
+
+func (x *Summary) GetFees() float64 {
+	if x != nil {
+		return x.Fees
+	}
+	return 0
+}
+
+func (x *Summary) GetDiscrepancies() float64 {
+	if x != nil {
+		return x.Discrepancies
+	}
+	return 0
+}
+
+func (x *Summary) GetByBank() []*BankSummary {
+	if x != nil {
+		return x.ByBank
+	}
+	return nil
+}
+
+func (x *Summary) GetBalanceChecks() []*BalanceCheck {
+	if x != nil {
+		return x.BalanceChecks
+	}
+	return nil
+}
+
+func (x *Summary) GetDuplicates() int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *Summary) GetReversals() int32 {
+	if x != nil {
+		return x.Reversals
+	}
+	return 0
+}
+
+func (x *Summary) GetInternalTransfers() int32 {
+	if x != nil {
+		return x.InternalTransfers
+	}
+	return 0
+}
+
+// Transaction is a system transaction
+type Transaction struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TrxId  string  `protobuf:"bytes,1,opt,name=trx_id,json=trxId,proto3" json:"trx_id,omitempty"`
+	Amount float64 `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// DEBIT or CREDIT
+	Type            string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	TransactionTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=transaction_time,json=transactionTime,proto3" json:"transaction_time,omitempty"`
+	Description     string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Transaction) GetTrxId() string {
+	if x != nil {
+		return x.TrxId
+	}
+	return ""
+}
+
+func (x *Transaction) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Transaction) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Transaction) GetTransactionTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TransactionTime
+	}
+	return nil
+}
+
+func (x *Transaction) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// BankStatement is a bank statement line
+type BankStatement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UniqueIdentifier string `protobuf:"bytes,1,opt,name=unique_identifier,json=uniqueIdentifier,proto3" json:"unique_identifier,omitempty"`
+	// Negative for debits, as in statement files
+	Amount      float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Date        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=date,proto3" json:"date,omitempty"`
+	Description string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+}
+
+func (x *BankStatement) Reset() {
+	*x = BankStatement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BankStatement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BankStatement) ProtoMessage() {}
+
+func (x *BankStatement) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BankStatement.ProtoReflect.Descriptor instead.
+func (*BankStatement) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BankStatement) GetUniqueIdentifier() string {
+	if x != nil {
+		return x.UniqueIdentifier
+	}
+	return ""
+}
+
+func (x *BankStatement) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *BankStatement) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *BankStatement) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// MatchedPair is a system transaction matched with a bank statement line
+type MatchedPair struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	System        *Transaction   `protobuf:"bytes,1,opt,name=system,proto3" json:"system,omitempty"`
+	Statement     *BankStatement `protobuf:"bytes,2,opt,name=statement,proto3" json:"statement,omitempty"`
+	Bank          string         `protobuf:"bytes,3,opt,name=bank,proto3" json:"bank,omitempty"`
+	AmountDelta   float64        `protobuf:"fixed64,4,opt,name=amount_delta,json=amountDelta,proto3" json:"amount_delta,omitempty"`
+	Fee           float64        `protobuf:"fixed64,5,opt,name=fee,proto3" json:"fee,omitempty"`
+	Residual      float64        `protobuf:"fixed64,6,opt,name=residual,proto3" json:"residual,omitempty"`
+	DateDeltaDays float64        `protobuf:"fixed64,7,opt,name=date_delta_days,json=dateDeltaDays,proto3" json:"date_delta_days,omitempty"`
+	Rule          string         `protobuf:"bytes,8,opt,name=rule,proto3" json:"rule,omitempty"`
+}
+
+func (x *MatchedPair) Reset() {
+	*x = MatchedPair{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MatchedPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchedPair) ProtoMessage() {}
+
+func (x *MatchedPair) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchedPair.ProtoReflect.Descriptor instead.
+func (*MatchedPair) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MatchedPair) GetSystem() *Transaction {
+	if x != nil {
+		return x.System
+	}
+	return nil
+}
+
+func (x *MatchedPair) GetStatement() *BankStatement {
+	if x != nil {
+		return x.Statement
+	}
+	return nil
+}
+
+func (x *MatchedPair) GetBank() string {
+	if x != nil {
+		return x.Bank
+	}
+	return ""
+}
+
+func (x *MatchedPair) GetAmountDelta() float64 {
+	if x != nil {
+		return x.AmountDelta
+	}
+	return 0
+}
+
+func (x *MatchedPair) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *MatchedPair) GetResidual() float64 {
+	if x != nil {
+		return x.Residual
+	}
+	return 0
+}
+
+func (x *MatchedPair) GetDateDeltaDays() float64 {
+	if x != nil {
+		return x.DateDeltaDays
+	}
+	return 0
+}
+
+func (x *MatchedPair) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+// Duplicate groups the records of a source sharing their identifier, or likely repeated
+type Duplicate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// system or the bank name the records came from
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// exact_key or likely
+	Reason       string           `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	Key          string           `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Transactions []*Transaction   `protobuf:"bytes,4,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Statements   []*BankStatement `protobuf:"bytes,5,rep,name=statements,proto3" json:"statements,omitempty"`
+}
+
+func (x *Duplicate) Reset() {
+	*x = Duplicate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Duplicate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Duplicate) ProtoMessage() {}
+
+func (x *Duplicate) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Duplicate.ProtoReflect.Descriptor instead.
+func (*Duplicate) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Duplicate) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Duplicate) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Duplicate) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Duplicate) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *Duplicate) GetStatements() []*BankStatement {
+	if x != nil {
+		return x.Statements
+	}
+	return nil
+}
+
+// Reversal pairs an original entry with the entry reversing it, the original first
+type Reversal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// system or the bank name the entries came from
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// same_reference or referenced
+	Rule         string           `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	Amount       float64          `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Transactions []*Transaction   `protobuf:"bytes,4,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Statements   []*BankStatement `protobuf:"bytes,5,rep,name=statements,proto3" json:"statements,omitempty"`
+}
+
+func (x *Reversal) Reset() {
+	*x = Reversal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Reversal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reversal) ProtoMessage() {}
+
+func (x *Reversal) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reversal.ProtoReflect.Descriptor instead.
+func (*Reversal) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Reversal) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Reversal) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *Reversal) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Reversal) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *Reversal) GetStatements() []*BankStatement {
+	if x != nil {
+		return x.Statements
+	}
+	return nil
+}
+
+// InternalTransfer pairs the debit leg in the sending bank with the credit leg in the receiving bank
+type InternalTransfer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromBank string         `protobuf:"bytes,1,opt,name=from_bank,json=fromBank,proto3" json:"from_bank,omitempty"`
+	From     *BankStatement `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	ToBank   string         `protobuf:"bytes,3,opt,name=to_bank,json=toBank,proto3" json:"to_bank,omitempty"`
+	To       *BankStatement `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *InternalTransfer) Reset() {
+	*x = InternalTransfer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InternalTransfer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InternalTransfer) ProtoMessage() {}
+
+func (x *InternalTransfer) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InternalTransfer.ProtoReflect.Descriptor instead.
+func (*InternalTransfer) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *InternalTransfer) GetFromBank() string {
+	if x != nil {
+		return x.FromBank
+	}
+	return ""
+}
+
+func (x *InternalTransfer) GetFrom() *BankStatement {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *InternalTransfer) GetToBank() string {
+	if x != nil {
+		return x.ToBank
+	}
+	return ""
+}
+
+func (x *InternalTransfer) GetTo() *BankStatement {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+// FeeRule describes the fee a bank deducts from every settlement
+type FeeRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bank string `protobuf:"bytes,1,opt,name=bank,proto3" json:"bank,omitempty"`
+	// flat, percentage or tiered
+	Type    string     `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Flat    float64    `protobuf:"fixed64,3,opt,name=flat,proto3" json:"flat,omitempty"`
+	Percent float64    `protobuf:"fixed64,4,opt,name=percent,proto3" json:"percent,omitempty"`
+	Tiers   []*FeeTier `protobuf:"bytes,5,rep,name=tiers,proto3" json:"tiers,omitempty"`
+	Min     float64    `protobuf:"fixed64,6,opt,name=min,proto3" json:"min,omitempty"`
+	Cap     float64    `protobuf:"fixed64,7,opt,name=cap,proto3" json:"cap,omitempty"`
+}
+
+func (x *FeeRule) Reset() {
+	*x = FeeRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeeRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeeRule) ProtoMessage() {}
+
+func (x *FeeRule) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeeRule.ProtoReflect.Descriptor instead.
+func (*FeeRule) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FeeRule) GetBank() string {
+	if x != nil {
+		return x.Bank
+	}
+	return ""
+}
+
+func (x *FeeRule) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FeeRule) GetFlat() float64 {
+	if x != nil {
+		return x.Flat
+	}
+	return 0
+}
+
+func (x *FeeRule) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *FeeRule) GetTiers() []*FeeTier {
+	if x != nil {
+		return x.Tiers
+	}
+	return nil
+}
+
+func (x *FeeRule) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *FeeRule) GetCap() float64 {
+	if x != nil {
+		return x.Cap
+	}
+	return 0
+}
+
+// FeeTier is a band of a tiered fee
+type FeeTier struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UpTo    float64 `protobuf:"fixed64,1,opt,name=up_to,json=upTo,proto3" json:"up_to,omitempty"`
+	Flat    float64 `protobuf:"fixed64,2,opt,name=flat,proto3" json:"flat,omitempty"`
+	Percent float64 `protobuf:"fixed64,3,opt,name=percent,proto3" json:"percent,omitempty"`
+}
+
+func (x *FeeTier) Reset() {
+	*x = FeeTier{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeeTier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeeTier) ProtoMessage() {}
+
+func (x *FeeTier) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeeTier.ProtoReflect.Descriptor instead.
+func (*FeeTier) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *FeeTier) GetUpTo() float64 {
+	if x != nil {
+		return x.UpTo
+	}
+	return 0
+}
+
+func (x *FeeTier) GetFlat() float64 {
+	if x != nil {
+		return x.Flat
+	}
+	return 0
+}
+
+func (x *FeeTier) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+// StatementBalance holds the balances printed on a bank statement
+type StatementBalance struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bank    string  `protobuf:"bytes,1,opt,name=bank,proto3" json:"bank,omitempty"`
+	Opening float64 `protobuf:"fixed64,2,opt,name=opening,proto3" json:"opening,omitempty"`
+	Closing float64 `protobuf:"fixed64,3,opt,name=closing,proto3" json:"closing,omitempty"`
+}
+
+func (x *StatementBalance) Reset() {
+	*x = StatementBalance{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatementBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatementBalance) ProtoMessage() {}
+
+func (x *StatementBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatementBalance.ProtoReflect.Descriptor instead.
+func (*StatementBalance) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *StatementBalance) GetBank() string {
+	if x != nil {
+		return x.Bank
+	}
+	return ""
+}
+
+func (x *StatementBalance) GetOpening() float64 {
+	if x != nil {
+		return x.Opening
+	}
+	return 0
+}
+
+func (x *StatementBalance) GetClosing() float64 {
+	if x != nil {
+		return x.Closing
+	}
+	return 0
+}
+
+// BankSummary holds the reconciliation figures of a bank
+type BankSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bank          string  `protobuf:"bytes,1,opt,name=bank,proto3" json:"bank,omitempty"`
+	Matched       int32   `protobuf:"varint,2,opt,name=matched,proto3" json:"matched,omitempty"`
+	Unmatched     int32   `protobuf:"varint,3,opt,name=unmatched,proto3" json:"unmatched,omitempty"`
+	Fees          float64 `protobuf:"fixed64,4,opt,name=fees,proto3" json:"fees,omitempty"`
+	Discrepancies float64 `protobuf:"fixed64,5,opt,name=discrepancies,proto3" json:"discrepancies,omitempty"`
+}
+
+func (x *BankSummary) Reset() {
+	*x = BankSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BankSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BankSummary) ProtoMessage() {}
+
+func (x *BankSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BankSummary.ProtoReflect.Descriptor instead.
+func (*BankSummary) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BankSummary) GetBank() string {
+	if x != nil {
+		return x.Bank
+	}
+	return ""
+}
+
+func (x *BankSummary) GetMatched() int32 {
+	if x != nil {
+		return x.Matched
+	}
+	return 0
+}
+
+func (x *BankSummary) GetUnmatched() int32 {
+	if x != nil {
+		return x.Unmatched
+	}
+	return 0
+}
+
+func (x *BankSummary) GetFees() float64 {
+	if x != nil {
+		return x.Fees
+	}
+	return 0
+}
+
+func (x *BankSummary) GetDiscrepancies() float64 {
+	if x != nil {
+		return x.Discrepancies
+	}
+	return 0
+}
+
+// BalanceCheck verifies a bank statement is complete
+type BalanceCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bank       string  `protobuf:"bytes,1,opt,name=bank,proto3" json:"bank,omitempty"`
+	Opening    float64 `protobuf:"fixed64,2,opt,name=opening,proto3" json:"opening,omitempty"`
+	Closing    float64 `protobuf:"fixed64,3,opt,name=closing,proto3" json:"closing,omitempty"`
+	LinesTotal float64 `protobuf:"fixed64,4,opt,name=lines_total,json=linesTotal,proto3" json:"lines_total,omitempty"`
+	Difference float64 `protobuf:"fixed64,5,opt,name=difference,proto3" json:"difference,omitempty"`
+	Balanced   bool    `protobuf:"varint,6,opt,name=balanced,proto3" json:"balanced,omitempty"`
+}
+
+func (x *BalanceCheck) Reset() {
+	*x = BalanceCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BalanceCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceCheck) ProtoMessage() {}
+
+func (x *BalanceCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_reconciliation_v1_reconciliation_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceCheck.ProtoReflect.Descriptor instead.
+func (*BalanceCheck) Descriptor() ([]byte, []int) {
+	return file_reconciliation_v1_reconciliation_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BalanceCheck) GetBank() string {
+	if x != nil {
+		return x.Bank
+	}
+	return ""
+}
+
+func (x *BalanceCheck) GetOpening() float64 {
+	if x != nil {
+		return x.Opening
+	}
+	return 0
+}
+
+func (x *BalanceCheck) GetClosing() float64 {
+	if x != nil {
+		return x.Closing
+	}
+	return 0
+}
+
+func (x *BalanceCheck) GetLinesTotal() float64 {
+	if x != nil {
+		return x.LinesTotal
+	}
+	return 0
+}
+
+func (x *BalanceCheck) GetDifference() float64 {
+	if x != nil {
+		return x.Difference
+	}
+	return 0
+}
+
+func (x *BalanceCheck) GetBalanced() bool {
+	if x != nil {
+		return x.Balanced
+	}
+	return false
+}
+
+var File_reconciliation_v1_reconciliation_proto protoreflect.FileDescriptor
+
+var file_reconciliation_v1_reconciliation_proto_rawDesc = []byte{
+	0x0a, 0x26, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x76, 0x31, 0x2f, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x11, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63,
+	0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xcc, 0x01, 0x0a,
+	0x10, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x3f, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x23, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x65,
+	0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x38, 0x0a, 0x06, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x48, 0x00, 0x52, 0x06, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x32, 0x0a, 0x04,
+	0x62, 0x61, 0x6e, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x72, 0x65, 0x63,
+	0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x61, 0x6e, 0x6b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52, 0x04, 0x62, 0x61, 0x6e, 0x6b,
+	0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0xb0, 0x02, 0x0a, 0x10,
+	0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12,
+	0x19, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x12, 0x35, 0x0a, 0x14, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x64, 0x61,
+	0x79, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x12, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x44, 0x61, 0x79, 0x73, 0x88, 0x01,
+	0x01, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x09, 0x66,
+	0x65, 0x65, 0x5f, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x46, 0x65, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x08, 0x66, 0x65, 0x65, 0x52,
+	0x75, 0x6c, 0x65, 0x73, 0x12, 0x3f, 0x0a, 0x08, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69,
+	0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x62, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x73, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x5f, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x22, 0x51,
+	0x0a, 0x0b, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x42, 0x0a,
+	0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x22, 0x61, 0x0a, 0x09, 0x42, 0x61, 0x6e, 0x6b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x12,
+	0x0a, 0x04, 0x62, 0x61, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x61,
+	0x6e, 0x6b, 0x12, 0x40, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69,
+	0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x6b, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x22, 0xcf, 0x04, 0x0a, 0x11, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69,
+	0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x10, 0x75, 0x6e,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52, 0x0f, 0x75, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x64, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x45, 0x0a, 0x0e, 0x75, 0x6e, 0x6d, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x64, 0x5f, 0x62, 0x61, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1c, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x6b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52,
+	0x0d, 0x75, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x42, 0x61, 0x6e, 0x6b, 0x12, 0x39,
+	0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x00,
+	0x52, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x07, 0x73, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x72, 0x65, 0x63,
+	0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x48, 0x00, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72,
+	0x79, 0x12, 0x43, 0x0a, 0x0a, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c,
+	0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52, 0x0a, 0x64, 0x75, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x12, 0x40, 0x0a, 0x09, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73,
+	0x61, 0x6c, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x63, 0x6f,
+	0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x76, 0x65, 0x72, 0x73, 0x61, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52, 0x09, 0x72,
+	0x65, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6c, 0x73, 0x12, 0x51, 0x0a, 0x12, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52, 0x11, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e,
+	0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x12, 0x4e, 0x0a, 0x12, 0x64,
+	0x69, 0x73, 0x63, 0x72, 0x65, 0x70, 0x61, 0x6e, 0x74, 0x5f, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63,
+	0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x74, 0x63,
+	0x68, 0x42, 0x61, 0x74, 0x63, 0x68, 0x48, 0x00, 0x52, 0x11, 0x64, 0x69, 0x73, 0x63, 0x72, 0x65,
+	0x70, 0x61, 0x6e, 0x74, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x70,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x46, 0x0a, 0x0a, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x38, 0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c,
+	0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x65,
+	0x64, 0x50, 0x61, 0x69, 0x72, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x22, 0x4e,
+	0x0a, 0x0e, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x12, 0x3c, 0x0a, 0x0a, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61,
+	0x74, 0x65, 0x52, 0x0a, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x22, 0x4a,
+	0x0a, 0x0d, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x39, 0x0a, 0x09, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6c, 0x52,
+	0x09, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6c, 0x73, 0x22, 0x52, 0x0a, 0x0d, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x41, 0x0a, 0x09, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23,
+	0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x65, 0x72, 0x52, 0x09, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x22, 0xd0,
+	0x03, 0x0a, 0x07, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x75,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49,
+	0x64, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x5f, 0x70, 0x61, 0x72,
+	0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x72, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x73, 0x50, 0x61, 0x72, 0x73, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x5f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x75,
+	0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09,
+	0x75, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x65, 0x65,
+	0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x66, 0x65, 0x65, 0x73, 0x12, 0x24, 0x0a,
+	0x0d, 0x64, 0x69, 0x73, 0x63, 0x72, 0x65, 0x70, 0x61, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x64, 0x69, 0x73, 0x63, 0x72, 0x65, 0x70, 0x61, 0x6e, 0x63,
+	0x69, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x07, 0x62, 0x79, 0x5f, 0x62, 0x61, 0x6e, 0x6b, 0x18, 0x08,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x6b, 0x53, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x52, 0x06, 0x62, 0x79, 0x42, 0x61, 0x6e, 0x6b, 0x12, 0x46, 0x0a, 0x0e,
+	0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x18, 0x09,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0d, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x75, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x74, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6c,
+	0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x72, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61,
+	0x6c, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x73, 0x22, 0xb9, 0x01, 0x0a, 0x0b, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x15, 0x0a, 0x06, 0x74, 0x72, 0x78, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x74, 0x72, 0x78, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x12, 0x45, 0x0a, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0f, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xa6, 0x01,
+	0x0a, 0x0d, 0x42, 0x61, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x2b, 0x0a, 0x11, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69,
+	0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x75, 0x6e, 0x69, 0x71,
+	0x75, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xa6, 0x02, 0x0a, 0x0b, 0x4d, 0x61, 0x74, 0x63, 0x68,
+	0x65, 0x64, 0x50, 0x61, 0x69, 0x72, 0x12, 0x36, 0x0a, 0x06, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69,
+	0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x3e,
+	0x0a, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x62, 0x61, 0x6e, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x61,
+	0x6e, 0x6b, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x64, 0x65, 0x6c,
+	0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74,
+	0x44, 0x65, 0x6c, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x66, 0x65, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x03, 0x66, 0x65, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x69, 0x64,
+	0x75, 0x61, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x72, 0x65, 0x73, 0x69, 0x64,
+	0x75, 0x61, 0x6c, 0x12, 0x26, 0x0a, 0x0f, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x64, 0x65, 0x6c, 0x74,
+	0x61, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x64, 0x61,
+	0x74, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x44, 0x61, 0x79, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x75, 0x6c, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x22,
+	0xd3, 0x01, 0x0a, 0x09, 0x44, 0x75, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x42, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c,
+	0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x40, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63,
+	0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x6b,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0xd4, 0x01, 0x0a, 0x08, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73,
+	0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x75,
+	0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x42, 0x0a, 0x0c, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72,
+	0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x40, 0x0a, 0x0a, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x42, 0x61, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0xb0, 0x01, 0x0a,
+	0x10, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x62, 0x61, 0x6e, 0x6b, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x42, 0x61, 0x6e, 0x6b, 0x12, 0x34,
+	0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72,
+	0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x61, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x6f, 0x5f, 0x62, 0x61, 0x6e, 0x6b, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x6f, 0x42, 0x61, 0x6e, 0x6b, 0x12, 0x30, 0x0a,
+	0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x72, 0x65, 0x63, 0x6f,
+	0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61,
+	0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x02, 0x74, 0x6f, 0x22,
+	0xb5, 0x01, 0x0a, 0x07, 0x46, 0x65, 0x65, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x62,
+	0x61, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x61, 0x6e, 0x6b, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x04, 0x66, 0x6c, 0x61, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65,
+	0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x12, 0x30, 0x0a, 0x05, 0x74, 0x69, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x65, 0x65, 0x54, 0x69, 0x65, 0x72, 0x52, 0x05, 0x74, 0x69,
+	0x65, 0x72, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x69, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x03, 0x6d, 0x69, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x61, 0x70, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x03, 0x63, 0x61, 0x70, 0x22, 0x4c, 0x0a, 0x07, 0x46, 0x65, 0x65, 0x54, 0x69,
+	0x65, 0x72, 0x12, 0x13, 0x0a, 0x05, 0x75, 0x70, 0x5f, 0x74, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x04, 0x75, 0x70, 0x54, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x66, 0x6c, 0x61, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x70, 0x65,
+	0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0x5a, 0x0a, 0x10, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x61, 0x6e,
+	0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x61, 0x6e, 0x6b, 0x12, 0x18, 0x0a,
+	0x07, 0x6f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07,
+	0x6f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x6f, 0x73, 0x69,
+	0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x63, 0x6c, 0x6f, 0x73, 0x69, 0x6e,
+	0x67, 0x22, 0x93, 0x01, 0x0a, 0x0b, 0x42, 0x61, 0x6e, 0x6b, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72,
+	0x79, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x61, 0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x62, 0x61, 0x6e, 0x6b, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x75, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x09, 0x75, 0x6e, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x66, 0x65, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x66, 0x65, 0x65,
+	0x73, 0x12, 0x24, 0x0a, 0x0d, 0x64, 0x69, 0x73, 0x63, 0x72, 0x65, 0x70, 0x61, 0x6e, 0x63, 0x69,
+	0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x64, 0x69, 0x73, 0x63, 0x72, 0x65,
+	0x70, 0x61, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x22, 0xb3, 0x01, 0x0a, 0x0c, 0x42, 0x61, 0x6c, 0x61,
+	0x6e, 0x63, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x61, 0x6e, 0x6b,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62, 0x61, 0x6e, 0x6b, 0x12, 0x18, 0x0a, 0x07,
+	0x6f, 0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x6f,
+	0x70, 0x65, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6c, 0x6f, 0x73, 0x69, 0x6e,
+	0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x63, 0x6c, 0x6f, 0x73, 0x69, 0x6e, 0x67,
+	0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x54, 0x6f, 0x74, 0x61,
+	0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x64, 0x69, 0x66, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x08, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x32, 0x73, 0x0a,
+	0x15, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5a, 0x0a, 0x09, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63,
+	0x69, 0x6c, 0x65, 0x12, 0x23, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x72, 0x65, 0x63, 0x6f, 0x6e,
+	0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63,
+	0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01,
+	0x30, 0x01, 0x42, 0x51, 0x5a, 0x4f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x61, 0x72, 0x68, 0x61, 0x6d, 0x2d, 0x61, 0x62, 0x69, 0x79, 0x61, 0x6e, 0x2f, 0x72, 0x65,
+	0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x2f, 0x76, 0x31, 0x3b, 0x72, 0x65, 0x63, 0x6f, 0x6e, 0x63, 0x69, 0x6c, 0x69, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_reconciliation_v1_reconciliation_proto_rawDescOnce sync.Once
+	file_reconciliation_v1_reconciliation_proto_rawDescData = file_reconciliation_v1_reconciliation_proto_rawDesc
+)
+
+func file_reconciliation_v1_reconciliation_proto_rawDescGZIP() []byte {
+	file_reconciliation_v1_reconciliation_proto_rawDescOnce.Do(func() {
+		file_reconciliation_v1_reconciliation_proto_rawDescData = protoimpl.X.CompressGZIP(file_reconciliation_v1_reconciliation_proto_rawDescData)
+	})
+	return file_reconciliation_v1_reconciliation_proto_rawDescData
+}
+
+var file_reconciliation_v1_reconciliation_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_reconciliation_v1_reconciliation_proto_goTypes = []any{
+	(*ReconcileRequest)(nil),      // 0: reconciliation.v1.ReconcileRequest
+	(*ReconcileOptions)(nil),      // 1: reconciliation.v1.ReconcileOptions
+	(*SystemBatch)(nil),           // 2: reconciliation.v1.SystemBatch
+	(*BankBatch)(nil),             // 3: reconciliation.v1.BankBatch
+	(*ReconcileResponse)(nil),     // 4: reconciliation.v1.ReconcileResponse
+	(*MatchBatch)(nil),            // 5: reconciliation.v1.MatchBatch
+	(*DuplicateBatch)(nil),        // 6: reconciliation.v1.DuplicateBatch
+	(*ReversalBatch)(nil),         // 7: reconciliation.v1.ReversalBatch
+	(*TransferBatch)(nil),         // 8: reconciliation.v1.TransferBatch
+	(*Summary)(nil),               // 9: reconciliation.v1.Summary
+	(*Transaction)(nil),           // 10: reconciliation.v1.Transaction
+	(*BankStatement)(nil),         // 11: reconciliation.v1.BankStatement
+	(*MatchedPair)(nil),           // 12: reconciliation.v1.MatchedPair
+	(*Duplicate)(nil),             // 13: reconciliation.v1.Duplicate
+	(*Reversal)(nil),              // 14: reconciliation.v1.Reversal
+	(*InternalTransfer)(nil),      // 15: reconciliation.v1.InternalTransfer
+	(*FeeRule)(nil),               // 16: reconciliation.v1.FeeRule
+	(*FeeTier)(nil),               // 17: reconciliation.v1.FeeTier
+	(*StatementBalance)(nil),      // 18: reconciliation.v1.StatementBalance
+	(*BankSummary)(nil),           // 19: reconciliation.v1.BankSummary
+	(*BalanceCheck)(nil),          // 20: reconciliation.v1.BalanceCheck
+	(*timestamppb.Timestamp)(nil), // 21: google.protobuf.Timestamp
+}
+var file_reconciliation_v1_reconciliation_proto_depIdxs = []int32{
+	1,  // 0: reconciliation.v1.ReconcileRequest.options:type_name -> reconciliation.v1.ReconcileOptions
+	2,  // 1: reconciliation.v1.ReconcileRequest.system:type_name -> reconciliation.v1.SystemBatch
+	3,  // 2: reconciliation.v1.ReconcileRequest.bank:type_name -> reconciliation.v1.BankBatch
+	16, // 3: reconciliation.v1.ReconcileOptions.fee_rules:type_name -> reconciliation.v1.FeeRule
+	18, // 4: reconciliation.v1.ReconcileOptions.balances:type_name -> reconciliation.v1.StatementBalance
+	10, // 5: reconciliation.v1.SystemBatch.transactions:type_name -> reconciliation.v1.Transaction
+	11, // 6: reconciliation.v1.BankBatch.statements:type_name -> reconciliation.v1.BankStatement
+	2,  // 7: reconciliation.v1.ReconcileResponse.unmatched_system:type_name -> reconciliation.v1.SystemBatch
+	3,  // 8: reconciliation.v1.ReconcileResponse.unmatched_bank:type_name -> reconciliation.v1.BankBatch
+	5,  // 9: reconciliation.v1.ReconcileResponse.matches:type_name -> reconciliation.v1.MatchBatch
+	9,  // 10: reconciliation.v1.ReconcileResponse.summary:type_name -> reconciliation.v1.Summary
+	6,  // 11: reconciliation.v1.ReconcileResponse.duplicates:type_name -> reconciliation.v1.DuplicateBatch
+	7,  // 12: reconciliation.v1.ReconcileResponse.reversals:type_name -> reconciliation.v1.ReversalBatch
+	8,  // 13: reconciliation.v1.ReconcileResponse.internal_transfers:type_name -> reconciliation.v1.TransferBatch
+	5,  // 14: reconciliation.v1.ReconcileResponse.discrepant_matches:type_name -> reconciliation.v1.MatchBatch
+	12, // 15: reconciliation.v1.MatchBatch.matches:type_name -> reconciliation.v1.MatchedPair
+	13, // 16: reconciliation.v1.DuplicateBatch.duplicates:type_name -> reconciliation.v1.Duplicate
+	14, // 17: reconciliation.v1.ReversalBatch.reversals:type_name -> reconciliation.v1.Reversal
+	15, // 18: reconciliation.v1.TransferBatch.transfers:type_name -> reconciliation.v1.InternalTransfer
+	19, // 19: reconciliation.v1.Summary.by_bank:type_name -> reconciliation.v1.BankSummary
+	20, // 20: reconciliation.v1.Summary.balance_checks:type_name -> reconciliation.v1.BalanceCheck
+	21, // 21: reconciliation.v1.Transaction.transaction_time:type_name -> google.protobuf.Timestamp
+	21, // 22: reconciliation.v1.BankStatement.date:type_name -> google.protobuf.Timestamp
+	10, // 23: reconciliation.v1.MatchedPair.system:type_name -> reconciliation.v1.Transaction
+	11, // 24: reconciliation.v1.MatchedPair.statement:type_name -> reconciliation.v1.BankStatement
+	10, // 25: reconciliation.v1.Duplicate.transactions:type_name -> reconciliation.v1.Transaction
+	11, // 26: reconciliation.v1.Duplicate.statements:type_name -> reconciliation.v1.BankStatement
+	10, // 27: reconciliation.v1.Reversal.transactions:type_name -> reconciliation.v1.Transaction
+	11, // 28: reconciliation.v1.Reversal.statements:type_name -> reconciliation.v1.BankStatement
+	11, // 29: reconciliation.v1.InternalTransfer.from:type_name -> reconciliation.v1.BankStatement
+	11, // 30: reconciliation.v1.InternalTransfer.to:type_name -> reconciliation.v1.BankStatement
+	17, // 31: reconciliation.v1.FeeRule.tiers:type_name -> reconciliation.v1.FeeTier
+	0,  // 32: reconciliation.v1.ReconciliationService.Reconcile:input_type -> reconciliation.v1.ReconcileRequest
+	4,  // 33: reconciliation.v1.ReconciliationService.Reconcile:output_type -> reconciliation.v1.ReconcileResponse
+	33, // [33:34] is the sub-list for method output_type
+	32, // [32:33] is the sub-list for method input_type
+	32, // [32:32] is the sub-list for extension type_name
+	32, // [32:32] is the sub-list for extension extendee
+	0,  // [0:32] is the sub-list for field type_name
+}
+
+func init() { file_reconciliation_v1_reconciliation_proto_init() }
+func file_reconciliation_v1_reconciliation_proto_init() {
+	if File_reconciliation_v1_reconciliation_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_reconciliation_v1_reconciliation_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ReconcileRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ReconcileOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*SystemBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*BankBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ReconcileResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*MatchBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*DuplicateBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ReversalBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*TransferBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*Summary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*Transaction); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*BankStatement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*MatchedPair); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*Duplicate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*Reversal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*InternalTransfer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*FeeRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*FeeTier); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*StatementBalance); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*BankSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_reconciliation_v1_reconciliation_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*BalanceCheck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_reconciliation_v1_reconciliation_proto_msgTypes[0].OneofWrappers = []any{
+		(*ReconcileRequest_Options)(nil),
+		(*ReconcileRequest_System)(nil),
+		(*ReconcileRequest_Bank)(nil),
+	}
+	file_reconciliation_v1_reconciliation_proto_msgTypes[1].OneofWrappers = []any{}
+	file_reconciliation_v1_reconciliation_proto_msgTypes[4].OneofWrappers = []any{
+		(*ReconcileResponse_UnmatchedSystem)(nil),
+		(*ReconcileResponse_UnmatchedBank)(nil),
+		(*ReconcileResponse_Matches)(nil),
+		(*ReconcileResponse_Summary)(nil),
+		(*ReconcileResponse_Duplicates)(nil),
+		(*ReconcileResponse_Reversals)(nil),
+		(*ReconcileResponse_InternalTransfers)(nil),
+		(*ReconcileResponse_DiscrepantMatches)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_reconciliation_v1_reconciliation_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_reconciliation_v1_reconciliation_proto_goTypes,
+		DependencyIndexes: file_reconciliation_v1_reconciliation_proto_depIdxs,
+		MessageInfos:      file_reconciliation_v1_reconciliation_proto_msgTypes,
+	}.Build()
+	File_reconciliation_v1_reconciliation_proto = out.File
+	file_reconciliation_v1_reconciliation_proto_rawDesc = nil
+	file_reconciliation_v1_reconciliation_proto_goTypes = nil
+	file_reconciliation_v1_reconciliation_proto_depIdxs = nil
+}
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: reconciliation/v1/reconciliation.proto
+
+package reconciliationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReconciliationService_Reconcile_FullMethodName = "/reconciliation.v1.ReconciliationService/Reconcile"
+)
+
+// ReconciliationServiceClient is the client API for ReconciliationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReconciliationService reconciles system transactions against bank statements, like the
+// POST /api/reconcile route of the HTTP server
+type ReconciliationServiceClient interface {
+	// Reconcile reads the options, then batches of records until the client closes its stream.
+	// The results are streamed back in batches, the last message being the summary of the run.
+	// Callers authenticate with the x-api-key or authorization metadata, as over HTTP.
+	Reconcile(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ReconcileRequest, ReconcileResponse], error)
+}
+
+type reconciliationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReconciliationServiceClient(cc grpc.ClientConnInterface) ReconciliationServiceClient {
+	return &reconciliationServiceClient{cc}
+}
+
+func (c *reconciliationServiceClient) Reconcile(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ReconcileRequest, ReconcileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ReconciliationService_ServiceDesc.Streams[0], ReconciliationService_Reconcile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReconcileRequest, ReconcileResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReconciliationService_ReconcileClient = grpc.BidiStreamingClient[ReconcileRequest, ReconcileResponse]
+
+// ReconciliationServiceServer is the server API for ReconciliationService service.
+// All implementations must embed UnimplementedReconciliationServiceServer
+// for forward compatibility.
+//
+// ReconciliationService reconciles system transactions against bank statements, like the
+// POST /api/reconcile route of the HTTP server
+type ReconciliationServiceServer interface {
+	// Reconcile reads the options, then batches of records until the client closes its stream.
+	// The results are streamed back in batches, the last message being the summary of the run.
+	// Callers authenticate with the x-api-key or authorization metadata, as over HTTP.
+	Reconcile(grpc.BidiStreamingServer[ReconcileRequest, ReconcileResponse]) error
+	mustEmbedUnimplementedReconciliationServiceServer()
+}
+
+// UnimplementedReconciliationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReconciliationServiceServer struct{}
+
+func (UnimplementedReconciliationServiceServer) Reconcile(grpc.BidiStreamingServer[ReconcileRequest, ReconcileResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Reconcile not implemented")
+}
+func (UnimplementedReconciliationServiceServer) mustEmbedUnimplementedReconciliationServiceServer() {}
+func (UnimplementedReconciliationServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeReconciliationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReconciliationServiceServer will
+// result in compilation errors.
+type UnsafeReconciliationServiceServer interface {
+	mustEmbedUnimplementedReconciliationServiceServer()
+}
+
+func RegisterReconciliationServiceServer(s grpc.ServiceRegistrar, srv ReconciliationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReconciliationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReconciliationService_ServiceDesc, srv)
+}
+
+func _ReconciliationService_Reconcile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ReconciliationServiceServer).Reconcile(&grpc.GenericServerStream[ReconcileRequest, ReconcileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReconciliationService_ReconcileServer = grpc.BidiStreamingServer[ReconcileRequest, ReconcileResponse]
+
+// ReconciliationService_ServiceDesc is the grpc.ServiceDesc for ReconciliationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReconciliationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reconciliation.v1.ReconciliationService",
+	HandlerType: (*ReconciliationServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Reconcile",
+			Handler:       _ReconciliationService_Reconcile_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "reconciliation/v1/reconciliation.proto",
+}